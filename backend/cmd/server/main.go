@@ -7,9 +7,11 @@ import (
 	"os"
 
 	"github/meso1007/reverse-learn/backend/internal/auth"
+	"github/meso1007/reverse-learn/backend/internal/certificate"
 	"github/meso1007/reverse-learn/backend/internal/database"
 	"github/meso1007/reverse-learn/backend/internal/handlers"
 	"github/meso1007/reverse-learn/backend/internal/payment"
+	"github/meso1007/reverse-learn/backend/internal/tutor"
 	"github/meso1007/reverse-learn/backend/internal/worker"
 
 	"github.com/google/generative-ai-go/genai"
@@ -44,19 +46,29 @@ func main() {
 	model := client.GenerativeModel("gemini-flash-latest")
 	model.ResponseMIMEType = "application/json"
 
-	// 4. Init Worker
-	w := worker.NewWorker(db, model, 100)
-	w.Start()
+	// The tutor chats in plain text
+	chatModel := client.GenerativeModel("gemini-flash-latest")
 
-	// 5. Init Handlers
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		jwtSecret = "secret-key-fallback"
 	}
 
+	// Certificates are signed with CERTIFICATE_SIGNING_KEY (base64 Ed25519 seed)
+	signer, err := certificate.NewSigner(os.Getenv("CERTIFICATE_SIGNING_KEY"))
+	if err != nil {
+		log.Fatal("CERTIFICATE_SIGNING_KEY: ", err)
+	}
+
+	// 4. Init Worker
+	w := worker.NewWorker(db, model, signer, 100)
+	w.Start()
+
+	// 5. Init Handlers
+
 	paymentService := payment.NewService()
 	authMiddlewareHandler := auth.NewAuthHandler(jwtSecret, db)
-	h := handlers.NewHandler(db, w.JobQueue, jwtSecret, paymentService)
+	h := handlers.NewHandler(db, w.JobQueue, jwtSecret, paymentService, tutor.New(chatModel), signer)
 
 	// 6. Setup Echo
 	e := echo.New()
@@ -74,21 +86,77 @@ func main() {
 	e.POST("/api/auth/login", h.Login)
 	e.POST("/api/propose-plan", h.ProposePlan)
 	e.POST("/api/webhook/stripe", h.StripeWebhook)
+	e.GET("/api/certificates/:code", h.VerifyCertificate)
+	e.GET("/api/certificates/:code/pdf", h.GetCertificatePDF)
+	e.POST("/api/certificates/:code/verify", h.CheckCertificatePDF)
+	e.GET("/api/shared/:slug", h.GetSharedProject)
 
 	// Protected Routes
 	api := e.Group("/api")
 	api.Use(authMiddlewareHandler.AuthMiddleware)
 
 	api.PUT("/profile", h.UpdateProfile)
+	api.GET("/me/progress", h.GetMyProgress)
+	api.GET("/me/xp", h.GetMyXP)
+	api.GET("/me/streak", h.GetMyStreak)
+	api.GET("/me/activity", h.GetMyActivity)
+	api.GET("/me/badges", h.GetMyBadges)
+	api.GET("/leaderboards", h.GetLeaderboard)
+	api.GET("/cohorts", h.GetCohorts)
+	api.POST("/cohorts", h.CreateCohort)
+	api.POST("/cohorts/join", h.JoinCohort)
+	api.DELETE("/cohorts/:id/membership", h.LeaveCohort)
 	api.POST("/generate-roadmap", h.GenerateRoadmap)
 	api.POST("/generate-step-quiz", h.GenerateStepQuiz)
 	api.GET("/projects", h.GetProjects)
 	api.GET("/projects/latest", h.GetLatestProject)
 	api.GET("/projects/:id", h.GetProject)
 	api.DELETE("/projects/:id", h.DeleteProject)
+	api.PUT("/projects/:id/gating", h.UpdateGating)
+	api.PUT("/projects/:id/share", h.UpdateShare)
+	api.DELETE("/projects/:id/share", h.RevokeShare)
+	api.POST("/projects/:id/certificate", h.IssueCertificate)
+	api.GET("/certificates", h.GetCertificates)
+	api.POST("/projects/:id/steps", h.AddStep)
+	api.PUT("/projects/:id/steps", h.ReorderSteps)
 	api.GET("/projects/:id/steps/:stepNumber", h.GetStep)
-	api.POST("/projects/:id/steps/:stepNumber/score", h.SaveStepScore)
+	api.PUT("/projects/:id/steps/:stepNumber", h.UpdateStep)
+	api.DELETE("/projects/:id/steps/:stepNumber", h.DeleteStep)
+	api.GET("/projects/:id/revisions", h.GetRevisions)
+	api.GET("/projects/:id/revisions/diff", h.DiffRevisions)
+	api.GET("/projects/:id/revisions/:number", h.GetRevision)
+	api.POST("/projects/:id/revisions/:number/revert", h.RevertRevision)
+	api.GET("/projects/:id/steps/:stepNumber/attempts", h.GetStepAttempts)
+	api.POST("/projects/:id/steps/:stepNumber/attempts", h.StartAttempt)
+	api.POST("/projects/:id/steps/:stepNumber/sessions", h.StartStudySession)
+	api.POST("/sessions/:sessionId/heartbeat", h.StudyHeartbeat)
+	api.POST("/sessions/:sessionId/stop", h.StopStudySession)
+	api.GET("/projects/:id/steps/:stepNumber/notes", h.GetStepNotes)
+	api.POST("/projects/:id/steps/:stepNumber/notes", h.CreateNote)
+	api.PUT("/projects/:id/steps/:stepNumber/notes/:noteId", h.UpdateNote)
+	api.DELETE("/projects/:id/steps/:stepNumber/notes/:noteId", h.DeleteNote)
+	api.GET("/notes/search", h.SearchNotes)
+	api.GET("/search", h.Search)
+	api.GET("/templates", h.GetTemplates)
+	api.GET("/templates/:id", h.GetTemplate)
+	api.POST("/templates/:id/clone", h.CloneTemplate)
+	api.GET("/projects/:id/export", h.ExportProject)
+	api.GET("/projects/:id/steps/:stepNumber/analytics", h.GetStepAnalytics)
+	api.POST("/projects/:id/steps/:stepNumber/chat", h.Chat)
+	api.GET("/projects/:id/steps/:stepNumber/conversations", h.GetConversations)
+	api.GET("/conversations/:id", h.GetConversation)
+	api.DELETE("/conversations/:id", h.DeleteConversation)
+	api.GET("/attempts/:attemptId", h.GetAttempt)
+	api.POST("/attempts/:attemptId/answers", h.AnswerQuestion)
+	api.POST("/attempts/:attemptId/submit", h.SubmitAttempt)
+	api.POST("/attempts/:attemptId/answers/:quizId/appeal", h.AppealGrade)
+	api.POST("/attempts/:attemptId/answers/:quizId/explain", h.ExplainMistake)
+	api.GET("/appeals", h.GetAppeals)
+	api.POST("/projects/:id/steps/:stepNumber/regenerate", h.RegenerateStep)
 	api.GET("/jobs/:id", h.GetJob)
+	api.GET("/review/due", h.GetDueReviews)
+	api.POST("/quizzes/:id/report", h.ReportQuiz)
+	api.POST("/review/:cardId/grade", h.GradeReview)
 
 	// Payment Routes
 	api.POST("/payment/subscribe", h.Subscribe)
@@ -101,6 +169,23 @@ func main() {
 	admin.GET("/stats", h.GetStats)
 	admin.PUT("/users/:id/toggle-admin", h.ToggleAdmin)
 	admin.DELETE("/users/:id", h.DeleteUser)
+	admin.GET("/quizzes/suspicious", h.GetSuspiciousQuestions)
+	admin.GET("/quizzes/calibration", h.GetDifficultyCalibration)
+	admin.GET("/study-time/calibration", h.GetStudyTimeCalibration)
+	admin.GET("/quizzes/:id/analytics", h.GetQuizAnalytics)
+	admin.GET("/appeals", h.GetAdminAppeals)
+	admin.PUT("/appeals/:id", h.ResolveAppeal)
+	admin.GET("/reports", h.GetAdminReports)
+	admin.PUT("/reports/:id", h.ResolveReport)
+	admin.GET("/xp-rules", h.GetXPRules)
+	admin.PUT("/xp-rules/:event", h.UpdateXPRule)
+	admin.GET("/badges", h.GetBadges)
+	admin.POST("/badges", h.CreateBadge)
+	admin.PUT("/badges/:key", h.UpdateBadge)
+	admin.GET("/templates", h.GetAdminTemplates)
+	admin.POST("/templates", h.CreateTemplate)
+	admin.PUT("/templates/:id", h.UpdateTemplate)
+	admin.DELETE("/templates/:id", h.DeleteTemplate)
 
 	// Start Server
 	port := os.Getenv("PORT")
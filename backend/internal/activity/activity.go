@@ -0,0 +1,204 @@
+package activity
+
+import (
+	"errors"
+	"time"
+	_ "time/tzdata" // learners' time zones, even where the system has no zone database
+
+	"github/meso1007/reverse-learn/backend/internal/leaderboard"
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Events that award XP
+const (
+	EventAttemptCompleted = "attempt_completed" // a step's quiz was submitted
+	EventQuizPassed       = "quiz_passed"       // the quiz reached the project's pass percentage
+	EventPerfectScore     = "perfect_score"     // every question was answered correctly
+	EventStepCompleted    = "step_completed"    // a step's quiz was completed for the first time
+	EventReviewDone       = "review_done"       // a due review question was answered
+)
+
+// DefaultRules apply to the events without a stored rule.
+var DefaultRules = []models.XPRule{
+	{Event: EventAttemptCompleted, XP: 10, Enabled: true},
+	{Event: EventQuizPassed, XP: 20, Enabled: true},
+	{Event: EventPerfectScore, XP: 15, Enabled: true},
+	// Capped because steps can be added, deleted and cloned at will
+	{Event: EventStepCompleted, XP: 50, DailyCap: 250, Enabled: true},
+	{Event: EventReviewDone, XP: 2, DailyCap: 40, Enabled: true},
+}
+
+// ValidEvent reports whether event is a known event.
+func ValidEvent(event string) bool {
+	for _, r := range DefaultRules {
+		if r.Event == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Rules returns the rule of every event, stored rules taking precedence
+// over the defaults.
+func Rules(db *gorm.DB) ([]models.XPRule, error) {
+	var stored []models.XPRule
+	if err := db.Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	byEvent := make(map[string]models.XPRule, len(stored))
+	for _, r := range stored {
+		byEvent[r.Event] = r
+	}
+
+	rules := make([]models.XPRule, len(DefaultRules))
+	for i, r := range DefaultRules {
+		if s, ok := byEvent[r.Event]; ok {
+			r = s
+		}
+		rules[i] = r
+	}
+	return rules, nil
+}
+
+// rule returns the rule of an event.
+func rule(db *gorm.DB, event string) (models.XPRule, error) {
+	var r models.XPRule
+	err := db.Where("event = ?", event).First(&r).Error
+	if err == nil {
+		return r, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return r, err
+	}
+	for _, d := range DefaultRules {
+		if d.Event == event {
+			return d, nil
+		}
+	}
+	return r, errors.New("unknown activity event: " + event)
+}
+
+// Location returns the learner's time zone, UTC when unset or unknown.
+func Location(user models.User) *time.Location {
+	if user.Timezone != "" {
+		if loc, err := time.LoadLocation(user.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// ValidTimezone reports whether tz is a known IANA time zone.
+func ValidTimezone(tz string) bool {
+	_, err := time.LoadLocation(tz)
+	return tz != "" && err == nil
+}
+
+// Day returns the date of t in the time zone as YYYY-MM-DD.
+func Day(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("2006-01-02")
+}
+
+// Record awards the XP of an event and counts the learner's day toward the
+// streak. source identifies what earned the event; a source already
+// recorded for the event is not awarded again. It returns the recorded
+// event, or nil when nothing was recorded.
+func Record(db *gorm.DB, user models.User, event, source string, now time.Time) (*models.ActivityEvent, error) {
+	r, err := rule(db, event)
+	if err != nil {
+		return nil, err
+	}
+	if !r.Enabled {
+		return nil, nil
+	}
+
+	day := Day(now, Location(user))
+	var recorded *models.ActivityEvent
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		tx.Model(&models.ActivityEvent{}).Where("user_id = ? AND event = ? AND source = ?", user.ID, event, source).Count(&existing)
+		if existing > 0 {
+			return nil
+		}
+
+		xp := r.XP
+		if r.DailyCap > 0 {
+			var today int
+			tx.Model(&models.ActivityEvent{}).
+				Select("COALESCE(SUM(xp), 0)").
+				Where("user_id = ? AND event = ? AND day = ?", user.ID, event, day).
+				Scan(&today)
+			if left := r.DailyCap - today; xp > left {
+				xp = max(left, 0)
+			}
+		}
+
+		e := models.ActivityEvent{
+			UserID:    user.ID,
+			Event:     event,
+			Source:    source,
+			XP:        xp,
+			Day:       day,
+			CreatedAt: now,
+		}
+		if err := tx.Create(&e).Error; err != nil {
+			return err
+		}
+		if err := leaderboard.AddXP(tx, user.ID, xp, now); err != nil {
+			return err
+		}
+		recorded = &e
+		return touchStreak(tx, user.ID, day, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recorded, nil
+}
+
+// XP is the learner's total XP and level.
+type XP struct {
+	XP          int `json:"xp"`
+	Level       int `json:"level"`
+	LevelXP     int `json:"level_xp"`      // total XP at which the current level started
+	NextLevelXP int `json:"next_level_xp"` // total XP needed for the next level
+	TodayXP     int `json:"today_xp"`
+}
+
+// levelXP is the total XP needed to reach a level: 100 for level 2, 300 for
+// level 3, 600 for level 4 and so on.
+func levelXP(level int) int {
+	return 50 * level * (level - 1)
+}
+
+// LevelFor returns the level reached with the total XP.
+func LevelFor(xp int) int {
+	level := 1
+	for levelXP(level+1) <= xp {
+		level++
+	}
+	return level
+}
+
+// GetXP returns the learner's total XP, level and XP earned today.
+func GetXP(db *gorm.DB, user models.User, now time.Time) (XP, error) {
+	var total, today int
+	if err := db.Model(&models.ActivityEvent{}).Select("COALESCE(SUM(xp), 0)").Where("user_id = ?", user.ID).Scan(&total).Error; err != nil {
+		return XP{}, err
+	}
+	day := Day(now, Location(user))
+	if err := db.Model(&models.ActivityEvent{}).Select("COALESCE(SUM(xp), 0)").Where("user_id = ? AND day = ?", user.ID, day).Scan(&today).Error; err != nil {
+		return XP{}, err
+	}
+
+	level := LevelFor(total)
+	return XP{
+		XP:          total,
+		Level:       level,
+		LevelXP:     levelXP(level),
+		NextLevelXP: levelXP(level + 1),
+		TodayXP:     today,
+	}, nil
+}
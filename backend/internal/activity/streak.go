@@ -0,0 +1,111 @@
+package activity
+
+import (
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// FreezeEvery is how many streak days earn a streak freeze.
+	FreezeEvery = 7
+	// MaxFreezes is how many freezes a learner can hold.
+	MaxFreezes = 2
+)
+
+// daysBetween returns the number of days from one local date to another.
+func daysBetween(from, to string) int {
+	a, errA := time.Parse("2006-01-02", from)
+	b, errB := time.Parse("2006-01-02", to)
+	if errA != nil || errB != nil {
+		return 0
+	}
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// touchStreak counts the day as active. A missed day is covered by a freeze
+// when one is left; otherwise the streak starts over.
+func touchStreak(db *gorm.DB, userID uint, day string, now time.Time) error {
+	streak := models.Streak{UserID: userID}
+	if err := db.FirstOrCreate(&streak, models.Streak{UserID: userID}).Error; err != nil {
+		return err
+	}
+
+	switch {
+	case streak.LastActiveDay == "":
+		streak.Current = 1
+	default:
+		missed := daysBetween(streak.LastActiveDay, day) - 1
+		switch {
+		case missed < 0:
+			// Same day, or an earlier day after the time zone was changed
+			return nil
+		case missed == 0:
+			streak.Current++
+		case missed <= streak.Freezes:
+			streak.Freezes -= missed
+			streak.FreezesUsed += missed
+			streak.Current++
+		default:
+			streak.Current = 1
+		}
+	}
+
+	if streak.Current%FreezeEvery == 0 && streak.Freezes < MaxFreezes {
+		streak.Freezes++
+	}
+	if streak.Current > streak.Longest {
+		streak.Longest = streak.Current
+	}
+	streak.LastActiveDay = day
+	streak.UpdatedAt = now
+	return db.Save(&streak).Error
+}
+
+// StreakStatus is the learner's streak as of today.
+type StreakStatus struct {
+	Current       int    `json:"current"`
+	Longest       int    `json:"longest"`
+	Freezes       int    `json:"freezes"`
+	FreezesUsed   int    `json:"freezes_used"`
+	LastActiveDay string `json:"last_active_day,omitempty"`
+	Today         string `json:"today"`
+	Timezone      string `json:"timezone"`
+	ActiveToday   bool   `json:"active_today"`
+	// AtRisk is set when the streak ends unless the learner is active today
+	AtRisk bool `json:"at_risk"`
+}
+
+// GetStreak returns the learner's streak as of now. A streak with more
+// missed days than freezes is reported as broken (zero).
+func GetStreak(db *gorm.DB, user models.User, now time.Time) (StreakStatus, error) {
+	loc := Location(user)
+	status := StreakStatus{Today: Day(now, loc), Timezone: loc.String()}
+
+	var streak models.Streak
+	if err := db.Where("user_id = ?", user.ID).Limit(1).Find(&streak).Error; err != nil {
+		return status, err
+	}
+	status.Current = streak.Current
+	status.Longest = streak.Longest
+	status.Freezes = streak.Freezes
+	status.FreezesUsed = streak.FreezesUsed
+	status.LastActiveDay = streak.LastActiveDay
+	if streak.LastActiveDay == "" {
+		return status, nil
+	}
+
+	missed := daysBetween(streak.LastActiveDay, status.Today) - 1
+	switch {
+	case missed < 0:
+		status.ActiveToday = true
+	case missed > streak.Freezes:
+		status.Current = 0
+	default:
+		// Today is still open; freezes only cover the days already missed
+		status.AtRisk = missed == streak.Freezes
+	}
+	return status, nil
+}
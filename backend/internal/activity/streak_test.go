@@ -0,0 +1,95 @@
+package activity
+
+import (
+	"testing"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestDaysBetween(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     int
+	}{
+		{"2025-03-01", "2025-03-01", 0},
+		{"2025-02-28", "2025-03-01", 1},
+		{"2024-02-28", "2024-03-01", 2},
+		{"2025-03-29", "2025-03-31", 2}, // dates are not affected by DST
+		{"2025-03-02", "2025-03-01", -1},
+		{"", "2025-03-01", 0},
+	}
+	for _, tt := range tests {
+		if got := daysBetween(tt.from, tt.to); got != tt.want {
+			t.Errorf("daysBetween(%q, %q) = %d, want %d", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestTouchStreak(t *testing.T) {
+	tests := []struct {
+		name        string
+		days        []string
+		current     int
+		longest     int
+		freezes     int
+		freezesUsed int
+	}{
+		{"first day", []string{"2025-03-01"}, 1, 1, 0, 0},
+		{"same day twice", []string{"2025-03-01", "2025-03-01"}, 1, 1, 0, 0},
+		{"consecutive days", []string{"2025-03-01", "2025-03-02", "2025-03-03"}, 3, 3, 0, 0},
+		{"missed day without freeze", []string{"2025-03-01", "2025-03-02", "2025-03-04"}, 1, 2, 0, 0},
+		{"seven days earn a freeze", week("2025-03-01", 7), 7, 7, 1, 0},
+		{"freeze covers a missed day", append(week("2025-03-01", 7), "2025-03-09"), 8, 8, 0, 1},
+		{"two missed days need two freezes", append(week("2025-03-01", 7), "2025-03-10"), 1, 7, 1, 0},
+		{"freezes are capped", week("2025-03-01", 28), 28, 28, MaxFreezes, 0},
+		{"two freezes cover two days", append(week("2025-03-01", 14), "2025-03-17"), 15, 15, 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			now := time.Now()
+			for _, day := range tt.days {
+				if err := touchStreak(db, 1, day, now); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var s models.Streak
+			if err := db.Where("user_id = ?", 1).First(&s).Error; err != nil {
+				t.Fatal(err)
+			}
+			if s.Current != tt.current || s.Longest != tt.longest || s.Freezes != tt.freezes || s.FreezesUsed != tt.freezesUsed {
+				t.Errorf("streak = current %d longest %d freezes %d used %d, want %d %d %d %d",
+					s.Current, s.Longest, s.Freezes, s.FreezesUsed, tt.current, tt.longest, tt.freezes, tt.freezesUsed)
+			}
+			if last := tt.days[len(tt.days)-1]; s.LastActiveDay != last {
+				t.Errorf("LastActiveDay = %q, want %q", s.LastActiveDay, last)
+			}
+		})
+	}
+}
+
+// week returns n consecutive days from the start date.
+func week(start string, n int) []string {
+	day, _ := time.Parse("2006-01-02", start)
+	days := make([]string, n)
+	for i := range days {
+		days[i] = day.AddDate(0, 0, i).Format("2006-01-02")
+	}
+	return days
+}
+
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.Streak{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
@@ -0,0 +1,232 @@
+package adaptive
+
+import (
+	"fmt"
+	"strings"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Difficulty tags stored on each quiz
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+// RemedialThreshold is the percentage below which a completed attempt
+// triggers a remedial quiz for the step.
+const RemedialThreshold = 60
+
+// maxWeakTopics limits how many missed concepts are passed to the prompt.
+const maxWeakTopics = 8
+
+// StepScore is the learner's latest result on a step of the project.
+type StepScore struct {
+	StepNumber int
+	Title      string
+	Percentage int
+}
+
+// Profile summarizes how the learner has done so far in a project.
+type Profile struct {
+	Level      string
+	StepScores []StepScore
+	// WeakTopics are the concepts (or question texts) of recently missed questions.
+	WeakTopics []string
+	// Mix is the number of easy, medium and hard questions to ask for.
+	Mix map[string]int
+}
+
+// NormalizeDifficulty maps a model-provided tag onto one of the known
+// difficulties, or "" when it cannot be recognised.
+func NormalizeDifficulty(d string) string {
+	switch strings.ToLower(strings.TrimSpace(d)) {
+	case DifficultyEasy, "beginner", "basic":
+		return DifficultyEasy
+	case DifficultyMedium, "intermediate", "normal":
+		return DifficultyMedium
+	case DifficultyHard, "advanced", "difficult":
+		return DifficultyHard
+	}
+	return ""
+}
+
+// Build collects the learner's step scores and missed questions for the project.
+func Build(db *gorm.DB, project models.Project) (*Profile, error) {
+	p := &Profile{Level: project.Level}
+
+	var steps []models.Step
+	if err := db.Where("project_id = ?", project.ID).Order("step_number").Find(&steps).Error; err != nil {
+		return nil, err
+	}
+	stepIDs := make([]uint, len(steps))
+	for i, s := range steps {
+		stepIDs[i] = s.ID
+	}
+
+	var scores []models.Score
+	db.Where("step_id IN ?", stepIDs).Find(&scores)
+	scoreMap := make(map[uint]models.Score)
+	for _, s := range scores {
+		scoreMap[s.StepID] = s
+	}
+	for _, s := range steps {
+		if sc, ok := scoreMap[s.ID]; ok {
+			p.StepScores = append(p.StepScores, StepScore{StepNumber: s.StepNumber, Title: s.Title, Percentage: sc.Percentage})
+		}
+	}
+
+	// Most recently missed questions first
+	var missed []struct {
+		Topic    string
+		Question string
+	}
+	err := db.Table("attempt_answers").
+		Select("quizzes.topic, quizzes.question").
+		Joins("JOIN quiz_attempts ON quiz_attempts.id = attempt_answers.attempt_id").
+		Joins("JOIN quizzes ON quizzes.id = attempt_answers.quiz_id").
+		Where("quiz_attempts.user_id = ? AND quiz_attempts.status = ? AND quiz_attempts.step_id IN ? AND attempt_answers.is_correct = ?",
+			project.UserID, "completed", stepIDs, false).
+		Order("quiz_attempts.completed_at DESC").
+		Limit(50).
+		Scan(&missed).Error
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, m := range missed {
+		topic := m.Topic
+		if topic == "" {
+			topic = m.Question
+		}
+		if seen[topic] {
+			continue
+		}
+		seen[topic] = true
+		p.WeakTopics = append(p.WeakTopics, topic)
+		if len(p.WeakTopics) == maxWeakTopics {
+			break
+		}
+	}
+
+	p.Mix = mix(p.target())
+	return p, nil
+}
+
+// DefaultMix is the difficulty mix for a learner without any results.
+func DefaultMix(level string) map[string]int {
+	return mix((&Profile{Level: level}).target())
+}
+
+// target picks the overall difficulty from the average step score, falling
+// back to the declared level when the learner has no scores yet.
+func (p *Profile) target() string {
+	if len(p.StepScores) == 0 {
+		switch p.Level {
+		case "advanced":
+			return DifficultyHard
+		case "intermediate":
+			return DifficultyMedium
+		}
+		return DifficultyEasy
+	}
+
+	total := 0
+	for _, s := range p.StepScores {
+		total += s.Percentage
+	}
+	avg := total / len(p.StepScores)
+	switch {
+	case avg < RemedialThreshold:
+		return DifficultyEasy
+	case avg < 85:
+		return DifficultyMedium
+	}
+	return DifficultyHard
+}
+
+// mix spreads ten questions over the difficulties around the target.
+func mix(target string) map[string]int {
+	switch target {
+	case DifficultyEasy:
+		return map[string]int{DifficultyEasy: 5, DifficultyMedium: 4, DifficultyHard: 1}
+	case DifficultyHard:
+		return map[string]int{DifficultyEasy: 1, DifficultyMedium: 4, DifficultyHard: 5}
+	}
+	return map[string]int{DifficultyEasy: 3, DifficultyMedium: 4, DifficultyHard: 3}
+}
+
+// ScaleMix spreads n questions over the difficulties in the proportions of
+// mix. The remainder goes to the difficulties with the largest shares.
+func ScaleMix(mix map[string]int, n int) map[string]int {
+	difficulties := []string{DifficultyEasy, DifficultyMedium, DifficultyHard}
+	total := 0
+	for _, d := range difficulties {
+		total += mix[d]
+	}
+	scaled := make(map[string]int, len(difficulties))
+	if total == 0 {
+		scaled[DifficultyMedium] = n
+		return scaled
+	}
+
+	assigned := 0
+	for _, d := range difficulties {
+		scaled[d] = mix[d] * n / total
+		assigned += scaled[d]
+	}
+	for assigned < n {
+		best := difficulties[0]
+		for _, d := range difficulties[1:] {
+			if mix[d]*n-scaled[d]*total > mix[best]*n-scaled[best]*total {
+				best = d
+			}
+		}
+		scaled[best]++
+		assigned++
+	}
+	return scaled
+}
+
+// PromptSection describes the profile for the quiz generation prompt.
+func (p *Profile) PromptSection(locale string) string {
+	var b strings.Builder
+	if locale == "en" {
+		b.WriteString("# Learner Performance\n")
+		if len(p.StepScores) == 0 {
+			b.WriteString("- No quiz results yet.\n")
+		}
+		for _, s := range p.StepScores {
+			fmt.Fprintf(&b, "- Step %d (%s): %d%%\n", s.StepNumber, s.Title, s.Percentage)
+		}
+		if len(p.WeakTopics) > 0 {
+			b.WriteString("\n# Concepts the learner recently got wrong\n")
+			for _, t := range p.WeakTopics {
+				fmt.Fprintf(&b, "- %s\n", t)
+			}
+		}
+		fmt.Fprintf(&b, "\n# Difficulty Mix\n- easy: %d, medium: %d, hard: %d\n",
+			p.Mix[DifficultyEasy], p.Mix[DifficultyMedium], p.Mix[DifficultyHard])
+		return b.String()
+	}
+
+	b.WriteString("# 学習者の成績\n")
+	if len(p.StepScores) == 0 {
+		b.WriteString("- まだクイズの結果はありません。\n")
+	}
+	for _, s := range p.StepScores {
+		fmt.Fprintf(&b, "- Step %d（%s）: %d%%\n", s.StepNumber, s.Title, s.Percentage)
+	}
+	if len(p.WeakTopics) > 0 {
+		b.WriteString("\n# 最近間違えた概念\n")
+		for _, t := range p.WeakTopics {
+			fmt.Fprintf(&b, "- %s\n", t)
+		}
+	}
+	fmt.Fprintf(&b, "\n# 難易度の配分\n- easy: %d問, medium: %d問, hard: %d問\n",
+		p.Mix[DifficultyEasy], p.Mix[DifficultyMedium], p.Mix[DifficultyHard])
+	return b.String()
+}
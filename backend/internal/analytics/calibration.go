@@ -0,0 +1,69 @@
+package analytics
+
+// Expected correct rates per difficulty tag. A question is miscalibrated when
+// its observed correct rate falls outside the band of its tag.
+var expectedCorrectRate = map[string][2]float64{
+	"easy":   {0.7, 1.0},
+	"medium": {0.4, 0.85},
+	"hard":   {0.1, 0.55},
+}
+
+// DifficultyCalibration compares the tagged difficulty of questions with how
+// learners actually answered them.
+type DifficultyCalibration struct {
+	Difficulty    string  `json:"difficulty"`
+	Questions     int     `json:"questions"`
+	Responses     int     `json:"responses"`
+	CorrectRate   float64 `json:"correct_rate"` // over all responses
+	ExpectedMin   float64 `json:"expected_min"`
+	ExpectedMax   float64 `json:"expected_max"`
+	Calibrated    bool    `json:"calibrated"`
+	Miscalibrated []uint  `json:"miscalibrated"`  // quiz IDs outside the expected band
+	TooEasyRatio  float64 `json:"too_easy_ratio"` // share of questions above the band
+	TooHardRatio  float64 `json:"too_hard_ratio"` // share of questions below the band
+}
+
+// Calibrate groups question stats by difficulty tag. Only questions with at
+// least minResponses responses are considered; untagged questions are skipped.
+func Calibrate(stats []QuestionStats, difficulty map[uint]string, minResponses int) []DifficultyCalibration {
+	result := make([]DifficultyCalibration, 0, len(expectedCorrectRate))
+	for _, d := range []string{"easy", "medium", "hard"} {
+		band := expectedCorrectRate[d]
+		cal := DifficultyCalibration{
+			Difficulty:    d,
+			ExpectedMin:   band[0],
+			ExpectedMax:   band[1],
+			Miscalibrated: []uint{},
+		}
+
+		correct := 0.0
+		tooEasy, tooHard := 0, 0
+		for _, st := range stats {
+			if difficulty[st.QuizID] != d || st.Responses < minResponses {
+				continue
+			}
+			cal.Questions++
+			cal.Responses += st.Responses
+			correct += st.CorrectRate * float64(st.Responses)
+
+			switch {
+			case st.CorrectRate > band[1]:
+				tooEasy++
+				cal.Miscalibrated = append(cal.Miscalibrated, st.QuizID)
+			case st.CorrectRate < band[0]:
+				tooHard++
+				cal.Miscalibrated = append(cal.Miscalibrated, st.QuizID)
+			}
+		}
+		if cal.Responses > 0 {
+			cal.CorrectRate = correct / float64(cal.Responses)
+			cal.Calibrated = cal.CorrectRate >= band[0] && cal.CorrectRate <= band[1]
+		}
+		if cal.Questions > 0 {
+			cal.TooEasyRatio = float64(tooEasy) / float64(cal.Questions)
+			cal.TooHardRatio = float64(tooHard) / float64(cal.Questions)
+		}
+		result = append(result, cal)
+	}
+	return result
+}
@@ -0,0 +1,234 @@
+package analytics
+
+import (
+	"sort"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/questions"
+)
+
+// Response is one graded answer to a question, together with the overall
+// percentage of the attempt it belongs to.
+type Response struct {
+	QuizID            uint
+	Chosen            []int // options picked, in Quiz.Options indexes; empty for text and ordering answers
+	IsCorrect         bool
+	TimeTakenMs       int
+	AttemptPercentage int
+}
+
+// ResponseOf grades a recorded answer against the quiz's current answer key,
+// so that fixing a question also fixes its statistics. Free responses keep
+// the model's grade and are left out (false) until they have one.
+func ResponseOf(q models.Quiz, a models.AttemptAnswer, attemptPercentage int) (Response, bool) {
+	r := Response{
+		QuizID:            a.QuizID,
+		TimeTakenMs:       a.TimeTakenMs,
+		AttemptPercentage: attemptPercentage,
+	}
+	given := questions.ResponseOf(a)
+	switch t := questions.TypeOf(q); {
+	case t == questions.TypeFreeResponse:
+		if a.GradingStatus != "graded" && a.GradingStatus != "overridden" {
+			return r, false
+		}
+		r.IsCorrect = a.IsCorrect
+		return r, true
+	case questions.SingleChoice(t):
+		if given.Choice >= 0 {
+			r.Chosen = []int{given.Choice}
+		}
+	case t == questions.TypeMultipleSelect:
+		r.Chosen = given.Choices
+	}
+	r.IsCorrect = questions.Grade(q, given)
+	return r, true
+}
+
+// AnswerKey returns the correct options of a question whose options are
+// picked (single choice and multiple select), or nil for other types, whose
+// options are not counted.
+func AnswerKey(q models.Quiz) []int {
+	switch t := questions.TypeOf(q); {
+	case questions.SingleChoice(t):
+		return []int{q.AnswerIndex}
+	case t == questions.TypeMultipleSelect:
+		return questions.AnswerOf(q).Indexes
+	}
+	return nil
+}
+
+// QuestionStats summarizes how learners answered a single question.
+type QuestionStats struct {
+	QuizID      uint    `json:"quiz_id"`
+	Responses   int     `json:"responses"`
+	CorrectRate float64 `json:"correct_rate"`
+	AvgTimeMs   int     `json:"avg_time_ms"`
+	// OptionCounts is indexed like Quiz.Options; empty unless options are picked.
+	OptionCounts []int `json:"option_counts"`
+	// TopDistractor is the most chosen wrong option, if any wrong answer was given.
+	TopDistractor     *int    `json:"top_distractor,omitempty"`
+	TopDistractorRate float64 `json:"top_distractor_rate"`
+	// Discrimination is the correct rate of the top 27% of attempts minus that
+	// of the bottom 27%. It is nil when there are too few responses.
+	Discrimination   *float64 `json:"discrimination,omitempty"`
+	UpperCorrectRate *float64 `json:"upper_correct_rate,omitempty"`
+	LowerCorrectRate *float64 `json:"lower_correct_rate,omitempty"`
+	Flags            []string `json:"flags,omitempty"`
+}
+
+// Flags
+const (
+	FlagNegativeDiscrimination = "negative_discrimination" // weaker learners do better than stronger ones
+	FlagLowDiscrimination      = "low_discrimination"      // does not separate strong and weak learners
+	FlagAnswerRarelyChosen     = "answer_rarely_chosen"    // strong learners rarely pick the "correct" option
+	FlagDistractorPreferred    = "distractor_preferred"    // a wrong option is chosen more often than the answer
+	FlagTooHard                = "too_hard"
+	FlagTooEasy                = "too_easy"
+)
+
+// MinResponses is the number of responses needed before a question is flagged.
+const MinResponses = 10
+
+// groupShare is the share of attempts in the upper and lower groups used for
+// the discrimination index.
+const groupShare = 0.27
+
+// Compute aggregates responses per question. answers maps each quiz to its
+// correct options (see AnswerKey) and optionCount to the number of options
+// counted, zero for questions whose options are not picked.
+func Compute(responses []Response, answers map[uint][]int, optionCount map[uint]int) []QuestionStats {
+	byQuiz := make(map[uint][]Response)
+	for _, r := range responses {
+		byQuiz[r.QuizID] = append(byQuiz[r.QuizID], r)
+	}
+
+	stats := make([]QuestionStats, 0, len(byQuiz))
+	for quizID, rs := range byQuiz {
+		stats = append(stats, computeQuestion(quizID, rs, answers[quizID], optionCount[quizID]))
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].QuizID < stats[j].QuizID })
+	return stats
+}
+
+func computeQuestion(quizID uint, rs []Response, answer []int, options int) QuestionStats {
+	st := QuestionStats{
+		QuizID:       quizID,
+		Responses:    len(rs),
+		OptionCounts: make([]int, options),
+	}
+
+	correct, totalTime, timed := 0, 0, 0
+	for _, r := range rs {
+		if r.IsCorrect {
+			correct++
+		}
+		if r.TimeTakenMs > 0 {
+			totalTime += r.TimeTakenMs
+			timed++
+		}
+		for _, idx := range r.Chosen {
+			if idx >= 0 && idx < options {
+				st.OptionCounts[idx]++
+			}
+		}
+	}
+	if len(rs) > 0 {
+		st.CorrectRate = float64(correct) / float64(len(rs))
+	}
+	if timed > 0 {
+		st.AvgTimeMs = totalTime / timed
+	}
+
+	correctOption := make(map[int]bool, len(answer))
+	for _, idx := range answer {
+		correctOption[idx] = true
+	}
+	for i, n := range st.OptionCounts {
+		if correctOption[i] || n == 0 {
+			continue
+		}
+		if st.TopDistractor == nil || n > st.OptionCounts[*st.TopDistractor] {
+			idx := i
+			st.TopDistractor = &idx
+		}
+	}
+	if st.TopDistractor != nil {
+		st.TopDistractorRate = float64(st.OptionCounts[*st.TopDistractor]) / float64(len(rs))
+	}
+
+	// Discrimination index over the upper and lower 27% of attempts
+	groupSize := int(float64(len(rs)) * groupShare)
+	if groupSize >= 1 {
+		sorted := make([]Response, len(rs))
+		copy(sorted, rs)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].AttemptPercentage > sorted[j].AttemptPercentage
+		})
+		upper := correctRate(sorted[:groupSize])
+		lower := correctRate(sorted[len(sorted)-groupSize:])
+		d := upper - lower
+		st.UpperCorrectRate = &upper
+		st.LowerCorrectRate = &lower
+		st.Discrimination = &d
+	}
+
+	if len(rs) >= MinResponses {
+		st.Flags = flags(st, answer)
+	}
+	return st
+}
+
+func correctRate(rs []Response) float64 {
+	if len(rs) == 0 {
+		return 0
+	}
+	correct := 0
+	for _, r := range rs {
+		if r.IsCorrect {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(rs))
+}
+
+func flags(st QuestionStats, answer []int) []string {
+	var f []string
+	if st.Discrimination != nil {
+		if *st.Discrimination < 0 {
+			f = append(f, FlagNegativeDiscrimination)
+		} else if *st.Discrimination < 0.2 {
+			f = append(f, FlagLowDiscrimination)
+		}
+	}
+	if st.UpperCorrectRate != nil && *st.UpperCorrectRate < 0.3 {
+		f = append(f, FlagAnswerRarelyChosen)
+	}
+	// A wrong option is preferred when it is chosen more than some correct option
+	if st.TopDistractor != nil {
+		for _, idx := range answer {
+			if idx >= 0 && idx < len(st.OptionCounts) && st.OptionCounts[*st.TopDistractor] > st.OptionCounts[idx] {
+				f = append(f, FlagDistractorPreferred)
+				break
+			}
+		}
+	}
+	if st.CorrectRate < 0.2 {
+		f = append(f, FlagTooHard)
+	} else if st.CorrectRate > 0.95 {
+		f = append(f, FlagTooEasy)
+	}
+	return f
+}
+
+// Suspicious reports whether the flags point to a question that is likely
+// wrong or ambiguous, rather than merely easy or hard.
+func Suspicious(st QuestionStats) bool {
+	for _, f := range st.Flags {
+		switch f {
+		case FlagNegativeDiscrimination, FlagAnswerRarelyChosen, FlagDistractorPreferred:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package analytics
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/questions"
+)
+
+func TestComputeCounts(t *testing.T) {
+	responses := []Response{
+		{QuizID: 2, Chosen: []int{0}, IsCorrect: true, TimeTakenMs: 1000},
+		{QuizID: 2, Chosen: []int{2}, TimeTakenMs: 3000},
+		{QuizID: 2, Chosen: []int{2}},
+		{QuizID: 2, Chosen: []int{1}, TimeTakenMs: 2000},
+		{QuizID: 1, Chosen: []int{5}, IsCorrect: false}, // out of range, counted as a response only
+	}
+	stats := Compute(responses, map[uint][]int{1: {0}, 2: {0}}, map[uint]int{1: 2, 2: 4})
+	if len(stats) != 2 || stats[0].QuizID != 1 || stats[1].QuizID != 2 {
+		t.Fatalf("Compute = %+v, want stats for quizzes 1 and 2 in order", stats)
+	}
+
+	st := stats[1]
+	if st.Responses != 4 || st.CorrectRate != 0.25 {
+		t.Errorf("responses %d correct rate %v, want 4 and 0.25", st.Responses, st.CorrectRate)
+	}
+	if st.AvgTimeMs != 2000 {
+		t.Errorf("AvgTimeMs = %d, want 2000 (untimed answers are left out)", st.AvgTimeMs)
+	}
+	if !reflect.DeepEqual(st.OptionCounts, []int{1, 1, 2, 0}) {
+		t.Errorf("OptionCounts = %v, want [1 1 2 0]", st.OptionCounts)
+	}
+	if st.TopDistractor == nil || *st.TopDistractor != 2 || st.TopDistractorRate != 0.5 {
+		t.Errorf("top distractor %v rate %v, want option 2 at 0.5", st.TopDistractor, st.TopDistractorRate)
+	}
+
+	if !reflect.DeepEqual(stats[0].OptionCounts, []int{0, 0}) || stats[0].TopDistractor != nil {
+		t.Errorf("quiz 1 = %+v, want no option counts", stats[0])
+	}
+}
+
+func TestComputeDiscrimination(t *testing.T) {
+	// Strong learners get the question wrong and weak ones right
+	var responses []Response
+	for i := 0; i < 20; i++ {
+		r := Response{QuizID: 1, AttemptPercentage: i * 5, Chosen: []int{1}}
+		if i < 10 {
+			r.Chosen, r.IsCorrect = []int{0}, true
+		}
+		responses = append(responses, r)
+	}
+	st := Compute(responses, map[uint][]int{1: {0}}, map[uint]int{1: 4})[0]
+	if st.Discrimination == nil || *st.Discrimination != -1 {
+		t.Fatalf("Discrimination = %v, want -1", st.Discrimination)
+	}
+	if !slices.Contains(st.Flags, FlagNegativeDiscrimination) {
+		t.Errorf("Flags = %v, want %s", st.Flags, FlagNegativeDiscrimination)
+	}
+}
+
+func TestComputeMultipleSelect(t *testing.T) {
+	// Options 0 and 2 are correct; option 3 is picked more than option 2
+	responses := []Response{
+		{QuizID: 1, Chosen: []int{0, 2}, IsCorrect: true},
+		{QuizID: 1, Chosen: []int{0, 3}},
+		{QuizID: 1, Chosen: []int{0, 3}},
+		{QuizID: 1, Chosen: []int{1, 3}},
+	}
+	for i := 0; i < MinResponses; i++ {
+		responses = append(responses, Response{QuizID: 1, Chosen: []int{0, 3}})
+	}
+	st := Compute(responses, map[uint][]int{1: {0, 2}}, map[uint]int{1: 4})[0]
+	if !reflect.DeepEqual(st.OptionCounts, []int{13, 1, 1, 13}) {
+		t.Errorf("OptionCounts = %v, want [13 1 1 13]", st.OptionCounts)
+	}
+	if st.TopDistractor == nil || *st.TopDistractor != 3 {
+		t.Errorf("TopDistractor = %v, want option 3", st.TopDistractor)
+	}
+	if !slices.Contains(st.Flags, FlagDistractorPreferred) {
+		t.Errorf("Flags = %v, want %s", st.Flags, FlagDistractorPreferred)
+	}
+}
+
+func TestResponseOf(t *testing.T) {
+	quiz := func(typ string, answerIndex int, answer questions.Answer) models.Quiz {
+		q := models.Quiz{ID: 1, Type: typ, AnswerIndex: answerIndex}
+		q.Options, _ = json.Marshal([]string{"a", "b", "c"})
+		q.Answer, _ = json.Marshal(answer)
+		return q
+	}
+	answer := func(resp questions.Response, isCorrect bool, grading string) models.AttemptAnswer {
+		a := models.AttemptAnswer{QuizID: 1, ChosenIndex: -1, IsCorrect: isCorrect, GradingStatus: grading}
+		a.Response, _ = json.Marshal(resp)
+		return a
+	}
+
+	tests := []struct {
+		name    string
+		quiz    models.Quiz
+		answer  models.AttemptAnswer
+		chosen  []int
+		correct bool
+		counted bool
+	}{
+		{"multiple choice", quiz(questions.TypeMultipleChoice, 1, questions.Answer{}), answer(questions.Response{Choice: 1}, false, ""), []int{1}, true, true},
+		{"legacy answer without response", quiz("", 2, questions.Answer{}), models.AttemptAnswer{QuizID: 1, ChosenIndex: 2}, []int{2}, true, true},
+		{"multiple select", quiz(questions.TypeMultipleSelect, 0, questions.Answer{Indexes: []int{0, 2}}), answer(questions.Response{Choices: []int{2, 0}}, false, ""), []int{2, 0}, true, true},
+		{"ordering is not counted by option", quiz(questions.TypeOrdering, 0, questions.Answer{Indexes: []int{0, 1, 2}}), answer(questions.Response{Choices: []int{0, 2, 1}}, true, ""), nil, false, true},
+		{"fill blank", quiz(questions.TypeFillBlank, 0, questions.Answer{Accepted: []string{"Go"}}), answer(questions.Response{Choice: -1, Text: "go"}, false, ""), nil, true, true},
+		{"graded free response", quiz(questions.TypeFreeResponse, 0, questions.Answer{}), answer(questions.Response{Text: "x"}, true, "graded"), nil, true, true},
+		{"pending free response", quiz(questions.TypeFreeResponse, 0, questions.Answer{}), answer(questions.Response{Text: "x"}, false, "pending"), nil, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := ResponseOf(tt.quiz, tt.answer, 80)
+			if ok != tt.counted {
+				t.Fatalf("counted = %v, want %v", ok, tt.counted)
+			}
+			if !ok {
+				return
+			}
+			if !reflect.DeepEqual(r.Chosen, tt.chosen) || r.IsCorrect != tt.correct || r.AttemptPercentage != 80 {
+				t.Errorf("ResponseOf = %+v, want chosen %v correct %v", r, tt.chosen, tt.correct)
+			}
+		})
+	}
+}
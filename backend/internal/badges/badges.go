@@ -0,0 +1,292 @@
+package badges
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/activity"
+	"github/meso1007/reverse-learn/backend/internal/gating"
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Metrics a badge threshold applies to
+const (
+	MetricAttemptsCompleted = "attempts_completed" // completed quiz attempts, remedial ones excluded
+	MetricStepsCompleted    = "steps_completed"    // steps with a passing best score; filter matches the step title or description
+	MetricPerfectSteps      = "perfect_steps"      // steps with a 100% best score; filter as above
+	MetricProjectsCompleted = "projects_completed" // projects with every step passed; filter matches the goal or stack
+	MetricStreakDays        = "streak_days"        // longest daily streak
+	MetricReviewsDone       = "reviews_done"       // review questions answered
+	MetricXP                = "xp"                 // total XP
+	MetricLevel             = "level"
+)
+
+var metrics = []string{
+	MetricAttemptsCompleted, MetricStepsCompleted, MetricPerfectSteps, MetricProjectsCompleted,
+	MetricStreakDays, MetricReviewsDone, MetricXP, MetricLevel,
+}
+
+// ValidMetric reports whether metric is a known metric.
+func ValidMetric(metric string) bool {
+	for _, m := range metrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
+
+// Defaults are the badges available without a stored definition. A stored
+// badge with the same key replaces the default.
+var Defaults = []models.Badge{
+	{Key: "first_attempt", Name: "First steps", NameJa: "はじめの一歩", Description: "Complete your first quiz.", DescriptionJa: "初めてクイズを完了する", Metric: MetricAttemptsCompleted, Threshold: 1, Enabled: true},
+	{Key: "first_project", Name: "Project complete", NameJa: "プロジェクト完走", Description: "Pass every step of a project.", DescriptionJa: "プロジェクトの全ステップに合格する", Metric: MetricProjectsCompleted, Threshold: 1, Enabled: true},
+	{Key: "three_projects", Name: "Seasoned builder", NameJa: "熟練のビルダー", Description: "Pass every step of three projects.", DescriptionJa: "3つのプロジェクトの全ステップに合格する", Metric: MetricProjectsCompleted, Threshold: 3, Enabled: true},
+	{Key: "perfect_step", Name: "Flawless", NameJa: "パーフェクト", Description: "Score 100% on a step.", DescriptionJa: "ステップで100%を取る", Metric: MetricPerfectSteps, Threshold: 1, Enabled: true},
+	{Key: "security_perfect", Name: "Security minded", NameJa: "セキュリティの達人", Description: "Score 100% on a security step.", DescriptionJa: "セキュリティのステップで100%を取る", Metric: MetricPerfectSteps, Threshold: 1, Filter: "security,auth,セキュリティ,認証", Enabled: true},
+	{Key: "streak_7", Name: "Week streak", NameJa: "7日連続", Description: "Study 7 days in a row.", DescriptionJa: "7日連続で学習する", Metric: MetricStreakDays, Threshold: 7, Enabled: true},
+	{Key: "streak_30", Name: "Month streak", NameJa: "30日連続", Description: "Study 30 days in a row.", DescriptionJa: "30日連続で学習する", Metric: MetricStreakDays, Threshold: 30, Enabled: true},
+	{Key: "reviews_50", Name: "Keeping it fresh", NameJa: "復習の習慣", Description: "Answer 50 review questions.", DescriptionJa: "復習問題に50問答える", Metric: MetricReviewsDone, Threshold: 50, Enabled: true},
+	{Key: "level_5", Name: "Level 5", NameJa: "レベル5", Description: "Reach level 5.", DescriptionJa: "レベル5に到達する", Metric: MetricLevel, Threshold: 5, Enabled: true},
+}
+
+// Definitions returns every badge: the defaults, replaced by stored badges
+// with the same key, followed by the other stored badges.
+func Definitions(db *gorm.DB) ([]models.Badge, error) {
+	var stored []models.Badge
+	if err := db.Order("key").Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]models.Badge, len(stored))
+	for _, b := range stored {
+		byKey[b.Key] = b
+	}
+
+	defs := make([]models.Badge, 0, len(Defaults)+len(stored))
+	for _, b := range Defaults {
+		if s, ok := byKey[b.Key]; ok {
+			b = s
+			delete(byKey, b.Key)
+		}
+		defs = append(defs, b)
+	}
+	for _, b := range stored {
+		if _, ok := byKey[b.Key]; ok {
+			defs = append(defs, b)
+		}
+	}
+	return defs, nil
+}
+
+// Status is a badge with the learner's progress toward it.
+type Status struct {
+	models.Badge
+	Earned    bool       `json:"earned"`
+	AwardedAt *time.Time `json:"awarded_at,omitempty"`
+	Progress  int        `json:"progress"` // the metric's value, at most the threshold
+}
+
+// List returns the badges the learner has earned, newest first, and the
+// enabled badges still available.
+func List(db *gorm.DB, user models.User) (earned, available []Status, err error) {
+	defs, err := Definitions(db)
+	if err != nil {
+		return nil, nil, err
+	}
+	awarded, err := awardedAt(db, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m := newMeter(db, user)
+	earned, available = []Status{}, []Status{}
+	for _, b := range defs {
+		if at, ok := awarded[b.Key]; ok {
+			earned = append(earned, Status{Badge: b, Earned: true, AwardedAt: &at, Progress: b.Threshold})
+			continue
+		}
+		if !b.Enabled {
+			continue
+		}
+		v, err := m.value(b.Metric, b.Filter)
+		if err != nil {
+			return nil, nil, err
+		}
+		available = append(available, Status{Badge: b, Progress: min(v, b.Threshold)})
+	}
+	sort.SliceStable(earned, func(i, j int) bool { return earned[i].AwardedAt.After(*earned[j].AwardedAt) })
+	return earned, available, nil
+}
+
+// Evaluate awards the enabled badges whose threshold the learner has
+// reached and returns the ones awarded now.
+func Evaluate(db *gorm.DB, user models.User, now time.Time) ([]models.Badge, error) {
+	defs, err := Definitions(db)
+	if err != nil {
+		return nil, err
+	}
+	awarded, err := awardedAt(db, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	m := newMeter(db, user)
+	newly := []models.Badge{}
+	for _, b := range defs {
+		if _, ok := awarded[b.Key]; ok || !b.Enabled {
+			continue
+		}
+		v, err := m.value(b.Metric, b.Filter)
+		if err != nil {
+			return newly, err
+		}
+		if v < b.Threshold {
+			continue
+		}
+		// A concurrent evaluation may have awarded it already
+		res := db.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.UserBadge{UserID: user.ID, BadgeKey: b.Key, AwardedAt: now})
+		if res.Error != nil {
+			return newly, res.Error
+		}
+		if res.RowsAffected > 0 {
+			newly = append(newly, b)
+		}
+	}
+	return newly, nil
+}
+
+// awardedAt returns when the learner earned each badge.
+func awardedAt(db *gorm.DB, userID uint) (map[string]time.Time, error) {
+	var rows []models.UserBadge
+	if err := db.Where("user_id = ?", userID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	awarded := make(map[string]time.Time, len(rows))
+	for _, r := range rows {
+		awarded[r.BadgeKey] = r.AwardedAt
+	}
+	return awarded, nil
+}
+
+// matches reports whether any text contains one of the comma-separated
+// keywords, ignoring case. An empty filter matches everything.
+func matches(filter string, texts ...string) bool {
+	if strings.TrimSpace(filter) == "" {
+		return true
+	}
+	for _, kw := range strings.Split(filter, ",") {
+		kw = strings.ToLower(strings.TrimSpace(kw))
+		if kw == "" {
+			continue
+		}
+		for _, t := range texts {
+			if strings.Contains(strings.ToLower(t), kw) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// meter computes the learner's metrics, loading what they need once.
+type meter struct {
+	db       *gorm.DB
+	user     models.User
+	projects []models.Project
+	loaded   bool
+	values   map[string]int
+}
+
+func newMeter(db *gorm.DB, user models.User) *meter {
+	return &meter{db: db, user: user, values: make(map[string]int)}
+}
+
+// value returns the metric's value. Unknown metrics are zero.
+func (m *meter) value(metric, filter string) (int, error) {
+	key := metric + "|" + filter
+	if v, ok := m.values[key]; ok {
+		return v, nil
+	}
+
+	var v int
+	var err error
+	switch metric {
+	case MetricAttemptsCompleted:
+		var n int64
+		err = m.db.Model(&models.QuizAttempt{}).
+			Where("user_id = ? AND status = ? AND remedial = ?", m.user.ID, "completed", false).
+			Count(&n).Error
+		v = int(n)
+	case MetricReviewsDone:
+		var n int64
+		err = m.db.Model(&models.ActivityEvent{}).
+			Where("user_id = ? AND event = ?", m.user.ID, activity.EventReviewDone).
+			Count(&n).Error
+		v = int(n)
+	case MetricStreakDays:
+		var streak models.Streak
+		err = m.db.Where("user_id = ?", m.user.ID).Limit(1).Find(&streak).Error
+		v = streak.Longest
+	case MetricXP, MetricLevel:
+		var xp activity.XP
+		xp, err = activity.GetXP(m.db, m.user, time.Now())
+		v = xp.XP
+		if metric == MetricLevel {
+			v = xp.Level
+		}
+	case MetricStepsCompleted, MetricPerfectSteps, MetricProjectsCompleted:
+		v, err = m.stepMetric(metric, filter)
+	}
+	if err != nil {
+		return 0, err
+	}
+	m.values[key] = v
+	return v, nil
+}
+
+// stepMetric counts the steps or projects of the learner that meet the metric.
+func (m *meter) stepMetric(metric, filter string) (int, error) {
+	if !m.loaded {
+		if err := m.db.Where("user_id = ?", m.user.ID).Preload("Steps.Score").Find(&m.projects).Error; err != nil {
+			return 0, err
+		}
+		m.loaded = true
+	}
+
+	n := 0
+	for _, p := range m.projects {
+		pass := gating.PassPercentage(p)
+		if metric == MetricProjectsCompleted {
+			if len(p.Steps) == 0 || !matches(filter, p.Goal, p.Stack) {
+				continue
+			}
+			completed := true
+			for _, s := range p.Steps {
+				if s.Score == nil || s.Score.BestPercentage < pass {
+					completed = false
+					break
+				}
+			}
+			if completed {
+				n++
+			}
+			continue
+		}
+
+		for _, s := range p.Steps {
+			if s.Score == nil || !matches(filter, s.Title, s.Description) {
+				continue
+			}
+			if (metric == MetricStepsCompleted && s.Score.BestPercentage >= pass) ||
+				(metric == MetricPerfectSteps && s.Score.BestPercentage == 100) {
+				n++
+			}
+		}
+	}
+	return n, nil
+}
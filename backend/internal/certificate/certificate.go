@@ -0,0 +1,146 @@
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/gating"
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// StepScore is a step's best result as shown on a certificate.
+type StepScore struct {
+	Step       int    `json:"step"`
+	Title      string `json:"title"`
+	Percentage int    `json:"percentage"`
+}
+
+// IncompleteError lists the steps that keep a project from being certified.
+type IncompleteError struct {
+	PassPercentage int   `json:"pass_percentage"`
+	Steps          []int `json:"steps"` // step numbers without a passing best score
+}
+
+func (e *IncompleteError) Error() string {
+	return fmt.Sprintf("%d steps have no passing score", len(e.Steps))
+}
+
+// Scores returns the step scores stored on a certificate.
+func Scores(cert models.Certificate) []StepScore {
+	var scores []StepScore
+	json.Unmarshal(cert.Scores, &scores)
+	return scores
+}
+
+// Check returns the best score of every step of the project, or an
+// IncompleteError when a step has not been passed yet.
+func Check(db *gorm.DB, project models.Project) ([]StepScore, error) {
+	var steps []models.Step
+	if err := db.Where("project_id = ?", project.ID).Order("step_number").Preload("Score").Find(&steps).Error; err != nil {
+		return nil, err
+	}
+
+	pass := gating.PassPercentage(project)
+	incomplete := &IncompleteError{PassPercentage: pass, Steps: []int{}}
+	scores := make([]StepScore, 0, len(steps))
+	for _, s := range steps {
+		if s.Score == nil || s.Score.BestPercentage < pass {
+			incomplete.Steps = append(incomplete.Steps, s.StepNumber)
+			continue
+		}
+		scores = append(scores, StepScore{Step: s.StepNumber, Title: s.Title, Percentage: s.Score.BestPercentage})
+	}
+	if len(steps) == 0 || len(incomplete.Steps) > 0 {
+		return nil, incomplete
+	}
+	return scores, nil
+}
+
+// newCode returns a random verification code.
+func newCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.EncodeToString(b), nil
+}
+
+// Issue returns the project's certificate, issuing it if every step has a
+// passing score. The bool reports whether it was issued now.
+func Issue(db *gorm.DB, signer *Signer, project models.Project) (*models.Certificate, bool, error) {
+	var existing models.Certificate
+	err := db.Where("project_id = ? AND revoked_at IS NULL", project.ID).Limit(1).Find(&existing).Error
+	if err != nil {
+		return nil, false, err
+	}
+	if existing.ID != 0 {
+		return &existing, false, nil
+	}
+
+	scores, err := Check(db, project)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var user models.User
+	if err := db.First(&user, project.UserID).Error; err != nil {
+		return nil, false, err
+	}
+	name := user.Username
+	if name == "" {
+		name, _, _ = strings.Cut(user.Email, "@")
+	}
+
+	code, err := newCode()
+	if err != nil {
+		return nil, false, err
+	}
+	total := 0
+	for _, s := range scores {
+		total += s.Percentage
+	}
+	scoresBytes, _ := json.Marshal(scores)
+
+	cert := models.Certificate{
+		Code:              code,
+		UserID:            project.UserID,
+		ProjectID:         project.ID,
+		LearnerName:       name,
+		Goal:              project.Goal,
+		Stack:             project.Stack,
+		Locale:            project.Locale,
+		Scores:            scoresBytes,
+		AveragePercentage: total / len(scores),
+		// Stored to the second so the signed details read back the same
+		IssuedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	signer.Sign(&cert)
+	cert.PDF = Render(cert)
+	sum := sha256.Sum256(cert.PDF)
+	cert.PDFHash = hex.EncodeToString(sum[:])
+
+	if err := db.Create(&cert).Error; err != nil {
+		// Issued meanwhile by a concurrent request
+		if db.Where("project_id = ? AND revoked_at IS NULL", project.ID).Limit(1).Find(&existing).Error == nil && existing.ID != 0 {
+			return &existing, false, nil
+		}
+		return nil, false, err
+	}
+	return &cert, true, nil
+}
+
+// Revoke revokes the certificates of the projects matched by the query
+// (a project ID or a subquery of project IDs).
+func Revoke(db *gorm.DB, projectIDs interface{}) error {
+	return db.Model(&models.Certificate{}).
+		Where("project_id IN (?) AND revoked_at IS NULL", projectIDs).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,231 @@
+package certificate
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+)
+
+// Page size (A4 landscape) and layout, in points.
+const (
+	pageWidth  = 842
+	pageHeight = 595
+	margin     = 36
+)
+
+// Fonts of the page. Text that is not plain ASCII is set in a standard
+// Japanese font, which viewers provide without it being embedded.
+const (
+	fontRegular = "F1" // Helvetica
+	fontBold    = "F2" // Helvetica-Bold
+	fontCJK     = "F3" // HeiseiKakuGo-W5
+)
+
+// labels are the fixed texts of the certificate by locale.
+type labels struct {
+	title, certifies, completed, stack, step, score, average, issued, code, verify string
+}
+
+func labelsFor(locale string) labels {
+	if locale == "en" {
+		return labels{
+			title:     "Certificate of Completion",
+			certifies: "This certifies that",
+			completed: "has completed every step of the learning roadmap",
+			stack:     "Tech stack",
+			step:      "Step",
+			score:     "Best score",
+			average:   "Average score",
+			issued:    "Issued",
+			code:      "Verification code",
+			verify:    "Verify this certificate at /api/certificates/%s",
+		}
+	}
+	return labels{
+		title:     "修了証",
+		certifies: "以下の学習者は",
+		completed: "学習ロードマップのすべてのステップを修了したことを証明します",
+		stack:     "技術スタック",
+		step:      "ステップ",
+		score:     "最高スコア",
+		average:   "平均スコア",
+		issued:    "発行日",
+		code:      "検証コード",
+		verify:    "/api/certificates/%s で真正性を確認できます",
+	}
+}
+
+// page accumulates the content stream of a page.
+type page struct {
+	buf bytes.Buffer
+}
+
+// isASCII reports whether the text can be set in the Helvetica fonts.
+func isASCII(text string) bool {
+	for _, r := range text {
+		if r < 0x20 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// width estimates the width of the text at the font size. Helvetica
+// characters average about half the size; Japanese ones are full width.
+func width(text string, size float64) float64 {
+	if isASCII(text) {
+		return float64(len(text)) * size * 0.5
+	}
+	w := 0.0
+	for _, r := range text {
+		if r < 0x80 {
+			w += size * 0.5
+		} else {
+			w += size
+		}
+	}
+	return w
+}
+
+// text draws the text with its left end at x.
+func (p *page) text(x, y, size float64, bold bool, text string) {
+	var font, str string
+	if isASCII(text) {
+		font = fontRegular
+		if bold {
+			font = fontBold
+		}
+		r := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+		str = "(" + r.Replace(text) + ")"
+	} else {
+		// UCS-2 code units as hex; characters outside the BMP are not supported
+		font = fontCJK
+		var hex strings.Builder
+		for _, r := range text {
+			if r > 0xffff || r < 0x20 {
+				r = '?'
+			}
+			fmt.Fprintf(&hex, "%04X", r)
+		}
+		str = "<" + hex.String() + ">"
+	}
+	fmt.Fprintf(&p.buf, "BT /%s %.1f Tf %.1f %.1f Td %s Tj ET\n", font, size, x, y, str)
+}
+
+// centered draws the text centered on the page.
+func (p *page) centered(y, size float64, bold bool, text string) {
+	p.text((pageWidth-width(text, size))/2, y, size, bold, text)
+}
+
+// truncate shortens the text to fit the width at the font size.
+func truncate(text string, size, max float64) string {
+	if width(text, size) <= max {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && width(string(runes)+"...", size) > max {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// Render draws the certificate as a one-page PDF.
+func Render(cert models.Certificate) []byte {
+	l := labelsFor(cert.Locale)
+	scores := Scores(cert)
+	p := &page{}
+
+	// Double border
+	fmt.Fprintf(&p.buf, "0.12 0.16 0.23 RG 3 w %d %d %d %d re S\n", margin, margin, pageWidth-2*margin, pageHeight-2*margin)
+	fmt.Fprintf(&p.buf, "0.6 w %d %d %d %d re S\n", margin+8, margin+8, pageWidth-2*margin-16, pageHeight-2*margin-16)
+
+	contentWidth := float64(pageWidth - 2*margin - 60)
+	p.buf.WriteString("0.12 0.16 0.23 rg\n")
+	p.centered(500, 30, true, l.title)
+	p.centered(462, 13, false, l.certifies)
+	p.centered(428, 26, true, truncate(cert.LearnerName, 26, contentWidth))
+	p.centered(400, 13, false, l.completed)
+	p.centered(370, 17, true, truncate(cert.Goal, 17, contentWidth))
+	p.centered(348, 11, false, truncate(l.stack+": "+cert.Stack, 11, contentWidth))
+
+	// Step scores, shrinking the rows when the roadmap is long
+	top, bottom := 318.0, 150.0
+	row := 14.0
+	if n := float64(len(scores) + 1); n*row > top-bottom {
+		row = (top - bottom) / n
+	}
+	size := row * 0.75
+	left, right := float64(margin+110), float64(pageWidth-margin-110)
+	p.text(left, top, size, true, l.step)
+	p.text(right-width(l.score, size), top, size, true, l.score)
+	for i, s := range scores {
+		y := top - float64(i+1)*row
+		title := truncate(fmt.Sprintf("%d. %s", s.Step, s.Title), size, right-left-80)
+		pct := fmt.Sprintf("%d%%", s.Percentage)
+		p.text(left, y, size, false, title)
+		p.text(right-width(pct, size), y, size, false, pct)
+	}
+
+	p.centered(122, 14, true, fmt.Sprintf("%s: %d%%", l.average, cert.AveragePercentage))
+
+	// Verification details
+	p.buf.WriteString("0.35 0.4 0.47 rg\n")
+	p.text(margin+24, 92, 10, false, fmt.Sprintf("%s: %s", l.issued, cert.IssuedAt.UTC().Format("2006-01-02")))
+	code := fmt.Sprintf("%s: %s", l.code, cert.Code)
+	p.text(pageWidth-margin-24-width(code, 10), 92, 10, false, code)
+	p.centered(74, 8, false, fmt.Sprintf(l.verify, cert.Code))
+	p.centered(62, 6, false, "Ed25519: "+cert.Signature)
+
+	return assemble(p.buf.Bytes(), cert)
+}
+
+// pdfString escapes a text for a PDF literal string, keeping only ASCII.
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r <= 0x7e:
+			b.WriteRune(r)
+		}
+	}
+	return "(" + b.String() + ")"
+}
+
+// assemble writes the objects of the document around the page content.
+func assemble(content []byte, cert models.Certificate) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /%s 5 0 R /%s 6 0 R /%s 7 0 R >> >> /Contents 4 0 R >>",
+			pageWidth, pageHeight, fontRegular, fontBold, fontCJK),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type0 /BaseFont /HeiseiKakuGo-W5-UniJIS-UCS2-H /Encoding /UniJIS-UCS2-H /DescendantFonts [8 0 R] >>",
+		"<< /Type /Font /Subtype /CIDFontType0 /BaseFont /HeiseiKakuGo-W5 /CIDSystemInfo << /Registry (Adobe) /Ordering (Japan1) /Supplement 2 >> /FontDescriptor 9 0 R /DW 1000 >>",
+		"<< /Type /FontDescriptor /FontName /HeiseiKakuGo-W5 /Flags 4 /FontBBox [-92 -250 1010 922] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 737 /StemV 114 >>",
+		fmt.Sprintf("<< /Title %s /Subject %s /Keywords %s /Producer (reverse-learn) /CreationDate (D:%s) >>",
+			pdfString("Certificate "+cert.Code), pdfString(cert.Code), pdfString("ed25519:"+cert.Signature),
+			cert.IssuedAt.UTC().Format("20060102150405")+"Z"),
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, len(objects), xref)
+	return b.Bytes()
+}
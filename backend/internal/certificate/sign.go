@@ -0,0 +1,78 @@
+package certificate
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+)
+
+// Signer signs certificates with an Ed25519 key, so that anyone holding the
+// public key can check that a certificate's details were not altered.
+type Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewSigner creates a signer from a base64 Ed25519 seed (32 bytes). The seed
+// is required: a key anyone could derive would let them forge certificates.
+func NewSigner(seed string) (*Signer, error) {
+	if seed == "" {
+		return nil, errors.New("certificate signing key is not set")
+	}
+	raw, err := base64.StdEncoding.DecodeString(seed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate signing key: %v", err)
+	}
+	if len(raw) != ed25519.SeedSize {
+		return nil, fmt.Errorf("certificate signing key must be %d bytes", ed25519.SeedSize)
+	}
+	return &Signer{key: ed25519.NewKeyFromSeed(raw)}, nil
+}
+
+// PublicKey returns the base64 public key that verifies the signatures.
+func (s *Signer) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.key.Public().(ed25519.PublicKey))
+}
+
+// signedDetails are the signed fields of a certificate, in a fixed order.
+type signedDetails struct {
+	Code              string      `json:"code"`
+	LearnerName       string      `json:"learner_name"`
+	Goal              string      `json:"goal"`
+	Stack             string      `json:"stack"`
+	Scores            []StepScore `json:"scores"`
+	AveragePercentage int         `json:"average_percentage"`
+	IssuedAt          string      `json:"issued_at"`
+}
+
+// Payload returns the bytes a certificate's signature is made over.
+func Payload(cert models.Certificate) []byte {
+	payload, _ := json.Marshal(signedDetails{
+		Code:              cert.Code,
+		LearnerName:       cert.LearnerName,
+		Goal:              cert.Goal,
+		Stack:             cert.Stack,
+		Scores:            Scores(cert),
+		AveragePercentage: cert.AveragePercentage,
+		IssuedAt:          cert.IssuedAt.UTC().Format(time.RFC3339),
+	})
+	return payload
+}
+
+// Sign sets the certificate's signature.
+func (s *Signer) Sign(cert *models.Certificate) {
+	cert.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, Payload(*cert)))
+}
+
+// Verify reports whether the certificate's signature matches its details.
+func (s *Signer) Verify(cert models.Certificate) bool {
+	sig, err := base64.StdEncoding.DecodeString(cert.Signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(s.key.Public().(ed25519.PublicKey), Payload(cert), sig)
+}
@@ -0,0 +1,98 @@
+package completion
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/activity"
+	"github/meso1007/reverse-learn/backend/internal/badges"
+	"github/meso1007/reverse-learn/backend/internal/certificate"
+	"github/meso1007/reverse-learn/backend/internal/gating"
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Outcome is what a completed attempt earned.
+type Outcome struct {
+	XP          int
+	Badges      []models.Badge
+	Certificate *models.Certificate // issued by this attempt, nil otherwise
+}
+
+// Record awards the XP of the events and any badges they unlock, returning
+// the XP and the badges awarded. Failures are logged, since XP never blocks
+// learning.
+func Record(db *gorm.DB, userID uint, events map[string]string) (int, []models.Badge) {
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return 0, []models.Badge{}
+	}
+	now := time.Now()
+	awarded, recorded := 0, 0
+	for event, source := range events {
+		e, err := activity.Record(db, user, event, source, now)
+		if err != nil {
+			log.Printf("Activity: Failed to record %s for user %d: %v", event, userID, err)
+			continue
+		}
+		if e != nil {
+			awarded += e.XP
+			recorded++
+		}
+	}
+	if recorded == 0 {
+		return awarded, []models.Badge{}
+	}
+
+	earned, err := badges.Evaluate(db, user, now)
+	if err != nil {
+		log.Printf("Badges: Failed to evaluate for user %d: %v", userID, err)
+	}
+	return awarded, earned
+}
+
+// Attempt awards the XP and badges of a completed attempt and, once every
+// step is passed, the project's certificate. It runs when the attempt is
+// submitted and again when its free responses are graded, since the attempt
+// may only pass then; events already recorded are not awarded twice.
+func Attempt(db *gorm.DB, signer *certificate.Signer, attempt models.QuizAttempt) Outcome {
+	source := fmt.Sprintf("attempt:%d", attempt.ID)
+	events := map[string]string{activity.EventAttemptCompleted: source}
+
+	var step models.Step
+	var project models.Project
+	if err := db.First(&step, attempt.StepID).Error; err == nil {
+		db.First(&project, step.ProjectID)
+	}
+	if !attempt.Remedial && attempt.Percentage >= gating.PassPercentage(project) {
+		events[activity.EventQuizPassed] = source
+		// A step is completed by its first passing attempt. Steps cloned from
+		// a template count once per template step, so cloning the template
+		// again earns nothing, and steps the learner added earn nothing, or
+		// adding and deleting steps would farm XP. A step restored by a
+		// revert keeps its ID, so it is not awarded twice either
+		stepSource := fmt.Sprintf("step:%d", attempt.StepID)
+		if project.TemplateID != nil {
+			stepSource = fmt.Sprintf("template:%d:step:%d", *project.TemplateID, step.StepNumber)
+		}
+		if !step.UserCreated {
+			events[activity.EventStepCompleted] = stepSource
+		}
+	}
+	if attempt.Total > 0 && attempt.Score == attempt.Total {
+		events[activity.EventPerfectScore] = source
+	}
+
+	var out Outcome
+	out.XP, out.Badges = Record(db, attempt.UserID, events)
+
+	// Passing the last step earns the project's certificate
+	if !attempt.Remedial && project.ID != 0 {
+		if cert, created, err := certificate.Issue(db, signer, project); err == nil && created {
+			out.Certificate = cert
+		}
+	}
+	return out
+}
@@ -5,7 +5,10 @@ import (
 	"log"
 	"os"
 
+	"github/meso1007/reverse-learn/backend/internal/leaderboard"
 	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/notes"
+	"github/meso1007/reverse-learn/backend/internal/search"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
@@ -39,18 +42,132 @@ func InitDB() *gorm.DB {
 		log.Fatal("failed to connect database: ", err)
 	}
 
-	// Auto Migrate
-	err = db.AutoMigrate(
+	if err := Migrate(db); err != nil {
+		log.Fatal(err)
+	}
+
+	return db
+}
+
+// Migrate creates or updates the schema and the search indexes, and
+// backfills data added by later migrations.
+func Migrate(db *gorm.DB) error {
+	if err := dedupeCertificates(db); err != nil {
+		return fmt.Errorf("failed to remove duplicate certificates: %w", err)
+	}
+
+	err := db.AutoMigrate(
 		&models.User{},
 		&models.Project{},
 		&models.Step{},
 		&models.Quiz{},
 		&models.Score{},
 		&models.Job{},
+		&models.RoadmapRevision{},
+		&models.QuizAttempt{},
+		&models.AttemptAnswer{},
+		&models.ReviewCard{},
+		&models.GradeAppeal{},
+		&models.MistakeExplanation{},
+		&models.Conversation{},
+		&models.Message{},
+		&models.UsageRecord{},
+		&models.QuizReport{},
+		&models.Certificate{},
+		&models.XPRule{},
+		&models.ActivityEvent{},
+		&models.Streak{},
+		&models.Badge{},
+		&models.UserBadge{},
+		&models.LeaderboardEntry{},
+		&models.Cohort{},
+		&models.CohortMember{},
+		&models.StudySession{},
+		&models.Note{},
+		&models.RoadmapTemplate{},
 	)
 	if err != nil {
-		log.Fatal("failed to migrate database:", err)
+		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 
-	return db
+	if err := backfillQuizAttempts(db); err != nil {
+		return fmt.Errorf("failed to backfill quiz attempts: %w", err)
+	}
+	if err := backfillLeaderboards(db); err != nil {
+		return fmt.Errorf("failed to backfill leaderboards: %w", err)
+	}
+	if err := notes.Setup(db); err != nil {
+		return fmt.Errorf("failed to set up note search: %w", err)
+	}
+	if err := search.Setup(db); err != nil {
+		return fmt.Errorf("failed to set up search: %w", err)
+	}
+	return nil
+}
+
+// dedupeCertificates keeps only the first certificate of each project, so
+// that the unique index on certificates can be created over databases where
+// concurrent issues left duplicates.
+func dedupeCertificates(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.Certificate{}) {
+		return nil
+	}
+	return db.Where("id NOT IN (?)", db.Model(&models.Certificate{}).Select("MIN(id)").Group("user_id, project_id")).
+		Delete(&models.Certificate{}).Error
+}
+
+// backfillQuizAttempts turns scores saved before attempt history existed into
+// a single attempt each, so that the derived best/latest values stay correct.
+func backfillQuizAttempts(db *gorm.DB) error {
+	var scores []models.Score
+	if err := db.Where("attempt_count = 0").Find(&scores).Error; err != nil {
+		return err
+	}
+
+	for _, sc := range scores {
+		var step models.Step
+		if err := db.First(&step, sc.StepID).Error; err != nil {
+			continue
+		}
+		var project models.Project
+		if err := db.First(&project, step.ProjectID).Error; err != nil {
+			continue
+		}
+
+		attempt := models.QuizAttempt{
+			UserID:      project.UserID,
+			StepID:      sc.StepID,
+			Status:      "completed",
+			Score:       sc.Score,
+			Total:       sc.Total,
+			Percentage:  sc.Percentage,
+			CreatedAt:   project.CreatedAt,
+			CompletedAt: &project.CreatedAt,
+		}
+		if err := db.Create(&attempt).Error; err != nil {
+			return err
+		}
+
+		sc.BestScore = sc.Score
+		sc.BestTotal = sc.Total
+		sc.BestPercentage = sc.Percentage
+		sc.AttemptCount = 1
+		if err := db.Save(&sc).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillLeaderboards builds the leaderboard totals from the XP ledger and
+// the attempt history the first time leaderboards are migrated.
+func backfillLeaderboards(db *gorm.DB) error {
+	var entries int64
+	if err := db.Model(&models.LeaderboardEntry{}).Count(&entries).Error; err != nil {
+		return err
+	}
+	if entries > 0 {
+		return nil
+	}
+	return leaderboard.Rebuild(db)
 }
@@ -0,0 +1,189 @@
+package gating
+
+import (
+	"fmt"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Gating modes of a project
+const (
+	ModeOff        = "off"        // every step is open
+	ModeSequential = "sequential" // the previous steps' quizzes must be completed
+	ModePass       = "pass"       // the previous steps' quizzes must be passed
+)
+
+// DefaultPassPercentage is the pass threshold of new projects.
+const DefaultPassPercentage = 70
+
+// PassPercentage is the score that passes a step's quiz in the project.
+func PassPercentage(project models.Project) int {
+	if project.PassPercentage > 0 {
+		return project.PassPercentage
+	}
+	return DefaultPassPercentage
+}
+
+// ValidMode reports whether mode is a known gating mode.
+func ValidMode(mode string) bool {
+	return mode == ModeOff || mode == ModeSequential || mode == ModePass
+}
+
+// strictness orders the modes from the most open to the most demanding.
+var strictness = map[string]int{ModeOff: 0, ModeSequential: 1, ModePass: 2}
+
+// Loosens reports whether going from one gating setting to another opens
+// steps sooner: a less demanding mode, or a lower pass percentage in pass
+// mode.
+func Loosens(from, to models.Project) bool {
+	fromMode, toMode := from.GatingMode, to.GatingMode
+	if fromMode == "" {
+		fromMode = ModeOff
+	}
+	if toMode == "" {
+		toMode = ModeOff
+	}
+	if strictness[toMode] != strictness[fromMode] {
+		return strictness[toMode] < strictness[fromMode]
+	}
+	return toMode == ModePass && PassPercentage(to) < PassPercentage(from)
+}
+
+// Lock explains why a step is locked and what unlocks it.
+type Lock struct {
+	Step           int    `json:"step"`
+	Mode           string `json:"mode"`
+	RequiredStep   int    `json:"required_step"` // first earlier step whose condition is not met
+	PassPercentage int    `json:"pass_percentage,omitempty"`
+	BestPercentage *int   `json:"best_percentage,omitempty"` // best result so far on the required step
+	Condition      string `json:"condition"`
+}
+
+// met reports whether a step's own result satisfies the project's gating.
+func met(project models.Project, score *models.Score) bool {
+	switch project.GatingMode {
+	case ModeSequential:
+		return score != nil
+	case ModePass:
+		return score != nil && score.BestPercentage >= project.PassPercentage
+	}
+	return true
+}
+
+// Evaluate returns the lock of every locked step, keyed by step ID. steps
+// must be in roadmap order and scores keyed by step ID. A step is locked as
+// soon as any earlier step does not meet the condition.
+func Evaluate(project models.Project, steps []models.Step, scores map[uint]models.Score) map[uint]*Lock {
+	locks := make(map[uint]*Lock)
+	if project.GatingMode == "" || project.GatingMode == ModeOff {
+		return locks
+	}
+
+	var blocking *models.Step
+	for i := range steps {
+		s := steps[i]
+		if blocking != nil {
+			locks[s.ID] = newLock(project, s, *blocking, scores)
+			continue
+		}
+
+		var score *models.Score
+		if sc, ok := scores[s.ID]; ok {
+			score = &sc
+		}
+		if !met(project, score) {
+			blocking = &steps[i]
+		}
+	}
+	return locks
+}
+
+func newLock(project models.Project, step, required models.Step, scores map[uint]models.Score) *Lock {
+	lock := &Lock{
+		Step:         step.StepNumber,
+		Mode:         project.GatingMode,
+		RequiredStep: required.StepNumber,
+	}
+	if sc, ok := scores[required.ID]; ok {
+		best := sc.BestPercentage
+		lock.BestPercentage = &best
+	}
+
+	if project.GatingMode == ModePass {
+		lock.PassPercentage = project.PassPercentage
+		lock.Condition = fmt.Sprintf("Score at least %d%% on the quiz for step %d", project.PassPercentage, required.StepNumber)
+	} else {
+		lock.Condition = fmt.Sprintf("Complete the quiz for step %d", required.StepNumber)
+	}
+	return lock
+}
+
+// StepLock returns the lock of a single step, or nil when it is open.
+func StepLock(db *gorm.DB, project models.Project, stepNumber int) (*Lock, error) {
+	if project.GatingMode == "" || project.GatingMode == ModeOff {
+		return nil, nil
+	}
+
+	var steps []models.Step
+	if err := db.Where("project_id = ? AND step_number <= ?", project.ID, stepNumber).Order("step_number").Find(&steps).Error; err != nil {
+		return nil, err
+	}
+	stepIDs := make([]uint, len(steps))
+	for i, s := range steps {
+		stepIDs[i] = s.ID
+	}
+
+	var scores []models.Score
+	if err := db.Where("step_id IN ?", stepIDs).Find(&scores).Error; err != nil {
+		return nil, err
+	}
+	scoreMap := make(map[uint]models.Score)
+	for _, s := range scores {
+		scoreMap[s.StepID] = s
+	}
+
+	for _, s := range steps {
+		if s.StepNumber == stepNumber {
+			return Evaluate(project, steps, scoreMap)[s.ID], nil
+		}
+	}
+	return nil, nil
+}
+
+// StepLocks returns the lock of every locked step of the project, keyed by
+// step ID.
+func StepLocks(db *gorm.DB, project models.Project) (map[uint]*Lock, error) {
+	if project.GatingMode == "" || project.GatingMode == ModeOff {
+		return map[uint]*Lock{}, nil
+	}
+
+	var steps []models.Step
+	if err := db.Where("project_id = ?", project.ID).Order("step_number").Find(&steps).Error; err != nil {
+		return nil, err
+	}
+	var scores []models.Score
+	if err := db.Where("step_id IN (?)", db.Model(&models.Step{}).Select("id").Where("project_id = ?", project.ID)).Find(&scores).Error; err != nil {
+		return nil, err
+	}
+	scoreMap := make(map[uint]models.Score)
+	for _, s := range scores {
+		scoreMap[s.StepID] = s
+	}
+	return Evaluate(project, steps, scoreMap), nil
+}
+
+// Locks returns the lock of every locked step of the project, keyed by step
+// number.
+func Locks(db *gorm.DB, project models.Project) (map[int]*Lock, error) {
+	locks, err := StepLocks(db, project)
+	if err != nil {
+		return nil, err
+	}
+	byNumber := make(map[int]*Lock, len(locks))
+	for _, lock := range locks {
+		byNumber[lock.Step] = lock
+	}
+	return byNumber, nil
+}
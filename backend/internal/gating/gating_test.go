@@ -0,0 +1,34 @@
+package gating
+
+import (
+	"testing"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+)
+
+func TestLoosens(t *testing.T) {
+	setting := func(mode string, pass int) models.Project {
+		return models.Project{GatingMode: mode, PassPercentage: pass}
+	}
+	tests := []struct {
+		name     string
+		from, to models.Project
+		want     bool
+	}{
+		{"nothing imposed", setting("", 0), setting(ModeOff, 70), false},
+		{"pass to sequential", setting(ModePass, 70), setting(ModeSequential, 70), true},
+		{"sequential to off", setting(ModeSequential, 70), setting(ModeOff, 70), true},
+		{"sequential to pass", setting(ModeSequential, 70), setting(ModePass, 10), false},
+		{"lower pass percentage", setting(ModePass, 80), setting(ModePass, 60), true},
+		{"higher pass percentage", setting(ModePass, 80), setting(ModePass, 90), false},
+		{"pass percentage outside pass mode", setting(ModeSequential, 80), setting(ModeSequential, 60), false},
+		{"default pass percentage", setting(ModePass, 0), setting(ModePass, DefaultPassPercentage), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Loosens(tt.from, tt.to); got != tt.want {
+				t.Errorf("Loosens = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
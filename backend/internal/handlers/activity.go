@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/activity"
+	"github/meso1007/reverse-learn/backend/internal/completion"
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm/clause"
+)
+
+// defaultActivityLimit and maxActivityLimit bound the XP ledger page size.
+const (
+	defaultActivityLimit = 20
+	maxActivityLimit     = 100
+)
+
+// recordActivity awards the XP of the events and any badges they unlock,
+// returning the XP and the badges awarded.
+func (h *Handler) recordActivity(userID uint, events map[string]string) (int, []models.Badge) {
+	return completion.Record(h.DB, userID, events)
+}
+
+// GetMyXP returns the learner's total XP, level and the XP earned today.
+func (h *Handler) GetMyXP(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+	xp, err := activity.GetXP(h.DB, user, time.Now())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to compute XP"})
+	}
+	return c.JSON(http.StatusOK, xp)
+}
+
+// GetMyStreak returns the learner's daily streak in their time zone.
+func (h *Handler) GetMyStreak(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+	streak, err := activity.GetStreak(h.DB, user, time.Now())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch streak"})
+	}
+	return c.JSON(http.StatusOK, streak)
+}
+
+// GetMyActivity lists the learner's XP ledger, newest first.
+func (h *Handler) GetMyActivity(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	limit := defaultActivityLimit
+	if l, err := strconv.Atoi(c.QueryParam("limit")); err == nil && l > 0 {
+		limit = min(l, maxActivityLimit)
+	}
+
+	var events []models.ActivityEvent
+	if err := h.DB.Where("user_id = ?", userID).Order("created_at desc, id desc").Limit(limit).Find(&events).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch activity"})
+	}
+	return c.JSON(http.StatusOK, events)
+}
+
+// GetXPRules lists the XP rule of every event.
+func (h *Handler) GetXPRules(c echo.Context) error {
+	rules, err := activity.Rules(h.DB)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch XP rules"})
+	}
+	return c.JSON(http.StatusOK, rules)
+}
+
+// UpdateXPRule changes the XP an event awards. Omitted fields are kept.
+func (h *Handler) UpdateXPRule(c echo.Context) error {
+	event := c.Param("event")
+	if !activity.ValidEvent(event) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Unknown event"})
+	}
+
+	type UpdateRuleRequest struct {
+		XP       *int  `json:"xp"`
+		DailyCap *int  `json:"daily_cap"`
+		Enabled  *bool `json:"enabled"`
+	}
+	req := new(UpdateRuleRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	if (req.XP != nil && *req.XP < 0) || (req.DailyCap != nil && *req.DailyCap < 0) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "XP and daily cap must not be negative"})
+	}
+
+	rules, err := activity.Rules(h.DB)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch XP rules"})
+	}
+	var rule models.XPRule
+	for _, r := range rules {
+		if r.Event == event {
+			rule = r
+		}
+	}
+	if req.XP != nil {
+		rule.XP = *req.XP
+	}
+	if req.DailyCap != nil {
+		rule.DailyCap = *req.DailyCap
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	// Upsert with every column, so that zero values (e.g. disabling) are saved too
+	if err := h.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&rule).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update XP rule"})
+	}
+	return c.JSON(http.StatusOK, rule)
+}
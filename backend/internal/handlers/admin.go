@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 
+	"github/meso1007/reverse-learn/backend/internal/certificate"
 	"github/meso1007/reverse-learn/backend/internal/models"
 
 	"github.com/labstack/echo/v4"
@@ -80,7 +81,11 @@ func (h *Handler) DeleteUser(c echo.Context) error {
 	userID := c.Param("id")
 
 	// Delete user's projects and related data
+	certificate.Revoke(h.DB, h.DB.Model(&models.Project{}).Select("id").Where("user_id = ?", userID))
 	h.DB.Where("user_id = ?", userID).Delete(&models.Project{})
+	h.DB.Where("user_id = ?", userID).Delete(&models.LeaderboardEntry{})
+	h.DB.Where("user_id = ?", userID).Delete(&models.CohortMember{})
+	h.DB.Where("user_id = ?", userID).Delete(&models.Note{})
 
 	// Delete user
 	if result := h.DB.Delete(&models.User{}, userID); result.Error != nil {
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github/meso1007/reverse-learn/backend/internal/analytics"
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/questions"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// questionAnalytics is the per-question analytics response.
+type questionAnalytics struct {
+	analytics.QuestionStats
+	StepID   uint     `json:"step_id"`
+	Question string   `json:"question"`
+	Type     string   `json:"type"`
+	Options  []string `json:"options"`
+	// AnswerIndexes are the correct options (see analytics.AnswerKey); only
+	// shown to admins
+	AnswerIndexes []int  `json:"answer_indexes,omitempty"`
+	Difficulty    string `json:"difficulty"`
+	Topic         string `json:"topic"`
+	Remedial      bool   `json:"remedial"`
+	Suspicious    bool   `json:"suspicious"`
+}
+
+// questionAnalytics computes analytics for the quizzes matched by scope,
+// using answers from completed attempts only.
+func (h *Handler) questionAnalytics(scope func(db *gorm.DB) *gorm.DB) ([]questionAnalytics, error) {
+	var quizzes []models.Quiz
+	if err := h.DB.Scopes(scope).Find(&quizzes).Error; err != nil {
+		return nil, err
+	}
+	if len(quizzes) == 0 {
+		return []questionAnalytics{}, nil
+	}
+
+	quizIDs := make([]uint, len(quizzes))
+	answers := make(map[uint][]int)
+	optionCount := make(map[uint]int)
+	quizMap := make(map[uint]models.Quiz)
+	optionsMap := make(map[uint][]string)
+	for i, q := range quizzes {
+		options := questions.Options(q)
+
+		quizIDs[i] = q.ID
+		answers[q.ID] = analytics.AnswerKey(q)
+		if answers[q.ID] != nil {
+			optionCount[q.ID] = len(options)
+		}
+		quizMap[q.ID] = q
+		optionsMap[q.ID] = options
+	}
+
+	var rows []struct {
+		models.AttemptAnswer
+		AttemptPercentage int
+	}
+	err := h.DB.Table("attempt_answers").
+		Select("attempt_answers.*, quiz_attempts.percentage AS attempt_percentage").
+		Joins("JOIN quiz_attempts ON quiz_attempts.id = attempt_answers.attempt_id").
+		Where("quiz_attempts.status = ? AND attempt_answers.answered_at IS NOT NULL AND attempt_answers.quiz_id IN ?", "completed", quizIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Each answer is graded by its question's type
+	responses := make([]analytics.Response, 0, len(rows))
+	for _, row := range rows {
+		if r, ok := analytics.ResponseOf(quizMap[row.QuizID], row.AttemptAnswer, row.AttemptPercentage); ok {
+			responses = append(responses, r)
+		}
+	}
+
+	stats := analytics.Compute(responses, answers, optionCount)
+	result := make([]questionAnalytics, 0, len(stats))
+	for _, st := range stats {
+		q := quizMap[st.QuizID]
+		result = append(result, questionAnalytics{
+			QuestionStats: st,
+			StepID:        q.StepID,
+			Question:      q.Question,
+			Type:          questions.TypeOf(q),
+			Options:       optionsMap[q.ID],
+			AnswerIndexes: answers[q.ID],
+			Difficulty:    q.Difficulty,
+			Topic:         q.Topic,
+			Remedial:      q.Remedial,
+			Suspicious:    analytics.Suspicious(st),
+		})
+	}
+	return result, nil
+}
+
+// GetStepAnalytics returns per-question analytics for the learner's own step.
+func (h *Handler) GetStepAnalytics(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	step, err := h.findProjectStep(project.ID, c.Param("stepNumber"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Step not found"})
+	}
+
+	result, err := h.questionAnalytics(func(db *gorm.DB) *gorm.DB {
+		return db.Where("step_id = ?", step.ID)
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to compute analytics"})
+	}
+	// Answers stay hidden: the pool also holds questions the learner has not
+	// been served yet
+	for i := range result {
+		result[i].AnswerIndexes = nil
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) GetQuizAnalytics(c echo.Context) error {
+	quizID := c.Param("id")
+
+	result, err := h.questionAnalytics(func(db *gorm.DB) *gorm.DB {
+		return db.Where("id = ?", quizID)
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to compute analytics"})
+	}
+	if len(result) == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "No answers recorded for this question"})
+	}
+
+	return c.JSON(http.StatusOK, result[0])
+}
+
+// GetSuspiciousQuestions lists questions whose answer patterns suggest a wrong
+// or ambiguous answer key, e.g. the "correct" option is rarely chosen by high scorers.
+func (h *Handler) GetSuspiciousQuestions(c echo.Context) error {
+	minResponses := analytics.MinResponses
+	if v, err := strconv.Atoi(c.QueryParam("min_responses")); err == nil && v > minResponses {
+		minResponses = v
+	}
+
+	// Only questions with enough answers can be flagged
+	answered := h.DB.Table("attempt_answers").
+		Select("attempt_answers.quiz_id").
+		Joins("JOIN quiz_attempts ON quiz_attempts.id = attempt_answers.attempt_id").
+		Where("quiz_attempts.status = ? AND attempt_answers.answered_at IS NOT NULL", "completed").
+		Group("attempt_answers.quiz_id").
+		Having("COUNT(*) >= ?", minResponses)
+
+	result, err := h.questionAnalytics(func(db *gorm.DB) *gorm.DB {
+		return db.Where("id IN (?)", answered)
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to compute analytics"})
+	}
+
+	suspicious := []questionAnalytics{}
+	for _, q := range result {
+		if q.Suspicious {
+			suspicious = append(suspicious, q)
+		}
+	}
+
+	return c.JSON(http.StatusOK, suspicious)
+}
+
+// GetDifficultyCalibration reports whether the difficulty tags of generated
+// questions match how often learners actually answer them correctly.
+func (h *Handler) GetDifficultyCalibration(c echo.Context) error {
+	minResponses := analytics.MinResponses
+	if v, err := strconv.Atoi(c.QueryParam("min_responses")); err == nil && v > 0 {
+		minResponses = v
+	}
+
+	result, err := h.questionAnalytics(func(db *gorm.DB) *gorm.DB {
+		return db.Where("difficulty <> ?", "")
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to compute analytics"})
+	}
+
+	stats := make([]analytics.QuestionStats, len(result))
+	difficulty := make(map[uint]string)
+	for i, q := range result {
+		stats[i] = q.QuestionStats
+		difficulty[q.QuizID] = q.Difficulty
+	}
+
+	return c.JSON(http.StatusOK, analytics.Calibrate(stats, difficulty, minResponses))
+}
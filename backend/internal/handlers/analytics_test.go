@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestStepAnalyticsHidesAnswers(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.DB, "learner@example.com")
+	project := createProject(t, h.DB, user.ID, 1)
+
+	_, attempt := call(t, h.StartAttempt, user.ID, http.MethodPost, "/", nil, "id", id(project.ID), "stepNumber", "1")
+	if code, resp := call(t, h.SubmitAttempt, user.ID, http.MethodPost, "/", map[string]interface{}{"answers": answersOf(attempt)}, "attemptId", id(attempt["attempt_id"])); code != http.StatusOK {
+		t.Fatalf("SubmitAttempt = %d %v", code, resp)
+	}
+
+	code, stats := callList(t, h.GetStepAnalytics, user.ID, http.MethodGet, "/", nil, "id", id(project.ID), "stepNumber", "1")
+	if code != http.StatusOK || len(stats) != 2 {
+		t.Fatalf("GetStepAnalytics = %d with %d questions, want 2", code, len(stats))
+	}
+	for _, q := range stats {
+		if _, ok := q["answer_indexes"]; ok {
+			t.Errorf("question %v reveals its answer", q["quiz_id"])
+		}
+		if q["correct_rate"] != float64(1) || q["responses"] != float64(1) {
+			t.Errorf("question %v: %v responses at %v, want 1 at 1", q["quiz_id"], q["responses"], q["correct_rate"])
+		}
+	}
+
+	quizID := id(stats[0]["quiz_id"])
+	code, admin := call(t, h.GetQuizAnalytics, user.ID, http.MethodGet, "/", nil, "id", quizID)
+	if code != http.StatusOK {
+		t.Fatalf("GetQuizAnalytics = %d %v", code, admin)
+	}
+	if keys, ok := admin["answer_indexes"].([]interface{}); !ok || len(keys) != 1 || keys[0] != float64(0) {
+		t.Errorf("admin answer_indexes = %v, want [0]", admin["answer_indexes"])
+	}
+}
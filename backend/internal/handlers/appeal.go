@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/completion"
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/questions"
+	"github/meso1007/reverse-learn/backend/internal/scoring"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// AppealGrade asks for a human review of the model's grade of a free response.
+// Answers whose grading failed can be appealed too.
+func (h *Handler) AppealGrade(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	type AppealRequest struct {
+		Reason string `json:"reason"`
+	}
+	req := new(AppealRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Reason is required"})
+	}
+
+	attempt, err := h.findUserAttempt(userID, c.Param("attemptId"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Attempt not found"})
+	}
+
+	answer := findAttemptAnswer(&attempt, c.Param("quizId"))
+	if answer == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Question is not part of this attempt"})
+	}
+	if answer.GradingStatus != "graded" && answer.GradingStatus != "failed" {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Only graded free responses can be appealed"})
+	}
+
+	var open int64
+	h.DB.Model(&models.GradeAppeal{}).Where("attempt_answer_id = ? AND status = ?", answer.ID, "open").Count(&open)
+	if open > 0 {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "An appeal for this answer is already open"})
+	}
+
+	appeal := models.GradeAppeal{
+		AttemptAnswerID: answer.ID,
+		UserID:          userID,
+		Reason:          req.Reason,
+		Status:          "open",
+		OriginalPoints:  answer.Points,
+		CreatedAt:       time.Now(),
+	}
+	if err := h.DB.Create(&appeal).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create appeal"})
+	}
+
+	return c.JSON(http.StatusCreated, appeal)
+}
+
+// GetAppeals lists the user's appeals, newest first.
+func (h *Handler) GetAppeals(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	var appeals []models.GradeAppeal
+	if err := h.DB.Where("user_id = ?", userID).Order("created_at desc").Find(&appeals).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch appeals"})
+	}
+	return c.JSON(http.StatusOK, appeals)
+}
+
+// appealDetail is an appeal with what a reviewer needs to judge it.
+type appealDetail struct {
+	models.GradeAppeal
+	QuizID        uint                  `json:"quiz_id"`
+	AttemptID     uint                  `json:"attempt_id"`
+	Question      string                `json:"question"`
+	Rubric        []questions.Criterion `json:"rubric"`
+	ModelAnswer   string                `json:"model_answer"`
+	Answer        string                `json:"answer"`
+	GradingStatus string                `json:"grading_status"`
+	Points        int                   `json:"points"`
+	MaxPoints     int                   `json:"max_points"`
+	Feedback      string                `json:"feedback"`
+	Criteria      json.RawMessage       `json:"criteria,omitempty"`
+}
+
+// GetAdminAppeals lists appeals with the given status (open by default), oldest first.
+func (h *Handler) GetAdminAppeals(c echo.Context) error {
+	status := c.QueryParam("status")
+	if status == "" {
+		status = "open"
+	}
+
+	var appeals []models.GradeAppeal
+	if err := h.DB.Where("status = ?", status).Order("created_at").Find(&appeals).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch appeals"})
+	}
+
+	details := make([]appealDetail, 0, len(appeals))
+	for _, appeal := range appeals {
+		var answer models.AttemptAnswer
+		if err := h.DB.First(&answer, appeal.AttemptAnswerID).Error; err != nil {
+			continue
+		}
+		var quiz models.Quiz
+		h.DB.First(&quiz, answer.QuizID)
+
+		given := questions.ResponseOf(answer)
+		key := questions.AnswerOf(quiz)
+
+		d := appealDetail{
+			GradeAppeal:   appeal,
+			QuizID:        quiz.ID,
+			AttemptID:     answer.AttemptID,
+			Question:      quiz.Question,
+			Rubric:        key.Rubric,
+			ModelAnswer:   key.ModelAnswer,
+			Answer:        given.Text,
+			GradingStatus: answer.GradingStatus,
+			Points:        answer.Points,
+			MaxPoints:     answer.MaxPoints,
+			Feedback:      answer.Feedback,
+		}
+		if len(answer.CriteriaScores) > 0 {
+			d.Criteria = answer.CriteriaScores
+		}
+		details = append(details, d)
+	}
+
+	return c.JSON(http.StatusOK, details)
+}
+
+// ResolveAppeal accepts or rejects an open appeal. Accepting overrides the
+// answer's points with the reviewer's and updates the attempt and step scores.
+func (h *Handler) ResolveAppeal(c echo.Context) error {
+	reviewerID := c.Get("userID").(uint)
+
+	type ResolveRequest struct {
+		Status string `json:"status"` // accepted, rejected
+		Points *int   `json:"points"` // required when accepted
+		Note   string `json:"note"`
+	}
+	req := new(ResolveRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	if req.Status != "accepted" && req.Status != "rejected" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Status must be accepted or rejected"})
+	}
+
+	var appeal models.GradeAppeal
+	if err := h.DB.First(&appeal, c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Appeal not found"})
+	}
+	if appeal.Status != "open" {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Appeal is already resolved"})
+	}
+
+	var answer models.AttemptAnswer
+	if err := h.DB.First(&answer, appeal.AttemptAnswerID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Answer not found"})
+	}
+
+	if req.Status == "accepted" {
+		if req.Points == nil || *req.Points < 0 || *req.Points > answer.MaxPoints {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Points must be between 0 and the answer's max points"})
+		}
+	}
+
+	now := time.Now()
+	appeal.Status = req.Status
+	appeal.ReviewerID = &reviewerID
+	appeal.ReviewerNote = req.Note
+	appeal.ResolvedAt = &now
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if req.Status == "accepted" {
+			appeal.ResolvedPoints = req.Points
+			answer.Points = *req.Points
+			answer.GradingStatus = "overridden"
+			answer.IsCorrect = answer.Points*100 >= answer.MaxPoints*questions.FreeResponsePassPercent
+			if req.Note != "" {
+				answer.Feedback = req.Note
+			}
+			if err := tx.Save(&answer).Error; err != nil {
+				return err
+			}
+			if err := scoring.RefreshAttempt(tx, answer.AttemptID); err != nil {
+				return err
+			}
+		}
+		return tx.Save(&appeal).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to resolve appeal"})
+	}
+	// The new points may make the attempt pass
+	if req.Status == "accepted" {
+		var attempt models.QuizAttempt
+		if err := h.DB.First(&attempt, answer.AttemptID).Error; err == nil && attempt.Status == "completed" {
+			completion.Attempt(h.DB, h.Certificates, attempt)
+		}
+	}
+
+	return c.JSON(http.StatusOK, appeal)
+}
@@ -0,0 +1,552 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/adaptive"
+	"github/meso1007/reverse-learn/backend/internal/completion"
+	"github/meso1007/reverse-learn/backend/internal/gating"
+	"github/meso1007/reverse-learn/backend/internal/leaderboard"
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/pool"
+	"github/meso1007/reverse-learn/backend/internal/questions"
+	"github/meso1007/reverse-learn/backend/internal/review"
+	"github/meso1007/reverse-learn/backend/internal/scoring"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// attemptQuestion is a question as served to the learner. The answer and
+// explanation are only filled in once it is answered or the attempt is
+// completed.
+// Option indexes are displayed indexes.
+type attemptQuestion struct {
+	QuizID      uint     `json:"quiz_id"`
+	Position    int      `json:"position"`
+	Type        string   `json:"type"`
+	Question    string   `json:"question"`
+	Code        string   `json:"code,omitempty"`
+	Options     []string `json:"options"`
+	Answered    bool     `json:"answered"`
+	Choice      *int     `json:"choice,omitempty"`  // option chosen by the learner (single-choice types)
+	Choices     []int    `json:"choices,omitempty"` // options selected, or the order given, by the learner
+	Text        string   `json:"text,omitempty"`    // text typed by the learner
+	IsCorrect   *bool    `json:"is_correct,omitempty"`
+	AnswerIndex *int     `json:"answer_index,omitempty"`   // correct option (single-choice types)
+	AnswerOrder []int    `json:"answer_indexes,omitempty"` // correct options, or the correct order
+	AnswerText  string   `json:"answer_text,omitempty"`    // accepted answer of text types, or the model answer
+	Explanation string   `json:"explanation,omitempty"`
+	// Free responses only
+	GradingStatus string          `json:"grading_status,omitempty"` // pending, graded, failed, overridden
+	Points        *int            `json:"points,omitempty"`
+	MaxPoints     int             `json:"max_points,omitempty"`
+	Feedback      string          `json:"feedback,omitempty"`
+	Criteria      json.RawMessage `json:"criteria,omitempty"`
+}
+
+// buildAttemptQuestion renders a served question using the option order
+// stored on the answer row. The result is only revealed once the question
+// was answered or the attempt is completed.
+func buildAttemptQuestion(a models.AttemptAnswer, q models.Quiz, reveal bool) attemptQuestion {
+	options := questions.Options(q)
+	var order []int
+	json.Unmarshal(a.OptionOrder, &order)
+
+	displayed := make([]string, 0, len(order))
+	for _, idx := range order {
+		if idx >= 0 && idx < len(options) {
+			displayed = append(displayed, options[idx])
+		}
+	}
+
+	t := questions.TypeOf(q)
+	resp := attemptQuestion{
+		QuizID:   q.ID,
+		Position: a.Position,
+		Type:     t,
+		Question: q.Question,
+		Code:     q.Code,
+		Options:  displayed,
+		Answered: a.AnsweredAt != nil,
+	}
+	if !reveal && a.AnsweredAt == nil {
+		return resp
+	}
+
+	resp.Explanation = q.Explanation
+	if t == questions.TypeFreeResponse {
+		resp.GradingStatus = a.GradingStatus
+		resp.MaxPoints = questions.AnswerOf(q).MaxPoints()
+	}
+	// A free response has no result until it is graded
+	if a.GradingStatus == "" || a.GradingStatus == "graded" || a.GradingStatus == "overridden" {
+		isCorrect := a.IsCorrect
+		resp.IsCorrect = &isCorrect
+	}
+	if a.GradingStatus == "graded" || a.GradingStatus == "overridden" {
+		points := a.Points
+		resp.Points = &points
+		resp.MaxPoints = a.MaxPoints
+		resp.Feedback = a.Feedback
+		if len(a.CriteriaScores) > 0 {
+			resp.Criteria = a.CriteriaScores
+		}
+	}
+
+	answer := questions.Reveal(q, order)
+	if questions.SingleChoice(t) {
+		resp.AnswerIndex = &answer.Choice
+	}
+	resp.AnswerOrder = answer.Choices
+	resp.AnswerText = answer.Text
+
+	if a.AnsweredAt != nil {
+		given := questions.FromOriginal(order, questions.ResponseOf(a))
+		if questions.SingleChoice(t) && given.Choice >= 0 {
+			resp.Choice = &given.Choice
+		}
+		resp.Choices = given.Choices
+		resp.Text = given.Text
+	}
+	return resp
+}
+
+// gradeAnswer records the learner's response (in displayed indexes) on the answer row.
+func gradeAnswer(a *models.AttemptAnswer, q models.Quiz, r questions.Response) error {
+	var order []int
+	json.Unmarshal(a.OptionOrder, &order)
+
+	given, err := questions.ToOriginal(q, order, r)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	a.ChosenIndex = -1
+	if questions.SingleChoice(questions.TypeOf(q)) {
+		a.ChosenIndex = given.Choice
+	}
+	a.Response, _ = json.Marshal(given)
+	a.IsCorrect = questions.Grade(q, given)
+	a.AnsweredAt = &now
+	if questions.TypeOf(q) == questions.TypeFreeResponse {
+		// Graded later by the model; see queueGrading
+		a.GradingStatus = "pending"
+		a.MaxPoints = questions.AnswerOf(q).MaxPoints()
+	}
+	return nil
+}
+
+// queueGrading queues the model grading of a free response. The answer is
+// marked failed when it cannot be queued so that the learner can appeal.
+func (h *Handler) queueGrading(userID uint, a *models.AttemptAnswer) {
+	inputBytes, _ := json.Marshal(models.GradeAnswerRequest{AttemptAnswerID: a.ID})
+	job := models.Job{
+		UserID: userID,
+		Type:   "grade_free_response",
+		Input:  inputBytes,
+	}
+	if err := h.queueJob(&job); err != nil {
+		a.GradingStatus = "failed"
+		h.DB.Model(a).Update("grading_status", a.GradingStatus)
+	}
+}
+
+// findUserAttempt loads an attempt owned by the user with its answers in served order.
+func (h *Handler) findUserAttempt(userID uint, attemptID string) (models.QuizAttempt, error) {
+	var attempt models.QuizAttempt
+	err := h.DB.Where("id = ? AND user_id = ?", attemptID, userID).
+		Preload("Answers", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		First(&attempt).Error
+	return attempt, err
+}
+
+// findAttemptAnswer returns the attempt's answer row for a quiz ID given as
+// a path parameter, or nil if the quiz was not served in the attempt.
+func findAttemptAnswer(attempt *models.QuizAttempt, quizID string) *models.AttemptAnswer {
+	id, err := strconv.ParseUint(quizID, 10, 64)
+	if err != nil {
+		return nil
+	}
+	for i := range attempt.Answers {
+		if attempt.Answers[i].QuizID == uint(id) {
+			return &attempt.Answers[i]
+		}
+	}
+	return nil
+}
+
+// attemptQuizzes loads the quizzes served in an attempt, keyed by ID.
+func (h *Handler) attemptQuizzes(attempt models.QuizAttempt) map[uint]models.Quiz {
+	quizIDs := make([]uint, len(attempt.Answers))
+	for i, a := range attempt.Answers {
+		quizIDs[i] = a.QuizID
+	}
+	var quizzes []models.Quiz
+	h.DB.Where("id IN ?", quizIDs).Find(&quizzes)
+
+	quizMap := make(map[uint]models.Quiz)
+	for _, q := range quizzes {
+		quizMap[q.ID] = q
+	}
+	return quizMap
+}
+
+func attemptResponse(attempt models.QuizAttempt, quizMap map[uint]models.Quiz) map[string]interface{} {
+	questions := make([]attemptQuestion, 0, len(attempt.Answers))
+	for _, a := range attempt.Answers {
+		q, ok := quizMap[a.QuizID]
+		if !ok {
+			continue
+		}
+		// An abandoned attempt was never submitted, so it stays hidden
+		questions = append(questions, buildAttemptQuestion(a, q, attempt.Status == "completed"))
+	}
+
+	resp := map[string]interface{}{
+		"attempt_id": attempt.ID,
+		"status":     attempt.Status,
+		"remedial":   attempt.Remedial,
+		"started_at": attempt.CreatedAt,
+		"questions":  questions,
+	}
+	if attempt.Status == "completed" {
+		resp["score"] = attempt.Score
+		resp["total"] = attempt.Total
+		resp["percentage"] = attempt.Percentage
+		resp["duration_seconds"] = attempt.DurationSeconds
+		resp["completed_at"] = attempt.CompletedAt
+	}
+	return resp
+}
+
+func (h *Handler) respondAttempt(c echo.Context, status int, attempt models.QuizAttempt, quizMap map[uint]models.Quiz) error {
+	return c.JSON(status, attemptResponse(attempt, quizMap))
+}
+
+// StartAttempt serves the step's questions with shuffled options and without answers.
+// With ?remedial=true the step's remedial quiz is served instead.
+func (h *Handler) StartAttempt(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	remedial := c.QueryParam("remedial") == "true"
+
+	var step models.Step
+	if err := h.DB.Where("project_id = ? AND step_number = ?", project.ID, c.Param("stepNumber")).Preload("Quizzes", "remedial = ? AND hidden = ?", remedial, false).First(&step).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Step not found"})
+	}
+	if len(step.Quizzes) == 0 {
+		if remedial {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "No remedial quiz for this step"})
+		}
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Quizzes have not been generated for this step"})
+	}
+
+	lock, err := gating.StepLock(h.DB, project, step.StepNumber)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check step lock"})
+	}
+	if lock != nil {
+		return respondStepLocked(c, lock)
+	}
+
+	// Each attempt asks a sample of the step's pool, balanced by difficulty
+	// and favouring the questions the learner has seen least. The questions
+	// served are recorded as the attempt's answers.
+	if !remedial && len(step.Quizzes) > pool.AttemptSize {
+		mix := adaptive.DefaultMix(project.Level)
+		if profile, err := adaptive.Build(h.DB, project); err == nil {
+			mix = profile.Mix
+		}
+		served, err := pool.Served(h.DB, userID, step.ID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to start attempt"})
+		}
+		step.Quizzes = pool.Sample(step.Quizzes, served, mix, pool.AttemptSize)
+	}
+
+	attempt := models.QuizAttempt{
+		UserID:    userID,
+		StepID:    step.ID,
+		Status:    "in_progress",
+		Remedial:  remedial,
+		Total:     len(step.Quizzes),
+		CreatedAt: time.Now(),
+	}
+	for i, q := range step.Quizzes {
+		orderBytes, _ := json.Marshal(questions.NewOrder(q))
+
+		attempt.Answers = append(attempt.Answers, models.AttemptAnswer{
+			QuizID:      q.ID,
+			Position:    i,
+			OptionOrder: orderBytes,
+			ChosenIndex: -1,
+		})
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		// Only one attempt per step is open at a time
+		if err := tx.Model(&models.QuizAttempt{}).
+			Where("user_id = ? AND step_id = ? AND status = ?", userID, step.ID, "in_progress").
+			Update("status", "abandoned").Error; err != nil {
+			return err
+		}
+		return tx.Create(&attempt).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to start attempt"})
+	}
+
+	quizMap := make(map[uint]models.Quiz)
+	for _, q := range step.Quizzes {
+		quizMap[q.ID] = q
+	}
+	return h.respondAttempt(c, http.StatusCreated, attempt, quizMap)
+}
+
+func (h *Handler) GetAttempt(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	attempt, err := h.findUserAttempt(userID, c.Param("attemptId"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Attempt not found"})
+	}
+
+	return h.respondAttempt(c, http.StatusOK, attempt, h.attemptQuizzes(attempt))
+}
+
+// AnswerQuestion grades a single answer and reveals the correct option and explanation.
+func (h *Handler) AnswerQuestion(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	type AnswerRequest struct {
+		QuizID uint `json:"quiz_id"`
+		questions.Response
+	}
+	req := new(AnswerRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	attempt, err := h.findUserAttempt(userID, c.Param("attemptId"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Attempt not found"})
+	}
+	if attempt.Status != "in_progress" {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Attempt is no longer in progress"})
+	}
+
+	var answer *models.AttemptAnswer
+	for i := range attempt.Answers {
+		if attempt.Answers[i].QuizID == req.QuizID {
+			answer = &attempt.Answers[i]
+			break
+		}
+	}
+	if answer == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Question is not part of this attempt"})
+	}
+	if answer.AnsweredAt != nil {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Question already answered"})
+	}
+
+	var quiz models.Quiz
+	if err := h.DB.First(&quiz, answer.QuizID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Question not found"})
+	}
+
+	// Time taken is measured since the previous answer (or the start of the attempt)
+	since := attempt.CreatedAt
+	for _, a := range attempt.Answers {
+		if a.AnsweredAt != nil && a.AnsweredAt.After(since) {
+			since = *a.AnsweredAt
+		}
+	}
+
+	if err := gradeAnswer(answer, quiz, req.Response); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid answer for this question type"})
+	}
+	answer.TimeTakenMs = int(answer.AnsweredAt.Sub(since).Milliseconds())
+	if err := h.DB.Save(answer).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save answer"})
+	}
+	if answer.GradingStatus == "pending" {
+		h.queueGrading(userID, answer)
+	}
+
+	return c.JSON(http.StatusOK, buildAttemptQuestion(*answer, quiz, true))
+}
+
+var errAttemptNotInProgress = errors.New("attempt is no longer in progress")
+
+// SubmitAttempt grades any remaining answers sent in the batch, completes the
+// attempt and updates the step score. Unanswered questions count as wrong.
+// Free responses count once graded, which updates the score again. A low
+// score queues a remedial quiz for the step.
+func (h *Handler) SubmitAttempt(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	type SubmitRequest struct {
+		Answers []struct {
+			QuizID uint `json:"quiz_id"`
+			questions.Response
+		} `json:"answers"`
+	}
+	req := new(SubmitRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	attempt, err := h.findUserAttempt(userID, c.Param("attemptId"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Attempt not found"})
+	}
+	if attempt.Status != "in_progress" {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Attempt is no longer in progress"})
+	}
+
+	var user models.User
+	h.DB.First(&user, userID)
+
+	quizMap := h.attemptQuizzes(attempt)
+	responses := make(map[uint]questions.Response)
+	for _, a := range req.Answers {
+		responses[a.QuizID] = a.Response
+	}
+
+	correct := 0
+	var toGrade []int
+	for i := range attempt.Answers {
+		a := &attempt.Answers[i]
+		q, ok := quizMap[a.QuizID]
+		if !ok {
+			continue
+		}
+		// Invalid batch answers are left unanswered and count as wrong
+		if r, ok := responses[a.QuizID]; ok && a.AnsweredAt == nil {
+			if gradeAnswer(a, q, r) == nil && a.GradingStatus == "pending" {
+				toGrade = append(toGrade, i)
+			}
+		}
+		if a.IsCorrect {
+			correct++
+		}
+	}
+
+	now := time.Now()
+	attempt.Status = "completed"
+	attempt.Score = correct
+	attempt.Total = len(attempt.Answers)
+	attempt.Percentage = scoring.Percentage(correct, attempt.Total)
+	attempt.DurationSeconds = int(now.Sub(attempt.CreatedAt).Seconds())
+	attempt.CompletedAt = &now
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the request that moves the attempt out of in_progress goes on,
+		// so concurrent submits cannot count it twice
+		result := tx.Model(&models.QuizAttempt{}).
+			Where("id = ? AND status = ?", attempt.ID, "in_progress").
+			Update("status", "completed")
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return errAttemptNotInProgress
+		}
+		for i := range attempt.Answers {
+			if err := tx.Save(&attempt.Answers[i]).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Omit("Answers").Save(&attempt).Error; err != nil {
+			return err
+		}
+		if _, err := scoring.RefreshStepScore(tx, attempt.StepID); err != nil {
+			return err
+		}
+		if !attempt.Remedial {
+			if err := leaderboard.AddAttempt(tx, userID, attempt.Percentage, now); err != nil {
+				return err
+			}
+		}
+		// Missed questions come back later in the review queue
+		return review.RecordAttempt(tx, attempt, user.ReviewCorrectAnswers)
+	})
+	if errors.Is(err, errAttemptNotInProgress) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Attempt is no longer in progress"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to submit attempt"})
+	}
+
+	for _, i := range toGrade {
+		h.queueGrading(userID, &attempt.Answers[i])
+	}
+
+	resp := attemptResponse(attempt, quizMap)
+	pending := 0
+	for _, a := range attempt.Answers {
+		if a.GradingStatus == "pending" {
+			pending++
+		}
+	}
+	resp["pending_grading"] = pending
+	if !attempt.Remedial && attempt.Percentage < adaptive.RemedialThreshold {
+		if jobID, ok := h.queueRemedialQuiz(userID, attempt); ok {
+			resp["remedial_job_id"] = jobID
+		}
+	}
+	// Awarded again if grading the free responses makes the attempt pass
+	outcome := completion.Attempt(h.DB, h.Certificates, attempt)
+	resp["xp_awarded"], resp["badges_earned"] = outcome.XP, outcome.Badges
+	if outcome.Certificate != nil {
+		resp["certificate_code"] = outcome.Certificate.Code
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// queueRemedialQuiz queues a remedial quiz for the attempt's step unless one
+// is already being generated.
+func (h *Handler) queueRemedialQuiz(userID uint, attempt models.QuizAttempt) (uint, bool) {
+	var step models.Step
+	if err := h.DB.First(&step, attempt.StepID).Error; err != nil {
+		return 0, false
+	}
+
+	req := models.RemedialQuizRequest{
+		ProjectID:  step.ProjectID,
+		StepNumber: step.StepNumber,
+		AttemptID:  attempt.ID,
+	}
+
+	var pending []models.Job
+	h.DB.Where("user_id = ? AND type = ? AND status IN ?", userID, "generate_remedial_quiz", []string{"pending", "processing"}).Find(&pending)
+	for _, j := range pending {
+		var r models.RemedialQuizRequest
+		json.Unmarshal(j.Input, &r)
+		if r.ProjectID == req.ProjectID && r.StepNumber == req.StepNumber {
+			return j.ID, true
+		}
+	}
+
+	inputBytes, _ := json.Marshal(req)
+	job := models.Job{
+		UserID: userID,
+		Type:   "generate_remedial_quiz",
+		Input:  inputBytes,
+	}
+	if err := h.queueJob(&job); err != nil {
+		return 0, false
+	}
+	return job.ID, true
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github/meso1007/reverse-learn/backend/internal/activity"
+	"github/meso1007/reverse-learn/backend/internal/models"
+)
+
+// answersOf returns a submission answering every question of an attempt
+// response with the displayed option "right".
+func answersOf(resp map[string]interface{}) []map[string]interface{} {
+	return answersWith(resp, "right")
+}
+
+// answersWith answers every question of an attempt response with the
+// displayed option.
+func answersWith(resp map[string]interface{}, option string) []map[string]interface{} {
+	var answers []map[string]interface{}
+	for _, q := range resp["questions"].([]interface{}) {
+		q := q.(map[string]interface{})
+		choice := -1
+		for i, o := range q["options"].([]interface{}) {
+			if o == option {
+				choice = i
+			}
+		}
+		answers = append(answers, map[string]interface{}{"quiz_id": q["quiz_id"], "choice": choice})
+	}
+	return answers
+}
+
+func revealed(resp map[string]interface{}) bool {
+	for _, q := range resp["questions"].([]interface{}) {
+		q := q.(map[string]interface{})
+		if _, ok := q["answer_index"]; ok {
+			return true
+		}
+		if _, ok := q["explanation"]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAbandonedAttemptKeepsAnswersHidden(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.DB, "learner@example.com")
+	project := createProject(t, h.DB, user.ID, 1)
+
+	code, first := call(t, h.StartAttempt, user.ID, http.MethodPost, "/", nil, "id", id(project.ID), "stepNumber", "1")
+	if code != http.StatusCreated {
+		t.Fatalf("StartAttempt = %d %v", code, first)
+	}
+	if revealed(first) {
+		t.Fatal("a new attempt reveals its answers")
+	}
+
+	// Starting again abandons the first attempt
+	code, second := call(t, h.StartAttempt, user.ID, http.MethodPost, "/", nil, "id", id(project.ID), "stepNumber", "1")
+	if code != http.StatusCreated {
+		t.Fatalf("StartAttempt = %d %v", code, second)
+	}
+
+	code, abandoned := call(t, h.GetAttempt, user.ID, http.MethodGet, "/", nil, "attemptId", id(first["attempt_id"]))
+	if code != http.StatusOK || abandoned["status"] != "abandoned" {
+		t.Fatalf("GetAttempt = %d %v, want the abandoned attempt", code, abandoned)
+	}
+	if revealed(abandoned) {
+		t.Error("an abandoned attempt reveals its answers")
+	}
+
+	code, submitted := call(t, h.SubmitAttempt, user.ID, http.MethodPost, "/", map[string]interface{}{"answers": answersOf(second)}, "attemptId", id(second["attempt_id"]))
+	if code != http.StatusOK {
+		t.Fatalf("SubmitAttempt = %d %v", code, submitted)
+	}
+	if submitted["status"] != "completed" || submitted["percentage"] != float64(100) {
+		t.Errorf("SubmitAttempt = %v, want a completed attempt at 100%%", submitted)
+	}
+	if !revealed(submitted) {
+		t.Error("a completed attempt keeps its answers hidden")
+	}
+}
+
+func TestSubmitAttemptOnlyOnce(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.DB, "learner@example.com")
+	other := createUser(t, h.DB, "other@example.com")
+	project := createProject(t, h.DB, user.ID, 1)
+
+	code, attempt := call(t, h.StartAttempt, user.ID, http.MethodPost, "/", nil, "id", id(project.ID), "stepNumber", "1")
+	if code != http.StatusCreated {
+		t.Fatalf("StartAttempt = %d %v", code, attempt)
+	}
+	body := map[string]interface{}{"answers": answersOf(attempt)}
+
+	if code, _ := call(t, h.SubmitAttempt, other.ID, http.MethodPost, "/", body, "attemptId", id(attempt["attempt_id"])); code != http.StatusNotFound {
+		t.Errorf("SubmitAttempt by another user = %d, want 404", code)
+	}
+	if code, resp := call(t, h.SubmitAttempt, user.ID, http.MethodPost, "/", body, "attemptId", id(attempt["attempt_id"])); code != http.StatusOK {
+		t.Fatalf("SubmitAttempt = %d %v", code, resp)
+	}
+	if code, _ := call(t, h.SubmitAttempt, user.ID, http.MethodPost, "/", body, "attemptId", id(attempt["attempt_id"])); code != http.StatusConflict {
+		t.Errorf("second SubmitAttempt = %d, want 409", code)
+	}
+}
+
+func TestStepCompletedNeedsAPassOnAGeneratedStep(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.DB, "learner@example.com")
+	project := createProject(t, h.DB, user.ID, 1)
+
+	attempt := func(stepNumber, option string) {
+		t.Helper()
+		code, started := call(t, h.StartAttempt, user.ID, http.MethodPost, "/", nil, "id", id(project.ID), "stepNumber", stepNumber)
+		if code != http.StatusCreated {
+			t.Fatalf("StartAttempt = %d %v", code, started)
+		}
+		body := map[string]interface{}{"answers": answersWith(started, option)}
+		if code, resp := call(t, h.SubmitAttempt, user.ID, http.MethodPost, "/", body, "attemptId", id(started["attempt_id"])); code != http.StatusOK {
+			t.Fatalf("SubmitAttempt = %d %v", code, resp)
+		}
+	}
+	completions := func() int64 {
+		var n int64
+		h.DB.Model(&models.ActivityEvent{}).Where("user_id = ? AND event = ?", user.ID, activity.EventStepCompleted).Count(&n)
+		return n
+	}
+
+	attempt("1", "wrong")
+	if n := completions(); n != 0 {
+		t.Errorf("a failed attempt completed the step (%d awards)", n)
+	}
+	attempt("1", "right")
+	if n := completions(); n != 1 {
+		t.Errorf("a passing attempt gave %d step awards, want 1", n)
+	}
+
+	// A step the learner added earns no step XP, however often it is passed
+	if code, resp := call(t, h.AddStep, user.ID, http.MethodPost, "/", map[string]string{"title": "My own step"}, "id", id(project.ID)); code != http.StatusOK {
+		t.Fatalf("AddStep = %d %v", code, resp)
+	}
+	var added models.Step
+	h.DB.Where("project_id = ? AND step_number = 2", project.ID).First(&added)
+	options, _ := json.Marshal([]string{"right", "wrong"})
+	h.DB.Create(&models.Quiz{StepID: added.ID, Type: "multiple_choice", Question: "Mine?", Options: options})
+	attempt("2", "right")
+	if n := completions(); n != 1 {
+		t.Errorf("passing an added step gave step XP (%d awards)", n)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"github/meso1007/reverse-learn/backend/internal/activity"
 	"github/meso1007/reverse-learn/backend/internal/models"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -128,8 +129,11 @@ func (h *Handler) UpdateProfile(c echo.Context) error {
 	userID := c.Get("userID").(uint)
 
 	type UpdateProfileRequest struct {
-		Username     string `json:"username"`
-		ProfileImage string `json:"profile_image"`
+		Username             string `json:"username"`
+		ProfileImage         string `json:"profile_image"`
+		ReviewCorrectAnswers *bool  `json:"review_correct_answers"`
+		Timezone             string `json:"timezone"` // IANA time zone, e.g. Asia/Tokyo
+		HideFromLeaderboards *bool  `json:"hide_from_leaderboards"`
 	}
 
 	req := new(UpdateProfileRequest)
@@ -149,15 +153,30 @@ func (h *Handler) UpdateProfile(c echo.Context) error {
 	if req.ProfileImage != "" {
 		user.ProfileImage = req.ProfileImage
 	}
+	if req.ReviewCorrectAnswers != nil {
+		user.ReviewCorrectAnswers = *req.ReviewCorrectAnswers
+	}
+	if req.Timezone != "" {
+		if !activity.ValidTimezone(req.Timezone) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown time zone"})
+		}
+		user.Timezone = req.Timezone
+	}
+	if req.HideFromLeaderboards != nil {
+		user.HideFromLeaderboards = *req.HideFromLeaderboards
+	}
 
 	if err := h.DB.Save(&user).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update profile"})
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"id":            user.ID,
-		"email":         user.Email,
-		"username":      user.Username,
-		"profile_image": user.ProfileImage,
+		"id":                     user.ID,
+		"email":                  user.Email,
+		"username":               user.Username,
+		"profile_image":          user.ProfileImage,
+		"review_correct_answers": user.ReviewCorrectAnswers,
+		"timezone":               user.Timezone,
+		"hide_from_leaderboards": user.HideFromLeaderboards,
 	})
 }
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+
+	"github/meso1007/reverse-learn/backend/internal/badges"
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm/clause"
+)
+
+// badgeKeyPattern is the form of a badge key: lowercase words joined by underscores.
+var badgeKeyPattern = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
+// BadgeRequest is the body for creating or updating a badge. Omitted fields
+// are kept on update.
+type BadgeRequest struct {
+	Key           string  `json:"key"` // 作成時のみ
+	Name          *string `json:"name"`
+	NameJa        *string `json:"name_ja"`
+	Description   *string `json:"description"`
+	DescriptionJa *string `json:"description_ja"`
+	Metric        *string `json:"metric"`
+	Threshold     *int    `json:"threshold"`
+	Filter        *string `json:"filter"` // カンマ区切りのキーワード
+	Enabled       *bool   `json:"enabled"`
+}
+
+// apply copies the request's fields onto the badge and validates the result.
+func (r *BadgeRequest) apply(b *models.Badge) string {
+	if r.Name != nil {
+		b.Name = *r.Name
+	}
+	if r.NameJa != nil {
+		b.NameJa = *r.NameJa
+	}
+	if r.Description != nil {
+		b.Description = *r.Description
+	}
+	if r.DescriptionJa != nil {
+		b.DescriptionJa = *r.DescriptionJa
+	}
+	if r.Metric != nil {
+		b.Metric = *r.Metric
+	}
+	if r.Threshold != nil {
+		b.Threshold = *r.Threshold
+	}
+	if r.Filter != nil {
+		b.Filter = *r.Filter
+	}
+	if r.Enabled != nil {
+		b.Enabled = *r.Enabled
+	}
+
+	switch {
+	case b.Name == "" && b.NameJa == "":
+		return "Name is required"
+	case !badges.ValidMetric(b.Metric):
+		return "Unknown metric"
+	case b.Threshold < 1:
+		return "Threshold must be at least 1"
+	}
+	return ""
+}
+
+// GetMyBadges returns the badges the learner has earned and the ones still available.
+func (h *Handler) GetMyBadges(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+	earned, available, err := badges.List(h.DB, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch badges"})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"earned":    earned,
+		"available": available,
+	})
+}
+
+// GetBadges lists every badge definition.
+func (h *Handler) GetBadges(c echo.Context) error {
+	defs, err := badges.Definitions(h.DB)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch badges"})
+	}
+	return c.JSON(http.StatusOK, defs)
+}
+
+// CreateBadge adds a badge definition. Learners who already meet it are
+// awarded it with their next activity.
+func (h *Handler) CreateBadge(c echo.Context) error {
+	req := new(BadgeRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	if !badgeKeyPattern.MatchString(req.Key) || len(req.Key) > 50 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Key must be lowercase letters, digits and underscores"})
+	}
+
+	defs, err := badges.Definitions(h.DB)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch badges"})
+	}
+	for _, b := range defs {
+		if b.Key == req.Key {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "A badge with this key already exists"})
+		}
+	}
+
+	badge := models.Badge{Key: req.Key, Enabled: true}
+	if msg := req.apply(&badge); msg != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+	}
+	if err := h.DB.Create(&badge).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create badge"})
+	}
+	return c.JSON(http.StatusCreated, badge)
+}
+
+// UpdateBadge changes a badge definition, including the defaults.
+// Badges already awarded are kept.
+func (h *Handler) UpdateBadge(c echo.Context) error {
+	key := c.Param("key")
+
+	defs, err := badges.Definitions(h.DB)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch badges"})
+	}
+	var badge models.Badge
+	for _, b := range defs {
+		if b.Key == key {
+			badge = b
+		}
+	}
+	if badge.Key == "" {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Badge not found"})
+	}
+
+	req := new(BadgeRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	if msg := req.apply(&badge); msg != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+	}
+
+	// Upsert with every column, so that a default badge gets stored and zero values are saved too
+	if err := h.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&badge).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update badge"})
+	}
+	return c.JSON(http.StatusOK, badge)
+}
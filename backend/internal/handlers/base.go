@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"github/meso1007/reverse-learn/backend/internal/certificate"
 	"github/meso1007/reverse-learn/backend/internal/payment"
+	"github/meso1007/reverse-learn/backend/internal/tutor"
 
 	"gorm.io/gorm"
 )
@@ -11,13 +13,17 @@ type Handler struct {
 	JobQueue       chan uint
 	JWTSecret      []byte
 	PaymentService *payment.Service
+	Tutor          *tutor.Tutor
+	Certificates   *certificate.Signer
 }
 
-func NewHandler(db *gorm.DB, jobQueue chan uint, secret string, paymentService *payment.Service) *Handler {
+func NewHandler(db *gorm.DB, jobQueue chan uint, secret string, paymentService *payment.Service, tutor *tutor.Tutor, certificates *certificate.Signer) *Handler {
 	return &Handler{
 		DB:             db,
 		JobQueue:       jobQueue,
 		JWTSecret:      []byte(secret),
 		PaymentService: paymentService,
+		Tutor:          tutor,
+		Certificates:   certificates,
 	}
 }
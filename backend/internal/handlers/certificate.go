@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/certificate"
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxCertificatePDFSize limits the size of a PDF sent for verification.
+const maxCertificatePDFSize = 5 << 20
+
+// certificateResponse is a certificate with its scores and whether it is
+// still valid.
+type certificateResponse struct {
+	models.Certificate
+	Scores    []certificate.StepScore `json:"scores"`
+	Valid     bool                    `json:"valid"` // signed by us and not revoked
+	Revoked   bool                    `json:"revoked"`
+	PublicKey string                  `json:"public_key"`
+}
+
+func (h *Handler) certificateResponse(cert models.Certificate) certificateResponse {
+	return certificateResponse{
+		Certificate: cert,
+		Scores:      certificate.Scores(cert),
+		Valid:       cert.RevokedAt == nil && h.Certificates.Verify(cert),
+		Revoked:     cert.RevokedAt != nil,
+		PublicKey:   h.Certificates.PublicKey(),
+	}
+}
+
+// IssueCertificate returns the project's certificate, issuing it if every
+// step has a passing best score.
+func (h *Handler) IssueCertificate(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	cert, created, err := certificate.Issue(h.DB, h.Certificates, project)
+	var incomplete *certificate.IncompleteError
+	if errors.As(err, &incomplete) {
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"error":           "Every step needs a passing score",
+			"pass_percentage": incomplete.PassPercentage,
+			"steps":           incomplete.Steps,
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to issue certificate"})
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	return c.JSON(status, h.certificateResponse(*cert))
+}
+
+// GetCertificates lists the user's certificates, newest first.
+func (h *Handler) GetCertificates(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	var certs []models.Certificate
+	if err := h.DB.Omit("PDF").Where("user_id = ?", userID).Order("issued_at desc").Find(&certs).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch certificates"})
+	}
+
+	resp := make([]certificateResponse, 0, len(certs))
+	for _, cert := range certs {
+		resp = append(resp, h.certificateResponse(cert))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// VerifyCertificate publicly shows a certificate's details and whether it is
+// valid, so that anyone given the code can check it.
+func (h *Handler) VerifyCertificate(c echo.Context) error {
+	var cert models.Certificate
+	if err := h.DB.Omit("PDF").Where("code = ?", c.Param("code")).First(&cert).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Certificate not found"})
+	}
+	// The internal IDs are not part of the signed details and not for the public
+	cert.UserID, cert.ProjectID = 0, 0
+	return c.JSON(http.StatusOK, h.certificateResponse(cert))
+}
+
+// GetCertificatePDF returns the PDF rendered when the certificate was issued.
+func (h *Handler) GetCertificatePDF(c echo.Context) error {
+	var cert models.Certificate
+	if err := h.DB.Where("code = ?", c.Param("code")).First(&cert).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Certificate not found"})
+	}
+	if cert.RevokedAt != nil {
+		return c.JSON(http.StatusGone, map[string]string{"error": "Certificate has been revoked"})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="certificate-`+cert.Code+`.pdf"`)
+	return c.Blob(http.StatusOK, "application/pdf", cert.PDF)
+}
+
+// CheckCertificatePDF tells whether a PDF (sent as the request body) is the
+// unaltered PDF of the certificate.
+func (h *Handler) CheckCertificatePDF(c echo.Context) error {
+	var cert models.Certificate
+	if err := h.DB.Omit("PDF").Where("code = ?", c.Param("code")).First(&cert).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Certificate not found"})
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxCertificatePDFSize+1))
+	if err != nil || len(body) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "PDF is required"})
+	}
+	if len(body) > maxCertificatePDFSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "PDF is too large"})
+	}
+
+	sum := sha256.Sum256(body)
+	matches := hex.EncodeToString(sum[:]) == cert.PDFHash
+	resp := h.certificateResponse(cert)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"code":       cert.Code,
+		"unaltered":  matches,
+		"valid":      matches && resp.Valid,
+		"revoked":    resp.Revoked,
+		"checked_at": time.Now(),
+	})
+}
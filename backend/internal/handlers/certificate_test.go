@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+)
+
+func TestCertificateIssuedOnce(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.DB, "learner@example.com")
+	project := createProject(t, h.DB, user.ID, 2)
+	for _, s := range project.Steps {
+		h.DB.Create(&models.Score{StepID: s.ID, Score: 2, Total: 2, Percentage: 100, BestPercentage: 100, AttemptCount: 1})
+	}
+
+	code, issued := call(t, h.IssueCertificate, user.ID, http.MethodPost, "/", nil, "id", id(project.ID))
+	if code != http.StatusCreated || issued["valid"] != true {
+		t.Fatalf("IssueCertificate = %d %v", code, issued)
+	}
+	code, again := call(t, h.IssueCertificate, user.ID, http.MethodPost, "/", nil, "id", id(project.ID))
+	if code != http.StatusOK || again["code"] != issued["code"] {
+		t.Errorf("second IssueCertificate = %d %v, want the same certificate", code, again)
+	}
+
+	// Concurrent issues cannot both store a certificate
+	duplicate := models.Certificate{Code: "DUPLICATE", UserID: user.ID, ProjectID: project.ID}
+	if err := h.DB.Create(&duplicate).Error; err == nil {
+		t.Error("a second certificate for the project was stored")
+	}
+
+	code, public := call(t, h.VerifyCertificate, 0, http.MethodGet, "/", nil, "code", issued["code"].(string))
+	if code != http.StatusOK || public["valid"] != true {
+		t.Fatalf("VerifyCertificate = %d %v", code, public)
+	}
+	for _, key := range []string{"user_id", "project_id"} {
+		if _, ok := public[key]; ok {
+			t.Errorf("the public certificate shows %s", key)
+		}
+	}
+}
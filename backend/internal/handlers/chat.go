@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github/meso1007/reverse-learn/backend/internal/gating"
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/quota"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// maxChatMessageLength limits the length of a learner's chat message.
+const maxChatMessageLength = 2000
+
+// conversationTitleLength is the length of the title taken from the first message.
+const conversationTitleLength = 50
+
+// writeEvent sends one server-sent event with a JSON payload.
+func writeEvent(c echo.Context, event string, data interface{}) error {
+	payload, _ := json.Marshal(data)
+	if _, err := fmt.Fprintf(c.Response(), "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	c.Response().Flush()
+	return nil
+}
+
+// Chat answers a learner's question about a step, streaming the tutor's
+// answer as server-sent events:
+//
+//	conversation {"conversation_id", "message_id"}  the stored question
+//	delta        {"text"}                           a piece of the answer
+//	done         {"message_id", "quota"}            the stored answer
+//	error        {"error"}                          the answer failed; the question is discarded
+//
+// Without a conversation_id a new conversation is started. Each answer
+// counts against the plan's daily chat quota.
+func (h *Handler) Chat(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	type ChatRequest struct {
+		ConversationID *uint  `json:"conversation_id"`
+		Message        string `json:"message"`
+	}
+	req := new(ChatRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Message == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Message is required"})
+	}
+	if utf8.RuneCountInString(req.Message) > maxChatMessageLength {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Message must be at most %d characters", maxChatMessageLength)})
+	}
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	step, err := h.findProjectStep(project.ID, c.Param("stepNumber"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Step not found"})
+	}
+
+	lock, err := gating.StepLock(h.DB, project, step.StepNumber)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check step lock"})
+	}
+	if lock != nil {
+		return respondStepLocked(c, lock)
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+	usage, err := quota.Get(h.DB, user, quota.MetricChatMessages)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check chat quota"})
+	}
+	if usage.Exceeded() {
+		return c.JSON(http.StatusTooManyRequests, map[string]interface{}{
+			"error": "Chat quota exceeded",
+			"quota": usage,
+		})
+	}
+
+	var conversation models.Conversation
+	var history []models.Message
+	if req.ConversationID != nil {
+		if err := h.DB.Where("id = ? AND user_id = ? AND step_id = ?", *req.ConversationID, userID, step.ID).First(&conversation).Error; err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Conversation not found"})
+		}
+		h.DB.Where("conversation_id = ?", conversation.ID).Order("created_at, id").Find(&history)
+	} else {
+		title := req.Message
+		if utf8.RuneCountInString(title) > conversationTitleLength {
+			title = string([]rune(title)[:conversationTitleLength]) + "…"
+		}
+		conversation = models.Conversation{
+			UserID:    userID,
+			ProjectID: project.ID,
+			StepID:    step.ID,
+			Title:     title,
+		}
+		if err := h.DB.Create(&conversation).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to start conversation"})
+		}
+	}
+
+	question := models.Message{
+		ConversationID: conversation.ID,
+		Role:           "user",
+		Content:        req.Message,
+		CreatedAt:      time.Now(),
+	}
+	if err := h.DB.Create(&question).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save message"})
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.WriteHeader(http.StatusOK)
+
+	writeEvent(c, "conversation", map[string]interface{}{
+		"conversation_id": conversation.ID,
+		"message_id":      question.ID,
+	})
+
+	answer, err := h.Tutor.Stream(c.Request().Context(), project, step, history, req.Message, func(chunk string) error {
+		return writeEvent(c, "delta", map[string]string{"text": chunk})
+	})
+	if err != nil {
+		log.Printf("Chat: Failed to answer in conversation %d: %v", conversation.ID, err)
+		// Unanswered questions are not kept, so they can be asked again
+		h.DB.Delete(&question)
+		if req.ConversationID == nil {
+			h.DB.Delete(&conversation)
+		}
+		writeEvent(c, "error", map[string]string{"error": "Failed to generate answer"})
+		return nil
+	}
+
+	reply := models.Message{
+		ConversationID: conversation.ID,
+		Role:           "assistant",
+		Content:        answer,
+		CreatedAt:      time.Now(),
+	}
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&reply).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&conversation).Update("updated_at", reply.CreatedAt).Error; err != nil {
+			return err
+		}
+		return quota.Record(tx, userID, quota.MetricChatMessages)
+	})
+	if err != nil {
+		writeEvent(c, "error", map[string]string{"error": "Failed to save answer"})
+		return nil
+	}
+
+	usage.Used++
+	writeEvent(c, "done", map[string]interface{}{
+		"message_id": reply.ID,
+		"quota":      usage,
+	})
+	return nil
+}
+
+// GetConversations lists the user's conversations about a step, most recent
+// first, with today's chat quota.
+func (h *Handler) GetConversations(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	step, err := h.findProjectStep(project.ID, c.Param("stepNumber"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Step not found"})
+	}
+
+	var conversations []models.Conversation
+	if err := h.DB.Where("user_id = ? AND step_id = ?", userID, step.ID).Order("updated_at desc").Find(&conversations).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch conversations"})
+	}
+
+	var user models.User
+	h.DB.First(&user, userID)
+	usage, err := quota.Get(h.DB, user, quota.MetricChatMessages)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check chat quota"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"conversations": conversations,
+		"quota":         usage,
+	})
+}
+
+// GetConversation returns a conversation with its messages in order.
+func (h *Handler) GetConversation(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	var conversation models.Conversation
+	err := h.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).
+		Preload("Messages", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") }).
+		First(&conversation).Error
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Conversation not found"})
+	}
+
+	return c.JSON(http.StatusOK, conversation)
+}
+
+// DeleteConversation deletes a conversation and its messages. Answers
+// already given still count against the day's quota.
+func (h *Handler) DeleteConversation(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	var conversation models.Conversation
+	if err := h.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&conversation).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Conversation not found"})
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("conversation_id = ?", conversation.ID).Delete(&models.Message{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&conversation).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete conversation"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Conversation deleted successfully"})
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"net/http"
+	"strings"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// newCohortCode returns a random code for joining a cohort.
+func newCohortCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.EncodeToString(b), nil
+}
+
+// isCohortMember reports whether the user belongs to the cohort.
+func (h *Handler) isCohortMember(cohortID, userID uint) bool {
+	var n int64
+	h.DB.Model(&models.CohortMember{}).Where("cohort_id = ? AND user_id = ?", cohortID, userID).Count(&n)
+	return n > 0
+}
+
+// cohortResponse is a cohort as listed to its members.
+type cohortResponse struct {
+	models.Cohort
+	Members int64 `json:"members"`
+	IsOwner bool  `json:"is_owner"`
+}
+
+// GetCohorts lists the cohorts the learner belongs to.
+func (h *Handler) GetCohorts(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	var cohorts []models.Cohort
+	err := h.DB.Where("id IN (?)", h.DB.Model(&models.CohortMember{}).Select("cohort_id").Where("user_id = ?", userID)).
+		Order("name").Find(&cohorts).Error
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch cohorts"})
+	}
+
+	resp := make([]cohortResponse, 0, len(cohorts))
+	for _, cohort := range cohorts {
+		r := cohortResponse{Cohort: cohort, IsOwner: cohort.OwnerID == userID}
+		h.DB.Model(&models.CohortMember{}).Where("cohort_id = ?", cohort.ID).Count(&r.Members)
+		resp = append(resp, r)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// CreateCohort creates a cohort with the learner as its first member.
+func (h *Handler) CreateCohort(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	type CreateCohortRequest struct {
+		Name string `json:"name"`
+	}
+	req := new(CreateCohortRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len([]rune(name)) > 100 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Name must be 1 to 100 characters"})
+	}
+
+	code, err := newCohortCode()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create cohort"})
+	}
+	cohort := models.Cohort{Name: name, Code: code, OwnerID: userID}
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&cohort).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.CohortMember{CohortID: cohort.ID, UserID: userID, JoinedAt: time.Now()}).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create cohort"})
+	}
+	return c.JSON(http.StatusCreated, cohortResponse{Cohort: cohort, Members: 1, IsOwner: true})
+}
+
+// JoinCohort adds the learner to the cohort with the code.
+func (h *Handler) JoinCohort(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	type JoinCohortRequest struct {
+		Code string `json:"code"`
+	}
+	req := new(JoinCohortRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	var cohort models.Cohort
+	if err := h.DB.Where("code = ?", strings.ToUpper(strings.TrimSpace(req.Code))).First(&cohort).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Cohort not found"})
+	}
+	if !h.isCohortMember(cohort.ID, userID) {
+		if err := h.DB.Create(&models.CohortMember{CohortID: cohort.ID, UserID: userID, JoinedAt: time.Now()}).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to join cohort"})
+		}
+	}
+
+	r := cohortResponse{Cohort: cohort, IsOwner: cohort.OwnerID == userID}
+	h.DB.Model(&models.CohortMember{}).Where("cohort_id = ?", cohort.ID).Count(&r.Members)
+	return c.JSON(http.StatusOK, r)
+}
+
+// LeaveCohort removes the learner from the cohort. A cohort is deleted when
+// its last member leaves.
+func (h *Handler) LeaveCohort(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	result := h.DB.Where("cohort_id = ? AND user_id = ?", c.Param("id"), userID).Delete(&models.CohortMember{})
+	if result.Error != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to leave cohort"})
+	}
+	if result.RowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Cohort not found"})
+	}
+
+	var remaining int64
+	h.DB.Model(&models.CohortMember{}).Where("cohort_id = ?", c.Param("id")).Count(&remaining)
+	if remaining == 0 {
+		h.DB.Delete(&models.Cohort{}, c.Param("id"))
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Left cohort successfully"})
+}
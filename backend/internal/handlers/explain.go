@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/questions"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ExplainMistake explains why the learner's wrong answer to a question is
+// wrong. Explanations are shared by everyone who gives the same response, so
+// a cached one is returned right away; otherwise a job is queued to write it.
+func (h *Handler) ExplainMistake(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	attempt, err := h.findUserAttempt(userID, c.Param("attemptId"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Attempt not found"})
+	}
+
+	answer := findAttemptAnswer(&attempt, c.Param("quizId"))
+	if answer == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Question is not part of this attempt"})
+	}
+	if answer.AnsweredAt == nil || answer.IsCorrect {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Only wrong answers can be explained"})
+	}
+	if answer.GradingStatus != "" {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Free responses get feedback when graded"})
+	}
+
+	var quiz models.Quiz
+	if err := h.DB.First(&quiz, answer.QuizID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Question not found"})
+	}
+	var step models.Step
+	h.DB.First(&step, quiz.StepID)
+	var project models.Project
+	h.DB.First(&project, step.ProjectID)
+
+	var cached models.MistakeExplanation
+	h.DB.Where("quiz_id = ? AND response_key = ? AND locale = ?", quiz.ID, questions.ResponseKey(quiz, questions.ResponseOf(*answer)), project.Locale).
+		Limit(1).Find(&cached)
+	if cached.ID != 0 {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"quiz_id":     quiz.ID,
+			"explanation": cached.Explanation,
+			"concept":     cached.Concept,
+		})
+	}
+
+	// Asking again while the explanation is being written returns the same job
+	var pending []models.Job
+	h.DB.Where("user_id = ? AND type = ? AND status IN ?", userID, "explain_mistake", []string{"pending", "processing"}).Find(&pending)
+	for _, j := range pending {
+		var r models.ExplainMistakeRequest
+		json.Unmarshal(j.Input, &r)
+		if r.AttemptAnswerID == answer.ID {
+			return c.JSON(http.StatusAccepted, map[string]interface{}{
+				"job_id": j.ID,
+				"status": j.Status,
+			})
+		}
+	}
+
+	inputBytes, _ := json.Marshal(models.ExplainMistakeRequest{AttemptAnswerID: answer.ID})
+	job := models.Job{
+		UserID: userID,
+		Type:   "explain_mistake",
+		Input:  inputBytes,
+	}
+	return h.enqueueJob(c, &job)
+}
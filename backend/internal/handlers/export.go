@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+type exportNote struct {
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type exportStep struct {
+	roadmapStep
+	Notes []exportNote `json:"notes"`
+}
+
+type projectExport struct {
+	Goal         string       `json:"goal"`
+	Stack        string       `json:"stack"`
+	Level        string       `json:"level"`
+	Locale       string       `json:"locale"`
+	CreatedAt    time.Time    `json:"created_at"`
+	ExportedAt   time.Time    `json:"exported_at"`
+	StudySeconds int          `json:"study_seconds"`
+	Steps        []exportStep `json:"steps"`
+}
+
+// ExportProject downloads a project with its roadmap, scores and the
+// learner's notes. The format query parameter is json (default) or markdown.
+func (h *Handler) ExportProject(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "markdown" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Format must be json or markdown"})
+	}
+
+	var project models.Project
+	if err := h.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).Preload("Steps", orderedSteps).First(&project).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	var projectNotes []models.Note
+	if err := h.DB.Where("project_id = ? AND user_id = ?", project.ID, userID).Order("created_at").Find(&projectNotes).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to export project"})
+	}
+	notesByStep := make(map[uint][]exportNote)
+	for _, n := range projectNotes {
+		notesByStep[n.StepID] = append(notesByStep[n.StepID], exportNote{Body: n.Body, CreatedAt: n.CreatedAt, UpdatedAt: n.UpdatedAt})
+	}
+
+	roadmap := h.roadmapSteps(project)
+	export := projectExport{
+		Goal:         project.Goal,
+		Stack:        project.Stack,
+		Level:        project.Level,
+		Locale:       project.Locale,
+		CreatedAt:    project.CreatedAt,
+		ExportedAt:   time.Now(),
+		StudySeconds: totalStudySeconds(roadmap),
+		Steps:        make([]exportStep, len(roadmap)),
+	}
+	for i, s := range roadmap {
+		stepNotes := notesByStep[project.Steps[i].ID]
+		if stepNotes == nil {
+			stepNotes = []exportNote{}
+		}
+		export.Steps[i] = exportStep{roadmapStep: s, Notes: stepNotes}
+	}
+
+	filename := fmt.Sprintf("project-%d", project.ID)
+	if format == "markdown" {
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`.md"`)
+		return c.Blob(http.StatusOK, "text/markdown; charset=utf-8", []byte(export.markdown()))
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`.json"`)
+	return c.JSON(http.StatusOK, export)
+}
+
+// markdown renders the export as a Markdown document.
+func (e projectExport) markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", e.Goal)
+	fmt.Fprintf(&b, "- Stack: %s\n- Level: %s\n- Created: %s\n- Study time: %d min\n",
+		e.Stack, e.Level, e.CreatedAt.Format("2006-01-02"), e.StudySeconds/60)
+
+	for _, s := range e.Steps {
+		fmt.Fprintf(&b, "\n## Step %d: %s\n\n", s.Step, s.Title)
+		if s.Description != "" {
+			fmt.Fprintf(&b, "%s\n\n", s.Description)
+		}
+		if s.Score != nil {
+			fmt.Fprintf(&b, "Score: %d/%d (%d%%), best %d%% over %d attempts\n",
+				s.Score.Score, s.Score.Total, s.Score.Percentage, s.Score.BestPercentage, s.Score.AttemptCount)
+		} else {
+			b.WriteString("Score: not attempted\n")
+		}
+		if len(s.Notes) > 0 {
+			b.WriteString("\n### Notes\n")
+			for _, n := range s.Notes {
+				fmt.Fprintf(&b, "\n_%s_\n\n%s\n", n.UpdatedAt.Format("2006-01-02 15:04"), n.Body)
+			}
+		}
+	}
+	return b.String()
+}
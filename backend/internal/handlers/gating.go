@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github/meso1007/reverse-learn/backend/internal/gating"
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// projectGating is the gating section of project responses.
+func projectGating(project models.Project) map[string]interface{} {
+	mode := project.GatingMode
+	if mode == "" {
+		mode = gating.ModeOff
+	}
+	resp := map[string]interface{}{
+		"mode":            mode,
+		"pass_percentage": project.PassPercentage,
+	}
+	if project.ImposedGatingMode != "" && project.ImposedGatingMode != gating.ModeOff {
+		resp["imposed"] = map[string]interface{}{
+			"mode":            project.ImposedGatingMode,
+			"pass_percentage": project.ImposedPassPercentage,
+		}
+	}
+	return resp
+}
+
+// imposedGating returns the gating an admin or cohort owner set on the
+// project, as a project for gating.Loosens.
+func imposedGating(project models.Project) models.Project {
+	return models.Project{GatingMode: project.ImposedGatingMode, PassPercentage: project.ImposedPassPercentage}
+}
+
+// respondStepLocked refuses access to a locked step and tells the learner how to unlock it.
+func respondStepLocked(c echo.Context, lock *gating.Lock) error {
+	return c.JSON(http.StatusForbidden, map[string]interface{}{
+		"error":  "Step is locked",
+		"unlock": lock,
+	})
+}
+
+// canImposeGating reports whether the user may set gating on another
+// learner's project: an admin, or the owner of a cohort the learner belongs
+// to.
+func (h *Handler) canImposeGating(userID uint, project models.Project) bool {
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		return false
+	}
+	if user.IsAdmin {
+		return true
+	}
+	if userID == project.UserID {
+		return false
+	}
+	var n int64
+	h.DB.Model(&models.CohortMember{}).
+		Joins("JOIN cohorts ON cohorts.id = cohort_members.cohort_id").
+		Where("cohorts.owner_id = ? AND cohort_members.user_id = ?", userID, project.UserID).
+		Count(&n)
+	return n > 0
+}
+
+// UpdateGating changes when the steps of a project unlock. The owner can
+// set gating on their own project; admins and the owners of the learner's
+// cohorts can impose it, and the owner then cannot loosen it below what was
+// imposed.
+func (h *Handler) UpdateGating(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	type GatingRequest struct {
+		Mode           string `json:"mode"`            // off, sequential, pass
+		PassPercentage *int   `json:"pass_percentage"` // 1-100, used in pass mode
+	}
+	req := new(GatingRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	var project models.Project
+	if err := h.DB.First(&project, c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	owner := project.UserID == userID
+	imposer := h.canImposeGating(userID, project)
+	if !owner && !imposer {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	if req.Mode != "" {
+		if !gating.ValidMode(req.Mode) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Mode must be one of off, sequential, pass"})
+		}
+		project.GatingMode = req.Mode
+	}
+	if req.PassPercentage != nil {
+		if *req.PassPercentage < 1 || *req.PassPercentage > 100 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Pass percentage must be between 1 and 100"})
+		}
+		project.PassPercentage = *req.PassPercentage
+	}
+	if project.PassPercentage == 0 {
+		project.PassPercentage = gating.DefaultPassPercentage
+	}
+
+	updates := map[string]interface{}{
+		"gating_mode":     project.GatingMode,
+		"pass_percentage": project.PassPercentage,
+	}
+	if owner && !imposer {
+		if gating.Loosens(imposedGating(project), project) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "Gating was set by your cohort and can only be made stricter"})
+		}
+	} else if !owner {
+		// What an admin or cohort owner sets becomes the learner's minimum
+		project.ImposedGatingMode, project.ImposedPassPercentage = "", 0
+		if project.GatingMode != gating.ModeOff {
+			project.ImposedGatingMode, project.ImposedPassPercentage = project.GatingMode, project.PassPercentage
+		}
+		updates["imposed_gating_mode"] = project.ImposedGatingMode
+		updates["imposed_pass_percentage"] = project.ImposedPassPercentage
+	}
+
+	if err := h.DB.Model(&project).Updates(updates).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update gating"})
+	}
+
+	return c.JSON(http.StatusOK, projectGating(project))
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github/meso1007/reverse-learn/backend/internal/gating"
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// gatedProject creates a project of three steps in sequential mode, where
+// only step 1 is open.
+func gatedProject(t *testing.T, db *gorm.DB, userID uint) models.Project {
+	t.Helper()
+	project := createProject(t, db, userID, 3)
+	if err := db.Model(&project).Update("gating_mode", "sequential").Error; err != nil {
+		t.Fatal(err)
+	}
+	project.GatingMode = "sequential"
+	return project
+}
+
+// descriptions returns the description of each step of a roadmap response.
+func descriptions(steps interface{}) []string {
+	var result []string
+	for _, s := range steps.([]interface{}) {
+		result = append(result, s.(map[string]interface{})["description"].(string))
+	}
+	return result
+}
+
+func TestLockedStepsAreRedacted(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.DB, "learner@example.com")
+	project := gatedProject(t, h.DB, user.ID)
+
+	code, edited := call(t, h.UpdateStep, user.ID, http.MethodPut, "/", map[string]string{"title": "Basics"}, "id", id(project.ID), "stepNumber", "1")
+	if code != http.StatusOK {
+		t.Fatalf("UpdateStep = %d %v", code, edited)
+	}
+	if got := descriptions(edited["roadmap"]); got[0] != "Description 1" || got[1] != "" || got[2] != "" {
+		t.Errorf("edited roadmap descriptions = %q, want only step 1", got)
+	}
+
+	for _, number := range []string{"1", "2"} {
+		code, rev := call(t, h.GetRevision, user.ID, http.MethodGet, "/", nil, "id", id(project.ID), "number", number)
+		if code != http.StatusOK {
+			t.Fatalf("GetRevision %s = %d %v", number, code, rev)
+		}
+		if got := descriptions(rev["steps"]); got[1] != "" || got[2] != "" {
+			t.Errorf("revision %s descriptions = %q, want locked steps redacted", number, got)
+		}
+	}
+
+	// Editing a locked step records a revision whose diff stays redacted
+	if code, resp := call(t, h.UpdateStep, user.ID, http.MethodPut, "/", map[string]string{"description": "Rewritten"}, "id", id(project.ID), "stepNumber", "3"); code != http.StatusOK {
+		t.Fatalf("UpdateStep = %d %v", code, resp)
+	}
+	code, diff := call(t, h.DiffRevisions, user.ID, http.MethodGet, "/", nil, "id", id(project.ID))
+	if code != http.StatusOK {
+		t.Fatalf("DiffRevisions = %d %v", code, diff)
+	}
+	changes := diff["changes"].([]interface{})
+	if len(changes) != 1 {
+		t.Fatalf("diff changes = %v, want the edit of step 3", changes)
+	}
+	for _, change := range changes {
+		change := change.(map[string]interface{})
+		for _, side := range []string{"from", "to"} {
+			if s, ok := change[side].(map[string]interface{}); ok && s["description"] != "" {
+				t.Errorf("diff %s reveals %q", side, s["description"])
+			}
+		}
+	}
+
+	code, resp := call(t, h.RegenerateStep, user.ID, http.MethodPost, "/", map[string]string{}, "id", id(project.ID), "stepNumber", "2")
+	if code != http.StatusForbidden {
+		t.Errorf("RegenerateStep of a locked step = %d %v, want 403", code, resp)
+	}
+}
+
+func TestRoadmapEditsKeepStepsLocked(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(h *Handler, userID uint, projectID string) int
+		want int
+	}{
+		{"move a locked step ahead", func(h *Handler, userID uint, projectID string) int {
+			code, _ := call(t, h.ReorderSteps, userID, http.MethodPut, "/", map[string][]int{"order": {1, 3, 2}}, "id", projectID)
+			return code
+		}, http.StatusForbidden},
+		{"move the open step first", func(h *Handler, userID uint, projectID string) int {
+			code, _ := call(t, h.ReorderSteps, userID, http.MethodPut, "/", map[string][]int{"order": {2, 1, 3}}, "id", projectID)
+			return code
+		}, http.StatusOK},
+		{"delete the step that unlocks the next", func(h *Handler, userID uint, projectID string) int {
+			code, _ := call(t, h.DeleteStep, userID, http.MethodDelete, "/", nil, "id", projectID, "stepNumber", "2")
+			return code
+		}, http.StatusForbidden},
+		{"delete a locked step", func(h *Handler, userID uint, projectID string) int {
+			code, _ := call(t, h.DeleteStep, userID, http.MethodDelete, "/", nil, "id", projectID, "stepNumber", "3")
+			return code
+		}, http.StatusForbidden},
+		{"delete a passed step", func(h *Handler, userID uint, projectID string) int {
+			code, _ := call(t, h.DeleteStep, userID, http.MethodDelete, "/", nil, "id", projectID, "stepNumber", "1")
+			return code
+		}, http.StatusOK},
+		{"revert away a locked step", func(h *Handler, userID uint, projectID string) int {
+			if code, resp := call(t, h.AddStep, userID, http.MethodPost, "/", map[string]string{"title": "Extra"}, "id", projectID); code != http.StatusOK {
+				t.Fatalf("AddStep = %d %v", code, resp)
+			}
+			code, _ := call(t, h.RevertRevision, userID, http.MethodPost, "/", nil, "id", projectID, "number", "1")
+			return code
+		}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			user := createUser(t, h.DB, "learner@example.com")
+			project := gatedProject(t, h.DB, user.ID)
+			// Step 1 is completed, so step 2 is open and step 3 locked
+			h.DB.Create(&models.Score{StepID: project.Steps[0].ID, Score: 2, Total: 2, Percentage: 100, BestPercentage: 100, AttemptCount: 1})
+
+			if got := tt.edit(h, user.ID, id(project.ID)); got != tt.want {
+				t.Fatalf("edit = %d, want %d", got, tt.want)
+			}
+			if tt.want == http.StatusForbidden {
+				var steps []models.Step
+				h.DB.Where("project_id = ?", project.ID).Order("step_number").Find(&steps)
+				if len(steps) < 3 || steps[0].ID != project.Steps[0].ID || steps[1].ID != project.Steps[1].ID || steps[2].ID != project.Steps[2].ID {
+					t.Errorf("a refused edit changed the roadmap: %+v", steps)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateGating(t *testing.T) {
+	h := newTestHandler(t)
+	learner := createUser(t, h.DB, "learner@example.com")
+	teacher := createUser(t, h.DB, "teacher@example.com")
+	stranger := createUser(t, h.DB, "stranger@example.com")
+	project := createProject(t, h.DB, learner.ID, 2)
+	cohort := models.Cohort{Name: "Go", Code: "GO123", OwnerID: teacher.ID}
+	h.DB.Create(&cohort)
+	h.DB.Create(&models.CohortMember{CohortID: cohort.ID, UserID: learner.ID})
+
+	update := func(userID uint, mode string, pass int) (int, map[string]interface{}) {
+		return call(t, h.UpdateGating, userID, http.MethodPut, "/", map[string]interface{}{"mode": mode, "pass_percentage": pass}, "id", id(project.ID))
+	}
+
+	if code, _ := update(stranger.ID, gating.ModePass, 50); code != http.StatusNotFound {
+		t.Errorf("UpdateGating by a stranger = %d, want 404", code)
+	}
+	// The owner sets their own threshold, and can loosen it again
+	if code, resp := update(learner.ID, gating.ModePass, 90); code != http.StatusOK || resp["pass_percentage"] != float64(90) {
+		t.Fatalf("UpdateGating by the owner = %d %v", code, resp)
+	}
+	if code, resp := update(learner.ID, gating.ModeOff, 90); code != http.StatusOK || resp["imposed"] != nil {
+		t.Fatalf("UpdateGating by the owner = %d %v", code, resp)
+	}
+
+	code, resp := update(teacher.ID, gating.ModePass, 80)
+	if code != http.StatusOK || resp["imposed"] == nil {
+		t.Fatalf("UpdateGating by the cohort owner = %d %v, want it imposed", code, resp)
+	}
+	for _, tt := range []struct {
+		mode string
+		pass int
+		want int
+	}{
+		{gating.ModeSequential, 80, http.StatusForbidden},
+		{gating.ModePass, 60, http.StatusForbidden},
+		{gating.ModePass, 95, http.StatusOK},
+		{gating.ModePass, 80, http.StatusOK}, // back down to what was imposed
+	} {
+		if code, resp := update(learner.ID, tt.mode, tt.pass); code != tt.want {
+			t.Errorf("owner sets %s %d = %d %v, want %d", tt.mode, tt.pass, code, resp, tt.want)
+		}
+	}
+
+	// Once the cohort owner turns gating off, the learner is free again
+	if code, resp := update(teacher.ID, gating.ModeOff, 80); code != http.StatusOK || resp["imposed"] != nil {
+		t.Fatalf("UpdateGating by the cohort owner = %d %v", code, resp)
+	}
+	if code, resp := update(learner.ID, gating.ModeSequential, 80); code != http.StatusOK {
+		t.Errorf("UpdateGating by the owner = %d %v", code, resp)
+	}
+}
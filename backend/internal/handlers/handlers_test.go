@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github/meso1007/reverse-learn/backend/internal/certificate"
+	"github/meso1007/reverse-learn/backend/internal/database"
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testSigningKey is a base64 Ed25519 seed for the tests' certificates.
+const testSigningKey = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+
+// newTestHandler returns a handler on a fresh in-memory database. Jobs are
+// queued on a buffered channel that nothing consumes.
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := certificate.NewSigner(testSigningKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewHandler(db, make(chan uint, 100), "test-secret", nil, nil, signer)
+}
+
+// serve runs the handler as the user (0 for a public route) with the JSON
+// body, path parameters given as name/value pairs and the target's query.
+func serve(t *testing.T, handler echo.HandlerFunc, userID uint, method, target string, body interface{}, params ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader *strings.Reader
+	if body == nil {
+		reader = strings.NewReader("")
+	} else {
+		b, _ := json.Marshal(body)
+		reader = strings.NewReader(string(b))
+	}
+	req := httptest.NewRequest(method, target, reader)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	if userID != 0 {
+		c.Set("userID", userID)
+	}
+	var names, values []string
+	for i := 0; i+1 < len(params); i += 2 {
+		names = append(names, params[i])
+		values = append(values, params[i+1])
+	}
+	c.SetParamNames(names...)
+	c.SetParamValues(values...)
+	if err := handler(c); err != nil {
+		t.Fatalf("%s %s: %v", method, target, err)
+	}
+	return rec
+}
+
+// call serves a request whose response is a JSON object.
+func call(t *testing.T, handler echo.HandlerFunc, userID uint, method, target string, body interface{}, params ...string) (int, map[string]interface{}) {
+	t.Helper()
+	rec := serve(t, handler, userID, method, target, body, params...)
+	resp := map[string]interface{}{}
+	decode(t, rec, &resp)
+	return rec.Code, resp
+}
+
+// callList serves a request whose response is a JSON array of objects.
+func callList(t *testing.T, handler echo.HandlerFunc, userID uint, method, target string, body interface{}, params ...string) (int, []map[string]interface{}) {
+	t.Helper()
+	rec := serve(t, handler, userID, method, target, body, params...)
+	var resp []map[string]interface{}
+	if rec.Code == http.StatusOK {
+		decode(t, rec, &resp)
+	}
+	return rec.Code, resp
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if rec.Code == http.StatusNoContent || rec.Body.Len() == 0 {
+		return
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+	}
+}
+
+func createUser(t *testing.T, db *gorm.DB, email string) models.User {
+	t.Helper()
+	user := models.User{Email: email, Username: email, Timezone: "UTC"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// createProject creates a project for the user with the given number of
+// steps, each with two multiple choice questions whose answer is option 0.
+func createProject(t *testing.T, db *gorm.DB, userID uint, steps int) models.Project {
+	t.Helper()
+	project := models.Project{UserID: userID, Goal: "Learn Go", Stack: "Go", Level: "beginner", GatingMode: "off", PassPercentage: 70}
+	for i := 1; i <= steps; i++ {
+		step := models.Step{StepNumber: i, Title: fmt.Sprintf("Step %d", i), Description: fmt.Sprintf("Description %d", i)}
+		for j := 0; j < 2; j++ {
+			options, _ := json.Marshal([]string{"right", "wrong", "also wrong"})
+			step.Quizzes = append(step.Quizzes, models.Quiz{
+				Type:        "multiple_choice",
+				Question:    fmt.Sprintf("Question %d.%d", i, j),
+				Options:     options,
+				AnswerIndex: 0,
+				Explanation: "Because",
+			})
+		}
+		project.Steps = append(project.Steps, step)
+	}
+	if err := db.Create(&project).Error; err != nil {
+		t.Fatal(err)
+	}
+	return project
+}
+
+// id formats a numeric ID from a JSON response or model as a path parameter.
+func id(v interface{}) string {
+	switch n := v.(type) {
+	case float64:
+		return fmt.Sprint(uint(n))
+	default:
+		return fmt.Sprint(n)
+	}
+}
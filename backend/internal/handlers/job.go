@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github/meso1007/reverse-learn/backend/internal/models"
@@ -9,10 +10,15 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// GetJob returns the status and result of one of the user's jobs.
 func (h *Handler) GetJob(c echo.Context) error {
+	userID := c.Get("userID").(uint)
 	jobID := c.Param("id")
 	var job models.Job
-	if err := h.DB.First(&job, jobID).Error; err != nil {
+	// Results hold the learner's grades and feedback, so jobs are private.
+	// Plan proposals are the exception: the home page asks for them before
+	// signing in, so they have no user and their result is only a plan
+	if err := h.DB.Where("id = ? AND (user_id = ? OR (user_id = 0 AND type = ?))", jobID, userID, "propose_plan").First(&job).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Job not found"})
 	}
 
@@ -35,3 +41,38 @@ func (h *Handler) GetJob(c echo.Context) error {
 		"updated_at": job.UpdatedAt,
 	})
 }
+
+var errQueueFull = errors.New("job queue is full")
+
+// queueJob saves a new job and pushes it onto the worker queue.
+func (h *Handler) queueJob(job *models.Job) error {
+	job.Status = "pending"
+	if err := h.DB.Create(job).Error; err != nil {
+		return err
+	}
+
+	select {
+	case h.JobQueue <- job.ID:
+		return nil
+	default:
+		job.Status = "failed"
+		job.Error = "Server is busy, please try again later"
+		h.DB.Save(job)
+		return errQueueFull
+	}
+}
+
+// enqueueJob queues a job and writes the 202 Accepted (or error) response itself.
+func (h *Handler) enqueueJob(c echo.Context, job *models.Job) error {
+	if err := h.queueJob(job); err != nil {
+		if errors.Is(err, errQueueFull) {
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "Server is busy"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create job"})
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"job_id": job.ID,
+		"status": "pending",
+	})
+}
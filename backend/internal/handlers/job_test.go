@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+)
+
+func TestGetJob(t *testing.T) {
+	h := newTestHandler(t)
+	user := createUser(t, h.DB, "learner@example.com")
+	other := createUser(t, h.DB, "other@example.com")
+
+	graded := models.Job{UserID: user.ID, Type: "grade_free_response", Status: "completed", Result: []byte(`{"score":1}`)}
+	h.DB.Create(&graded)
+	if code, resp := call(t, h.GetJob, user.ID, http.MethodGet, "/", nil, "id", id(graded.ID)); code != http.StatusOK || resp["status"] != "completed" {
+		t.Errorf("GetJob by its owner = %d %v", code, resp)
+	}
+	if code, _ := call(t, h.GetJob, other.ID, http.MethodGet, "/", nil, "id", id(graded.ID)); code != http.StatusNotFound {
+		t.Errorf("GetJob by another user = %d, want 404", code)
+	}
+
+	// Plan proposals are queued from the public home page, without a user
+	code, proposed := call(t, h.ProposePlan, 0, http.MethodPost, "/", map[string]string{"goal": "Build a CLI"})
+	if code != http.StatusAccepted {
+		t.Fatalf("ProposePlan = %d %v", code, proposed)
+	}
+	if code, resp := call(t, h.GetJob, other.ID, http.MethodGet, "/", nil, "id", id(proposed["job_id"])); code != http.StatusOK || resp["type"] != "propose_plan" {
+		t.Errorf("GetJob for a plan proposal = %d %v", code, resp)
+	}
+
+	// Other jobs without a user stay hidden
+	orphan := models.Job{Type: "generate_roadmap", Status: "completed"}
+	h.DB.Create(&orphan)
+	if code, _ := call(t, h.GetJob, user.ID, http.MethodGet, "/", nil, "id", id(orphan.ID)); code != http.StatusNotFound {
+		t.Errorf("GetJob for a job without a user = %d, want 404", code)
+	}
+}
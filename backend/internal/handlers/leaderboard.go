@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/leaderboard"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetLeaderboard returns a leaderboard. Query parameters: period (week,
+// month or all; default week), metric (xp or score; default xp), cohort
+// (a cohort the learner belongs to; global when omitted) and limit.
+func (h *Handler) GetLeaderboard(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	q := leaderboard.Query{
+		UserID: userID,
+		Period: c.QueryParam("period"),
+		Metric: c.QueryParam("metric"),
+		Limit:  leaderboard.DefaultLimit,
+	}
+	if q.Period == "" {
+		q.Period = leaderboard.PeriodWeek
+	}
+	if q.Metric == "" {
+		q.Metric = leaderboard.MetricXP
+	}
+	if !leaderboard.ValidPeriod(q.Period) || !leaderboard.ValidMetric(q.Metric) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown period or metric"})
+	}
+	if l, err := strconv.Atoi(c.QueryParam("limit")); err == nil && l > 0 {
+		q.Limit = min(l, leaderboard.MaxLimit)
+	}
+	if cohort := c.QueryParam("cohort"); cohort != "" {
+		id, err := strconv.ParseUint(cohort, 10, 32)
+		if err != nil || !h.isCohortMember(uint(id), userID) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Cohort not found"})
+		}
+		q.CohortID = uint(id)
+	}
+
+	board, err := leaderboard.Get(h.DB, q, time.Now())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch leaderboard"})
+	}
+	return c.JSON(http.StatusOK, board)
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/notes"
+
+	"github.com/labstack/echo/v4"
+)
+
+// bindNote reads and validates the body of a note request.
+func bindNote(c echo.Context) (string, error) {
+	req := new(models.NoteRequest)
+	if err := c.Bind(req); err != nil {
+		return "", errors.New("Invalid input")
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" || len([]rune(body)) > notes.MaxBodyLength {
+		return "", fmt.Errorf("Note must be 1 to %d characters", notes.MaxBodyLength)
+	}
+	return body, nil
+}
+
+// findStepNote loads a note of the user on the step addressed by the route.
+func (h *Handler) findStepNote(c echo.Context, userID uint) (models.Note, error) {
+	var note models.Note
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return note, err
+	}
+	step, err := h.findProjectStep(project.ID, c.Param("stepNumber"))
+	if err != nil {
+		return note, err
+	}
+	err = h.DB.Where("id = ? AND step_id = ? AND user_id = ?", c.Param("noteId"), step.ID, userID).First(&note).Error
+	return note, err
+}
+
+// GetStepNotes lists the learner's notes on a step, newest first.
+func (h *Handler) GetStepNotes(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	step, err := h.findProjectStep(project.ID, c.Param("stepNumber"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Step not found"})
+	}
+
+	var list []models.Note
+	if err := h.DB.Where("step_id = ? AND user_id = ?", step.ID, userID).Order("created_at desc").Find(&list).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch notes"})
+	}
+	return c.JSON(http.StatusOK, list)
+}
+
+// CreateNote adds a note to a step.
+func (h *Handler) CreateNote(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	step, err := h.findProjectStep(project.ID, c.Param("stepNumber"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Step not found"})
+	}
+	body, err := bindNote(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	note := models.Note{UserID: userID, ProjectID: project.ID, StepID: step.ID, Body: body}
+	if err := h.DB.Create(&note).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save note"})
+	}
+	return c.JSON(http.StatusCreated, note)
+}
+
+// UpdateNote replaces the body of a note.
+func (h *Handler) UpdateNote(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	note, err := h.findStepNote(c, userID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Note not found"})
+	}
+	body, err := bindNote(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	note.Body = body
+	if err := h.DB.Save(&note).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save note"})
+	}
+	return c.JSON(http.StatusOK, note)
+}
+
+// DeleteNote deletes a note.
+func (h *Handler) DeleteNote(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	note, err := h.findStepNote(c, userID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Note not found"})
+	}
+	if err := h.DB.Delete(&note).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete note"})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Note deleted successfully"})
+}
+
+// SearchNotes searches the learner's notes across all projects. Query
+// parameters: q (all words must match) and limit.
+func (h *Handler) SearchNotes(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	limit := notes.DefaultLimit
+	if l, err := strconv.Atoi(c.QueryParam("limit")); err == nil && l > 0 {
+		limit = min(l, notes.MaxLimit)
+	}
+	results, err := notes.Search(h.DB, userID, c.QueryParam("q"), limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to search notes"})
+	}
+	return c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/progress"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetMyProgress returns the learner's progress across all projects, so
+// dashboards need a single request instead of one per project.
+func (h *Handler) GetMyProgress(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	p, err := progress.Summarize(h.DB, userID, time.Now())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to compute progress"})
+	}
+	return c.JSON(http.StatusOK, p)
+}
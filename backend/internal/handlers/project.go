@@ -2,15 +2,27 @@ package handlers
 
 import (
 	"encoding/json"
-	"math/rand"
 	"net/http"
 	"time"
 
+	"github/meso1007/reverse-learn/backend/internal/certificate"
+	"github/meso1007/reverse-learn/backend/internal/gating"
 	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/pool"
+	"github/meso1007/reverse-learn/backend/internal/questions"
+	"github/meso1007/reverse-learn/backend/internal/roadmap"
+	"github/meso1007/reverse-learn/backend/internal/study"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
+// orderedSteps preloads steps in roadmap order rather than insertion order,
+// since steps can be added and reordered after generation.
+func orderedSteps(db *gorm.DB) *gorm.DB {
+	return db.Order("step_number")
+}
+
 func (h *Handler) ProposePlan(c echo.Context) error {
 	req := new(models.ProposeRequest)
 	if err := c.Bind(req); err != nil {
@@ -80,6 +92,8 @@ func (h *Handler) GenerateRoadmap(c echo.Context) error {
 	}
 }
 
+// GenerateStepQuiz returns the step's questions, generating them on first
+// use. With force, another batch is added to the step's question pool.
 func (h *Handler) GenerateStepQuiz(c echo.Context) error {
 	userID := c.Get("userID").(uint)
 
@@ -88,36 +102,39 @@ func (h *Handler) GenerateStepQuiz(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
 	}
 
-	// Check Cache (DB)
+	// Check Cache (DB). With force, new questions are added to the step's pool instead.
 	var project models.Project
 	h.DB.Where("user_id = ? AND goal = ?", userID, req.Goal).First(&project)
 	if project.ID != 0 {
 		var step models.Step
-		h.DB.Where("project_id = ? AND step_number = ?", project.ID, req.StepNumber).Preload("Quizzes").First(&step)
-		if step.ID != 0 && len(step.Quizzes) > 0 {
-			// Return cached quizzes
-			type QuizResponse struct {
-				Question    string   `json:"question"`
-				Options     []string `json:"options"`
-				AnswerIndex int      `json:"answer_index"`
-				Explanation string   `json:"explanation"`
-			}
-			var quizzesResp []QuizResponse
+		h.DB.Where("project_id = ? AND step_number = ?", project.ID, req.StepNumber).Preload("Quizzes", "remedial = ? AND hidden = ?", false, false).First(&step)
+		if step.ID != 0 && len(step.Quizzes) > 0 && !req.Force {
+			// Return cached quizzes (answers are only revealed through attempts)
+			var quizzesResp []questions.Preview
 			for _, q := range step.Quizzes {
-				var options []string
-				json.Unmarshal(q.Options, &options)
-				quizzesResp = append(quizzesResp, QuizResponse{
-					Question:    q.Question,
-					Options:     options,
-					AnswerIndex: q.AnswerIndex,
-					Explanation: q.Explanation,
-				})
+				quizzesResp = append(quizzesResp, questions.PreviewOf(q))
 			}
 
 			return c.JSON(http.StatusOK, map[string]interface{}{
 				"quizzes": quizzesResp,
 			})
 		}
+		if step.ID != 0 && pool.Room(step.Quizzes) == 0 {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"error":     "The question pool of this step is full",
+				"pool_size": len(step.Quizzes),
+			})
+		}
+	}
+
+	if project.ID != 0 {
+		lock, err := gating.StepLock(h.DB, project, req.StepNumber)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check step lock"})
+		}
+		if lock != nil {
+			return respondStepLocked(c, lock)
+		}
 	}
 
 	// Ensure locale matches project locale
@@ -180,21 +197,33 @@ func (h *Handler) GetProjects(c echo.Context) error {
 	return c.JSON(http.StatusOK, summaries)
 }
 
-func (h *Handler) GetLatestProject(c echo.Context) error {
-	userID := c.Get("userID").(uint)
-	locale := c.QueryParam("locale")
-
-	var project models.Project
-	query := h.DB.Where("user_id = ?", userID).Order("created_at desc").Preload("Steps")
-
-	if locale != "" {
-		query = query.Where("locale = ?", locale)
-	}
+// roadmapStep is one entry of the "roadmap" array in project responses.
+type roadmapStep struct {
+	Step        int          `json:"step"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	IsCompleted bool         `json:"is_completed"`
+	Score       *stepScore   `json:"score,omitempty"`
+	Locked      bool         `json:"locked"`
+	Unlock      *gating.Lock `json:"unlock,omitempty"`
+	// StudySeconds is the time the learner has spent on the step
+	StudySeconds int `json:"study_seconds"`
+}
 
-	if err := query.First(&project).Error; err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": "No project found"})
-	}
+type stepScore struct {
+	Score          int `json:"score"`
+	Total          int `json:"total"`
+	Percentage     int `json:"percentage"`
+	BestScore      int `json:"best_score"`
+	BestTotal      int `json:"best_total"`
+	BestPercentage int `json:"best_percentage"`
+	AttemptCount   int `json:"attempt_count"`
+}
 
+// roadmapSteps builds the roadmap of a project (with preloaded steps),
+// attaching the latest and best score of each step and its lock state. The
+// description of locked steps is left out.
+func (h *Handler) roadmapSteps(project models.Project) []roadmapStep {
 	// Also load scores for the steps
 	var scores []models.Score
 	stepIDs := make([]uint, len(project.Steps))
@@ -209,128 +238,103 @@ func (h *Handler) GetLatestProject(c echo.Context) error {
 		scoreMap[s.StepID] = s
 	}
 
-	// Construct response
-	type StepResponse struct {
-		Step        int    `json:"step"`
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		IsCompleted bool   `json:"is_completed"`
-		Score       *struct {
-			Score      int `json:"score"`
-			Total      int `json:"total"`
-			Percentage int `json:"percentage"`
-		} `json:"score,omitempty"`
-	}
+	locks := gating.Evaluate(project, project.Steps, scoreMap)
+	studySeconds, _ := study.StepSeconds(h.DB, project.UserID)
 
-	var stepsResp []StepResponse
+	var stepsResp []roadmapStep
 	for _, s := range project.Steps {
-		var scoreResp *struct {
-			Score      int `json:"score"`
-			Total      int `json:"total"`
-			Percentage int `json:"percentage"`
-		}
+		var scoreResp *stepScore
 		isCompleted := false
 		if sc, ok := scoreMap[s.ID]; ok {
 			isCompleted = true
-			scoreResp = &struct {
-				Score      int `json:"score"`
-				Total      int `json:"total"`
-				Percentage int `json:"percentage"`
-			}{
-				Score:      sc.Score,
-				Total:      sc.Total,
-				Percentage: sc.Percentage,
+			scoreResp = &stepScore{
+				Score:          sc.Score,
+				Total:          sc.Total,
+				Percentage:     sc.Percentage,
+				BestScore:      sc.BestScore,
+				BestTotal:      sc.BestTotal,
+				BestPercentage: sc.BestPercentage,
+				AttemptCount:   sc.AttemptCount,
 			}
 		}
-		stepsResp = append(stepsResp, StepResponse{
-			Step:        s.StepNumber,
-			Title:       s.Title,
-			Description: s.Description,
-			IsCompleted: isCompleted,
-			Score:       scoreResp,
+		// Locked steps show their title only, like the quiz endpoints that
+		// refuse them
+		description := s.Description
+		if locks[s.ID] != nil {
+			description = ""
+		}
+		stepsResp = append(stepsResp, roadmapStep{
+			Step:         s.StepNumber,
+			Title:        s.Title,
+			Description:  description,
+			IsCompleted:  isCompleted,
+			Score:        scoreResp,
+			Locked:       locks[s.ID] != nil,
+			Unlock:       locks[s.ID],
+			StudySeconds: studySeconds[s.ID],
 		})
 	}
+	return stepsResp
+}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"id":      project.ID,
-		"goal":    project.Goal,
-		"stack":   project.Stack,
-		"level":   project.Level,
-		"roadmap": stepsResp,
-	})
+// totalStudySeconds returns the time spent on all steps of a roadmap.
+func totalStudySeconds(steps []roadmapStep) int {
+	total := 0
+	for _, s := range steps {
+		total += s.StudySeconds
+	}
+	return total
 }
 
-func (h *Handler) GetProject(c echo.Context) error {
+func (h *Handler) GetLatestProject(c echo.Context) error {
 	userID := c.Get("userID").(uint)
-	projectID := c.Param("id")
+	locale := c.QueryParam("locale")
 
 	var project models.Project
-	if err := h.DB.Where("id = ? AND user_id = ?", projectID, userID).Preload("Steps").First(&project).Error; err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
-	}
+	query := h.DB.Where("user_id = ?", userID).Order("created_at desc").Preload("Steps", orderedSteps)
 
-	// Also load scores for the steps
-	var scores []models.Score
-	stepIDs := make([]uint, len(project.Steps))
-	for i, s := range project.Steps {
-		stepIDs[i] = s.ID
+	if locale != "" {
+		query = query.Where("locale = ?", locale)
 	}
-	h.DB.Where("step_id IN ?", stepIDs).Find(&scores)
 
-	// Map scores to step IDs
-	scoreMap := make(map[uint]models.Score)
-	for _, s := range scores {
-		scoreMap[s.StepID] = s
+	if err := query.First(&project).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "No project found"})
 	}
 
-	// Construct response
-	type StepResponse struct {
-		Step        int    `json:"step"`
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		IsCompleted bool   `json:"is_completed"`
-		Score       *struct {
-			Score      int `json:"score"`
-			Total      int `json:"total"`
-			Percentage int `json:"percentage"`
-		} `json:"score,omitempty"`
-	}
+	stepsResp := h.roadmapSteps(project)
 
-	var stepsResp []StepResponse
-	for _, s := range project.Steps {
-		var scoreResp *struct {
-			Score      int `json:"score"`
-			Total      int `json:"total"`
-			Percentage int `json:"percentage"`
-		}
-		isCompleted := false
-		if sc, ok := scoreMap[s.ID]; ok {
-			isCompleted = true
-			scoreResp = &struct {
-				Score      int `json:"score"`
-				Total      int `json:"total"`
-				Percentage int `json:"percentage"`
-			}{
-				Score:      sc.Score,
-				Total:      sc.Total,
-				Percentage: sc.Percentage,
-			}
-		}
-		stepsResp = append(stepsResp, StepResponse{
-			Step:        s.StepNumber,
-			Title:       s.Title,
-			Description: s.Description,
-			IsCompleted: isCompleted,
-			Score:       scoreResp,
-		})
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id":            project.ID,
+		"goal":          project.Goal,
+		"stack":         project.Stack,
+		"level":         project.Level,
+		"gating":        projectGating(project),
+		"share":         projectShare(project),
+		"roadmap":       stepsResp,
+		"study_seconds": totalStudySeconds(stepsResp),
+	})
+}
+
+func (h *Handler) GetProject(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+	projectID := c.Param("id")
+
+	var project models.Project
+	if err := h.DB.Where("id = ? AND user_id = ?", projectID, userID).Preload("Steps", orderedSteps).First(&project).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
 	}
 
+	stepsResp := h.roadmapSteps(project)
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"id":      project.ID,
-		"goal":    project.Goal,
-		"stack":   project.Stack,
-		"level":   project.Level,
-		"roadmap": stepsResp,
+		"id":            project.ID,
+		"goal":          project.Goal,
+		"stack":         project.Stack,
+		"level":         project.Level,
+		"gating":        projectGating(project),
+		"share":         projectShare(project),
+		"roadmap":       stepsResp,
+		"study_seconds": totalStudySeconds(stepsResp),
 	})
 }
 
@@ -345,100 +349,65 @@ func (h *Handler) GetStep(c echo.Context) error {
 	}
 
 	var step models.Step
-	if err := h.DB.Where("project_id = ? AND step_number = ?", project.ID, stepNumber).Preload("Quizzes").First(&step).Error; err != nil {
+	if err := h.DB.Where("project_id = ? AND step_number = ?", project.ID, stepNumber).Preload("Quizzes", "remedial = ? AND hidden = ?", false, false).First(&step).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Step not found"})
 	}
 
-	// Format quizzes
-	type QuizResponse struct {
-		Question    string   `json:"question"`
-		Options     []string `json:"options"`
-		AnswerIndex int      `json:"answer_index"`
-		Explanation string   `json:"explanation"`
+	lock, err := gating.StepLock(h.DB, project, step.StepNumber)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check step lock"})
+	}
+	if lock != nil {
+		return respondStepLocked(c, lock)
 	}
-	var quizzes []QuizResponse
-	for _, q := range step.Quizzes {
-		var options []string
-		json.Unmarshal(q.Options, &options)
-
-		// Shuffle options
-		shuffledOptions := make([]string, len(options))
-		copy(shuffledOptions, options)
-
-		// Create a mapping of old index to new index to update AnswerIndex
-		perm := rand.Perm(len(options))
-		for i, v := range perm {
-			shuffledOptions[i] = options[v]
-		}
 
-		// Find new answer index
-		newAnswerIndex := 0
-		for i, v := range perm {
-			if v == q.AnswerIndex {
-				newAnswerIndex = i
-				break
-			}
-		}
+	var remedialCount int64
+	h.DB.Model(&models.Quiz{}).Where("step_id = ? AND remedial = ?", step.ID, true).Count(&remedialCount)
 
-		quizzes = append(quizzes, QuizResponse{
-			Question:    q.Question,
-			Options:     shuffledOptions,
-			AnswerIndex: newAnswerIndex,
-			Explanation: q.Explanation,
-		})
+	// Answers and explanations are only revealed through the attempt flow
+	var quizzes []questions.Preview
+	for _, q := range step.Quizzes {
+		quizzes = append(quizzes, questions.PreviewOf(q))
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"step":        step.StepNumber,
-		"title":       step.Title,
-		"description": step.Description,
-		"quizzes":     quizzes,
+		"step":             step.StepNumber,
+		"title":            step.Title,
+		"description":      step.Description,
+		"quizzes":          quizzes,
+		"remedial_quizzes": remedialCount,
 	})
 }
 
-func (h *Handler) SaveStepScore(c echo.Context) error {
+func (h *Handler) GetStepAttempts(c echo.Context) error {
 	userID := c.Get("userID").(uint)
-	projectID := c.Param("id")
-	stepNumber := c.Param("stepNumber")
-
-	type ScoreRequest struct {
-		Score      int `json:"score"`
-		Total      int `json:"total"`
-		Percentage int `json:"percentage"`
-	}
-	req := new(ScoreRequest)
-	if err := c.Bind(req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
-	}
 
-	var project models.Project
-	if err := h.DB.Where("id = ? AND user_id = ?", projectID, userID).First(&project).Error; err != nil {
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
 	}
 
-	var step models.Step
-	if err := h.DB.Where("project_id = ? AND step_number = ?", project.ID, stepNumber).First(&step).Error; err != nil {
+	step, err := h.findProjectStep(project.ID, c.Param("stepNumber"))
+	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Step not found"})
 	}
 
-	// Save or update score
-	var score models.Score
-	if err := h.DB.Where("step_id = ?", step.ID).First(&score).Error; err == nil {
-		score.Score = req.Score
-		score.Total = req.Total
-		score.Percentage = req.Percentage
-		h.DB.Save(&score)
-	} else {
-		score = models.Score{
-			StepID:     step.ID,
-			Score:      req.Score,
-			Total:      req.Total,
-			Percentage: req.Percentage,
-		}
-		h.DB.Create(&score)
+	var attempts []models.QuizAttempt
+	if err := h.DB.Where("step_id = ?", step.ID).Order("created_at desc, id desc").Preload("Answers").Find(&attempts).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch attempts"})
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+	var score models.Score
+	h.DB.Where("step_id = ?", step.ID).Limit(1).Find(&score)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"step":            step.StepNumber,
+		"attempt_count":   len(attempts),
+		"best_score":      score.BestScore,
+		"best_total":      score.BestTotal,
+		"best_percentage": score.BestPercentage,
+		"attempts":        attempts,
+	})
 }
 
 func (h *Handler) DeleteProject(c echo.Context) error {
@@ -450,10 +419,37 @@ func (h *Handler) DeleteProject(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
 	}
 
-	// Delete related steps (and quizzes/scores via GORM if configured, but manual for safety here)
-	h.DB.Where("project_id = ?", project.ID).Delete(&models.Step{})
-
-	if err := h.DB.Delete(&project).Error; err != nil {
+	// Steps go with everything built on them (quizzes, attempts, scores,
+	// review cards, study sessions...), so that nothing keeps counting
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var stepIDs []uint
+		if err := tx.Model(&models.Step{}).Where("project_id = ?", project.ID).Pluck("id", &stepIDs).Error; err != nil {
+			return err
+		}
+		for _, id := range stepIDs {
+			if err := roadmap.DeleteStep(tx, id); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("project_id = ?", project.ID).Delete(&models.RoadmapRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("conversation_id IN (?)", tx.Model(&models.Conversation{}).Select("id").Where("project_id = ?", project.ID)).Delete(&models.Message{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", project.ID).Delete(&models.Conversation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", project.ID).Delete(&models.Note{}).Error; err != nil {
+			return err
+		}
+		// Certificates are kept so their codes still resolve, but no longer count as valid
+		if err := certificate.Revoke(tx, project.ID); err != nil {
+			return err
+		}
+		return tx.Delete(&project).Error
+	})
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete project"})
 	}
 
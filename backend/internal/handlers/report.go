@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/moderation"
+	"github/meso1007/reverse-learn/backend/internal/questions"
+	"github/meso1007/reverse-learn/backend/internal/roadmap"
+	"github/meso1007/reverse-learn/backend/internal/scoring"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// maxReportCommentLength limits the comment of a question report.
+const maxReportCommentLength = 1000
+
+// userCanSeeQuiz reports whether the quiz belongs to one of the user's
+// projects or was served to the user.
+func (h *Handler) userCanSeeQuiz(userID uint, quiz models.Quiz) bool {
+	var owned int64
+	h.DB.Model(&models.Step{}).
+		Joins("JOIN projects ON projects.id = steps.project_id").
+		Where("steps.id = ? AND projects.user_id = ?", quiz.StepID, userID).
+		Count(&owned)
+	if owned > 0 {
+		return true
+	}
+
+	var served int64
+	h.DB.Model(&models.AttemptAnswer{}).
+		Joins("JOIN quiz_attempts ON quiz_attempts.id = attempt_answers.attempt_id").
+		Where("attempt_answers.quiz_id = ? AND quiz_attempts.user_id = ?", quiz.ID, userID).
+		Count(&served)
+	return served > 0
+}
+
+// ReportQuiz records a learner's report of a problem with a question. A
+// question reported by enough learners is hidden until a moderator reviews it.
+func (h *Handler) ReportQuiz(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	type ReportRequest struct {
+		Category string `json:"category"` // wrong_answer, unclear, typo, off_topic, other
+		Comment  string `json:"comment"`
+	}
+	req := new(ReportRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	if !moderation.ValidCategory(req.Category) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Category must be wrong_answer, unclear, typo, off_topic or other"})
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+	if len([]rune(req.Comment)) > maxReportCommentLength {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Comment is too long"})
+	}
+
+	var quiz models.Quiz
+	if err := h.DB.First(&quiz, c.Param("id")).Error; err != nil || !h.userCanSeeQuiz(userID, quiz) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Question not found"})
+	}
+
+	var open int64
+	h.DB.Model(&models.QuizReport{}).Where("quiz_id = ? AND user_id = ? AND status = ?", quiz.ID, userID, moderation.StatusOpen).Count(&open)
+	if open > 0 {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "You have already reported this question"})
+	}
+
+	report := models.QuizReport{
+		QuizID:    quiz.ID,
+		UserID:    userID,
+		Category:  req.Category,
+		Comment:   req.Comment,
+		Status:    moderation.StatusOpen,
+		CreatedAt: time.Now(),
+	}
+	hidden := false
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&report).Error; err != nil {
+			return err
+		}
+		var err error
+		hidden, err = moderation.RefreshHidden(tx, quiz.ID)
+		return err
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to report question"})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"report":      report,
+		"quiz_hidden": hidden,
+	})
+}
+
+// reportedQuiz is a question in the moderation queue with its answer and reports.
+type reportedQuiz struct {
+	QuizID      uint                `json:"quiz_id"`
+	StepID      uint                `json:"step_id"`
+	Type        string              `json:"type"`
+	Question    string              `json:"question"`
+	Code        string              `json:"code,omitempty"`
+	Options     []string            `json:"options"`
+	AnswerIndex int                 `json:"answer_index"`
+	Answer      questions.Answer    `json:"answer"`
+	AnswerText  string              `json:"answer_text"`
+	Explanation string              `json:"explanation"`
+	Hidden      bool                `json:"hidden"`
+	Reports     []models.QuizReport `json:"reports"`
+}
+
+// GetAdminReports lists the reported questions with reports of the given
+// status (open by default), the most reported first.
+func (h *Handler) GetAdminReports(c echo.Context) error {
+	status := c.QueryParam("status")
+	if status == "" {
+		status = moderation.StatusOpen
+	}
+
+	var reports []models.QuizReport
+	if err := h.DB.Where("status = ?", status).Order("created_at").Find(&reports).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch reports"})
+	}
+
+	byQuiz := make(map[uint][]models.QuizReport)
+	var quizIDs []uint
+	for _, r := range reports {
+		if _, ok := byQuiz[r.QuizID]; !ok {
+			quizIDs = append(quizIDs, r.QuizID)
+		}
+		byQuiz[r.QuizID] = append(byQuiz[r.QuizID], r)
+	}
+
+	var quizzes []models.Quiz
+	h.DB.Where("id IN ?", quizIDs).Find(&quizzes)
+	quizMap := make(map[uint]models.Quiz)
+	for _, q := range quizzes {
+		quizMap[q.ID] = q
+	}
+
+	queue := make([]reportedQuiz, 0, len(quizIDs))
+	for _, id := range quizIDs {
+		item := reportedQuiz{QuizID: id, Options: []string{}, Reports: byQuiz[id]}
+		// Deleted questions are listed with their reports only
+		if q, ok := quizMap[id]; ok {
+			item.StepID = q.StepID
+			item.Type = questions.TypeOf(q)
+			item.Question = q.Question
+			item.Code = q.Code
+			item.Options = questions.Options(q)
+			item.AnswerIndex = q.AnswerIndex
+			item.Answer = questions.AnswerOf(q)
+			item.AnswerText = questions.AnswerText(q)
+			item.Explanation = q.Explanation
+			item.Hidden = q.Hidden
+		}
+		queue = append(queue, item)
+	}
+	// Most reported first, oldest first among equals (reports are in creation order)
+	for i := 1; i < len(queue); i++ {
+		for j := i; j > 0 && len(queue[j].Reports) > len(queue[j-1].Reports); j-- {
+			queue[j], queue[j-1] = queue[j-1], queue[j]
+		}
+	}
+
+	return c.JSON(http.StatusOK, queue)
+}
+
+// quizFix holds the fields a moderator changes on a reported question.
+// Omitted fields are kept. Options can be reworded but not added or removed,
+// since answers already given refer to them by index.
+type quizFix struct {
+	Question        *string               `json:"question"`
+	Code            *string               `json:"code"`
+	Options         []string              `json:"options"`
+	AnswerIndex     *int                  `json:"answer_index"`
+	AnswerIndexes   []int                 `json:"answer_indexes"` // correct options, or every option in the correct order
+	AcceptedAnswers []string              `json:"accepted_answers"`
+	Rubric          []questions.Criterion `json:"rubric"`
+	ModelAnswer     *string               `json:"model_answer"`
+	Explanation     *string               `json:"explanation"`
+}
+
+// apply returns the quiz with the fix applied.
+func (f quizFix) apply(q models.Quiz) (models.Quiz, bool) {
+	if f.Question != nil {
+		q.Question = *f.Question
+	}
+	if f.Code != nil {
+		q.Code = *f.Code
+	}
+	if f.Options != nil {
+		if len(f.Options) != len(questions.Options(q)) {
+			return q, false
+		}
+		q.Options, _ = json.Marshal(f.Options)
+	}
+	if f.AnswerIndex != nil {
+		q.AnswerIndex = *f.AnswerIndex
+	}
+	if f.Explanation != nil {
+		q.Explanation = *f.Explanation
+	}
+
+	answer := questions.AnswerOf(q)
+	if f.AnswerIndexes != nil {
+		answer.Indexes = f.AnswerIndexes
+	}
+	if f.AcceptedAnswers != nil {
+		answer.Accepted = f.AcceptedAnswers
+	}
+	if f.Rubric != nil {
+		answer.Rubric = f.Rubric
+	}
+	if f.ModelAnswer != nil {
+		answer.ModelAnswer = *f.ModelAnswer
+	}
+	if f.AnswerIndexes != nil || f.AcceptedAnswers != nil || f.Rubric != nil || f.ModelAnswer != nil {
+		q.Answer, _ = json.Marshal(answer)
+	}
+	return q, true
+}
+
+// ResolveReport resolves every open report on the reported question:
+//   - fix: corrects the question in place, so every learner served it sees
+//     the fix, and regrades the answers already given
+//   - delete: removes the question and its answers from past attempts
+//   - dismiss: keeps the question as it is
+//
+// The question is shown again unless it was deleted.
+func (h *Handler) ResolveReport(c echo.Context) error {
+	reviewerID := c.Get("userID").(uint)
+
+	type ResolveRequest struct {
+		Action string  `json:"action"` // fix, delete, dismiss
+		Note   string  `json:"note"`
+		Fix    quizFix `json:"fix"`
+	}
+	req := new(ResolveRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	var report models.QuizReport
+	if err := h.DB.First(&report, c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Report not found"})
+	}
+	if report.Status != moderation.StatusOpen {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Report is already resolved"})
+	}
+	var quiz models.Quiz
+	if err := h.DB.First(&quiz, report.QuizID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Question not found"})
+	}
+
+	var status string
+	switch req.Action {
+	case "fix":
+		status = moderation.StatusFixed
+		fixed, ok := req.Fix.apply(quiz)
+		if !ok {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Options can be reworded but not added or removed"})
+		}
+		if err := questions.Validate(fixed); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid question: " + err.Error()})
+		}
+		quiz = fixed
+	case "delete":
+		status = moderation.StatusDeleted
+	case "dismiss":
+		status = moderation.StatusDismissed
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Action must be fix, delete or dismiss"})
+	}
+
+	var resolved int64
+	regraded := 0
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		switch req.Action {
+		case "fix":
+			if err := tx.Save(&quiz).Error; err != nil {
+				return err
+			}
+			// Explanations of mistakes were written against the old question
+			if err := tx.Where("quiz_id = ?", quiz.ID).Delete(&models.MistakeExplanation{}).Error; err != nil {
+				return err
+			}
+			if regraded, err = scoring.RegradeQuiz(tx, quiz); err != nil {
+				return err
+			}
+		case "delete":
+			if err := roadmap.DeleteQuiz(tx, quiz.ID); err != nil {
+				return err
+			}
+		}
+		resolved, err = moderation.Resolve(tx, quiz.ID, status, reviewerID, req.Note)
+		return err
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to resolve report"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"quiz_id":          quiz.ID,
+		"status":           status,
+		"resolved_reports": resolved,
+		"regraded_answers": regraded,
+	})
+}
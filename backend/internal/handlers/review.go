@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/activity"
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/questions"
+	"github/meso1007/reverse-learn/backend/internal/review"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// GetDueReviews lists the learner's review cards that are due, with the
+// question and shuffled options but without the answer.
+func (h *Handler) GetDueReviews(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	limit := 20
+	if v, err := strconv.Atoi(c.QueryParam("limit")); err == nil && v > 0 && v <= 100 {
+		limit = v
+	}
+
+	now := time.Now()
+	// Cards whose question, step or project was deleted, or whose question
+	// is hidden, are skipped
+	live := h.DB.Model(&models.Quiz{}).Select("quizzes.id").
+		Joins("JOIN steps ON steps.id = quizzes.step_id").
+		Joins("JOIN projects ON projects.id = steps.project_id").
+		Where("quizzes.hidden = ?", false)
+	due := h.DB.Model(&models.ReviewCard{}).
+		Where("user_id = ? AND due_at <= ?", userID, now).
+		Where("quiz_id IN (?)", live).
+		Session(&gorm.Session{})
+
+	var dueCount int64
+	due.Count(&dueCount)
+
+	var cards []models.ReviewCard
+	if err := due.Order("due_at").Limit(limit).Find(&cards).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch reviews"})
+	}
+
+	quizIDs := make([]uint, len(cards))
+	for i, card := range cards {
+		quizIDs[i] = card.QuizID
+	}
+	var quizzes []models.Quiz
+	h.DB.Where("id IN ?", quizIDs).Find(&quizzes)
+	quizMap := make(map[uint]models.Quiz)
+	for _, q := range quizzes {
+		quizMap[q.ID] = q
+	}
+
+	type ReviewResponse struct {
+		CardID   uint      `json:"card_id"`
+		QuizID   uint      `json:"quiz_id"`
+		Type     string    `json:"type"`
+		Question string    `json:"question"`
+		Code     string    `json:"code,omitempty"`
+		Options  []string  `json:"options"`
+		DueAt    time.Time `json:"due_at"`
+	}
+
+	reviews := make([]ReviewResponse, 0, len(cards))
+	for i := range cards {
+		card := &cards[i]
+		q := quizMap[card.QuizID]
+
+		options := questions.Options(q)
+
+		// Cards keep their option order until they are graded
+		var order []int
+		json.Unmarshal(card.OptionOrder, &order)
+		if card.OptionOrder == nil || len(order) != len(options) {
+			review.ShuffleOptions(card, q)
+			h.DB.Model(card).Update("option_order", card.OptionOrder)
+			json.Unmarshal(card.OptionOrder, &order)
+		}
+
+		displayed := make([]string, len(order))
+		for j, idx := range order {
+			displayed[j] = options[idx]
+		}
+
+		reviews = append(reviews, ReviewResponse{
+			CardID:   card.ID,
+			QuizID:   q.ID,
+			Type:     questions.TypeOf(q),
+			Question: q.Question,
+			Code:     q.Code,
+			Options:  displayed,
+			DueAt:    card.DueAt,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"due_count": dueCount,
+		"reviews":   reviews,
+	})
+}
+
+// GradeReview grades the answer to a due review card and schedules its next
+// review. Cards cannot be graded again before they are due.
+func (h *Handler) GradeReview(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	type GradeRequest struct {
+		questions.Response      // answer, with indexes into the options as served
+		Quality            *int `json:"quality"` // optional self-assessment (3-5) for correct answers
+	}
+	req := new(GradeRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	var card models.ReviewCard
+	if err := h.DB.Where("id = ? AND user_id = ?", c.Param("cardId"), userID).First(&card).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Review card not found"})
+	}
+	now := time.Now()
+	if card.DueAt.After(now) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Review card is not due yet"})
+	}
+	// The review is identified by the due date it was scheduled for
+	dueAt := card.DueAt
+
+	var quiz models.Quiz
+	if err := h.DB.First(&quiz, card.QuizID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Question not found"})
+	}
+
+	var order []int
+	json.Unmarshal(card.OptionOrder, &order)
+	given, err := questions.ToOriginal(quiz, order, req.Response)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid answer for this question type"})
+	}
+
+	answer := questions.Reveal(quiz, order)
+	isCorrect := questions.Grade(quiz, given)
+
+	quality := review.QualityWrong
+	if isCorrect {
+		quality = review.QualityDefault
+		if req.Quality != nil && *req.Quality >= review.QualityHard && *req.Quality <= review.QualityEasy {
+			quality = *req.Quality
+		}
+	}
+
+	review.Schedule(&card, quality, now)
+	if err := h.DB.Save(&card).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save review"})
+	}
+
+	resp := map[string]interface{}{
+		"card_id":       card.ID,
+		"is_correct":    isCorrect,
+		"explanation":   quiz.Explanation,
+		"interval_days": card.IntervalDays,
+		"ease_factor":   card.EaseFactor,
+		"next_due_at":   card.DueAt,
+	}
+	// The correct answer, in the same shape as attempt questions
+	switch t := questions.TypeOf(quiz); {
+	case questions.SingleChoice(t):
+		resp["answer_index"] = answer.Choice
+	case questions.HasOptions(t):
+		resp["answer_indexes"] = answer.Choices
+	default:
+		resp["answer_text"] = answer.Text
+	}
+	resp["xp_awarded"], resp["badges_earned"] = h.recordActivity(userID, map[string]string{
+		activity.EventReviewDone: fmt.Sprintf("review:%d:%d", card.ID, dueAt.Unix()),
+	})
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github/meso1007/reverse-learn/backend/internal/gating"
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/roadmap"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// findRevision loads a revision of the project by its number.
+func (h *Handler) findRevision(projectID uint, number string) (models.RoadmapRevision, error) {
+	var rev models.RoadmapRevision
+	err := h.DB.Where("project_id = ? AND number = ?", projectID, number).First(&rev).Error
+	return rev, err
+}
+
+// redactSnapshot blanks the description of a revision step that is locked
+// now, as roadmapSteps does for the current roadmap.
+func redactSnapshot(step *roadmap.StepSnapshot, locks map[uint]*gating.Lock) {
+	if step != nil && locks[step.StepID] != nil {
+		step.Description = ""
+	}
+}
+
+func (h *Handler) GetRevisions(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	// Projects created before revisions existed get their current state as revision 1
+	if err := roadmap.EnsureBaseline(h.DB, project.ID, userID); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch revisions"})
+	}
+
+	var revisions []models.RoadmapRevision
+	if err := h.DB.Where("project_id = ?", project.ID).Order("number desc").Find(&revisions).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch revisions"})
+	}
+
+	type RevisionSummary struct {
+		models.RoadmapRevision
+		StepCount int `json:"step_count"`
+	}
+
+	summaries := make([]RevisionSummary, 0, len(revisions))
+	for _, rev := range revisions {
+		steps, _ := roadmap.Steps(rev)
+		summaries = append(summaries, RevisionSummary{
+			RoadmapRevision: rev,
+			StepCount:       len(steps),
+		})
+	}
+
+	return c.JSON(http.StatusOK, summaries)
+}
+
+func (h *Handler) GetRevision(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	rev, err := h.findRevision(project.ID, c.Param("number"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Revision not found"})
+	}
+
+	steps, err := roadmap.Steps(rev)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to read revision"})
+	}
+	locks, err := gating.StepLocks(h.DB, project)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check step locks"})
+	}
+	for i := range steps {
+		redactSnapshot(&steps[i], locks)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"revision": rev,
+		"steps":    steps,
+	})
+}
+
+// DiffRevisions compares two revisions (?from=&to=). "to" defaults to the
+// latest revision and "from" to the one before it.
+func (h *Handler) DiffRevisions(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	var latest models.RoadmapRevision
+	if err := h.DB.Where("project_id = ?", project.ID).Order("number desc").First(&latest).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Revision not found"})
+	}
+
+	to := latest
+	if toParam := c.QueryParam("to"); toParam != "" {
+		if to, err = h.findRevision(project.ID, toParam); err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Revision not found"})
+		}
+	}
+
+	// The first revision is compared against an empty roadmap by default
+	var from models.RoadmapRevision
+	fromParam := c.QueryParam("from")
+	if fromParam == "" && to.Number > 1 {
+		fromParam = strconv.Itoa(to.Number - 1)
+	}
+	if fromParam != "" {
+		if from, err = h.findRevision(project.ID, fromParam); err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Revision not found"})
+		}
+	}
+
+	fromSteps, err := roadmap.Steps(from)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to read revision"})
+	}
+	toSteps, err := roadmap.Steps(to)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to read revision"})
+	}
+	locks, err := gating.StepLocks(h.DB, project)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check step locks"})
+	}
+
+	// Changes to locked steps are listed without their descriptions
+	changes := roadmap.Diff(fromSteps, toSteps)
+	for i := range changes {
+		redactSnapshot(changes[i].From, locks)
+		redactSnapshot(changes[i].To, locks)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"from":    from.Number,
+		"to":      to.Number,
+		"changes": changes,
+	})
+}
+
+func (h *Handler) RevertRevision(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	rev, err := h.findRevision(project.ID, c.Param("number"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Revision not found"})
+	}
+
+	steps, err := roadmap.Steps(rev)
+	if err != nil || len(steps) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Revision cannot be restored"})
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		locked, err := gating.StepLocks(tx, project)
+		if err != nil {
+			return err
+		}
+		if err := roadmap.Revert(tx, project.ID, steps); err != nil {
+			return err
+		}
+		if err := keepLocked(tx, project, locked); err != nil {
+			return err
+		}
+		_, err = roadmap.Record(tx, project.ID, roadmap.SourceRevert, userID, nil)
+		return err
+	})
+	if errors.Is(err, errUnlocksSteps) {
+		return respondUnlocksSteps(c)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to revert roadmap"})
+	}
+
+	return h.respondRoadmap(c, project.ID)
+}
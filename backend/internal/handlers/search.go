@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github/meso1007/reverse-learn/backend/internal/search"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Search searches the learner's projects, steps and quiz questions. Query
+// parameters: q (all words must match), kind (project, step or quiz),
+// locale, completed (true or false; a project is completed when all its
+// steps are, a step when its quiz has a score) and limit.
+func (h *Handler) Search(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	q := search.Query{
+		UserID: userID,
+		Text:   c.QueryParam("q"),
+		Kind:   c.QueryParam("kind"),
+		Locale: c.QueryParam("locale"),
+		Limit:  search.DefaultLimit,
+	}
+	if q.Kind != "" && !search.ValidKind(q.Kind) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Kind must be project, step or quiz"})
+	}
+	if completed := c.QueryParam("completed"); completed != "" {
+		v, err := strconv.ParseBool(completed)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Completed must be true or false"})
+		}
+		q.Completed = &v
+	}
+	if l, err := strconv.Atoi(c.QueryParam("limit")); err == nil && l > 0 {
+		q.Limit = min(l, search.MaxLimit)
+	}
+
+	results, err := search.Search(h.DB, q)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to search"})
+	}
+	return c.JSON(http.StatusOK, results)
+}
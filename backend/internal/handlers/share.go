@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newShareSlug returns a random slug for a public roadmap link. 128 bits
+// make links impossible to guess or enumerate.
+func newShareSlug() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// projectShare returns the sharing settings of a project.
+func projectShare(project models.Project) map[string]interface{} {
+	share := map[string]interface{}{
+		"enabled":       project.ShareSlug != nil,
+		"show_progress": project.ShareProgress,
+	}
+	if project.ShareSlug != nil {
+		share["slug"] = *project.ShareSlug
+	}
+	return share
+}
+
+// UpdateShare shares the project through a public link, creating the link
+// if the project is not shared yet, and sets whether it shows progress.
+func (h *Handler) UpdateShare(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	type ShareRequest struct {
+		ShowProgress *bool `json:"show_progress"` // 進捗も公開する
+	}
+	req := new(ShareRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	if project.ShareSlug == nil {
+		slug, err := newShareSlug()
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to share project"})
+		}
+		project.ShareSlug = &slug
+	}
+	if req.ShowProgress != nil {
+		project.ShareProgress = *req.ShowProgress
+	}
+
+	if err := h.DB.Model(&project).Updates(map[string]interface{}{
+		"share_slug":     project.ShareSlug,
+		"share_progress": project.ShareProgress,
+	}).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to share project"})
+	}
+	return c.JSON(http.StatusOK, projectShare(project))
+}
+
+// RevokeShare stops sharing the project. The old link stops working for
+// good; sharing again creates a new one.
+func (h *Handler) RevokeShare(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	project.ShareSlug = nil
+	if err := h.DB.Model(&project).Update("share_slug", nil).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to revoke share link"})
+	}
+	return c.JSON(http.StatusOK, projectShare(project))
+}
+
+// sharedStep is a step as shown through a public link. It is built field by
+// field so that questions, answers and explanations can never leak.
+type sharedStep struct {
+	Step        int    `json:"step"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// Progress, only when the learner shares it
+	IsCompleted    *bool `json:"is_completed,omitempty"`
+	BestPercentage *int  `json:"best_percentage,omitempty"`
+}
+
+// GetSharedProject returns a shared roadmap without authentication.
+func (h *Handler) GetSharedProject(c echo.Context) error {
+	var project models.Project
+	if err := h.DB.Where("share_slug = ?", c.Param("slug")).Preload("Steps", orderedSteps).First(&project).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Shared roadmap not found"})
+	}
+
+	// Revoked links must stop working at once, and shared roadmaps are not
+	// meant to be indexed
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	c.Response().Header().Set("X-Robots-Tag", "noindex")
+
+	// Built from the roadmap so that locked steps stay redacted
+	roadmap := h.roadmapSteps(project)
+	steps := make([]sharedStep, 0, len(roadmap))
+	completed := 0
+	for _, s := range roadmap {
+		step := sharedStep{Step: s.Step, Title: s.Title, Description: s.Description}
+		if project.ShareProgress {
+			step.IsCompleted = &s.IsCompleted
+			if s.Score != nil {
+				step.BestPercentage = &s.Score.BestPercentage
+			}
+		}
+		if s.IsCompleted {
+			completed++
+		}
+		steps = append(steps, step)
+	}
+
+	resp := map[string]interface{}{
+		"goal":       project.Goal,
+		"stack":      project.Stack,
+		"level":      project.Level,
+		"locale":     project.Locale,
+		"created_at": project.CreatedAt.Format(time.RFC3339),
+		"steps":      steps,
+	}
+	if project.ShareProgress {
+		resp["progress"] = map[string]interface{}{
+			"steps_completed": completed,
+			"steps_total":     len(roadmap),
+			"study_seconds":   totalStudySeconds(roadmap),
+		}
+	}
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github/meso1007/reverse-learn/backend/internal/gating"
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/roadmap"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// maxFeedbackLength limits the free-text feedback passed into prompts.
+const maxFeedbackLength = 2000
+
+// findUserProject loads a project owned by the given user.
+func (h *Handler) findUserProject(userID uint, projectID string) (models.Project, error) {
+	var project models.Project
+	err := h.DB.Where("id = ? AND user_id = ?", projectID, userID).First(&project).Error
+	return project, err
+}
+
+// findProjectStep loads a step of the project by its step number.
+func (h *Handler) findProjectStep(projectID uint, stepNumber string) (models.Step, error) {
+	var step models.Step
+	err := h.DB.Where("project_id = ? AND step_number = ?", projectID, stepNumber).First(&step).Error
+	return step, err
+}
+
+func (h *Handler) RegenerateStep(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	type RegenerateRequest struct {
+		Feedback          string `json:"feedback"`
+		InvalidateQuizzes bool   `json:"invalidate_quizzes"`
+	}
+	req := new(RegenerateRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	step, err := h.findProjectStep(project.ID, c.Param("stepNumber"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Step not found"})
+	}
+
+	if len(req.Feedback) > maxFeedbackLength {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Feedback is too long"})
+	}
+
+	// The new description is returned with the job, so locked steps stay as they are
+	lock, err := gating.StepLock(h.DB, project, step.StepNumber)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check step lock"})
+	}
+	if lock != nil {
+		return respondStepLocked(c, lock)
+	}
+
+	inputBytes, _ := json.Marshal(models.RegenerateStepRequest{
+		ProjectID:         project.ID,
+		StepNumber:        step.StepNumber,
+		Feedback:          req.Feedback,
+		InvalidateQuizzes: req.InvalidateQuizzes,
+	})
+	return h.enqueueJob(c, &models.Job{
+		UserID: userID,
+		Type:   "regenerate_step",
+		Input:  inputBytes,
+	})
+}
+
+// respondRoadmap writes the project's steps in their current order, with
+// locked steps redacted as in GetProject.
+func (h *Handler) respondRoadmap(c echo.Context, projectID uint) error {
+	var project models.Project
+	if err := h.DB.Preload("Steps", orderedSteps).First(&project, projectID).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch steps"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id":      projectID,
+		"roadmap": h.roadmapSteps(project),
+	})
+}
+
+// errUnlocksSteps is returned when a roadmap edit would open or remove a step
+// that gating keeps locked.
+var errUnlocksSteps = errors.New("edit would unlock gated steps")
+
+// keepLocked checks, inside an edit's transaction, that every step locked
+// before the edit is still there and still locked, so that reordering,
+// deleting or reverting steps cannot get around gating.
+func keepLocked(tx *gorm.DB, project models.Project, before map[uint]*gating.Lock) error {
+	if len(before) == 0 {
+		return nil
+	}
+	after, err := gating.StepLocks(tx, project)
+	if err != nil {
+		return err
+	}
+	for stepID := range before {
+		if after[stepID] == nil {
+			return errUnlocksSteps
+		}
+	}
+	return nil
+}
+
+// respondUnlocksSteps refuses an edit that keepLocked rejected.
+func respondUnlocksSteps(c echo.Context) error {
+	return c.JSON(http.StatusForbidden, map[string]string{"error": "Locked steps cannot be deleted or moved ahead of the steps that unlock them"})
+}
+
+// renumberSteps assigns consecutive step numbers (1..n) following the given order.
+// Quizzes and scores reference the step ID, so they follow their step.
+func renumberSteps(tx *gorm.DB, steps []models.Step) error {
+	for i := range steps {
+		if steps[i].StepNumber == i+1 {
+			continue
+		}
+		steps[i].StepNumber = i + 1
+		if err := tx.Model(&models.Step{}).Where("id = ?", steps[i].ID).Update("step_number", i+1).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *Handler) AddStep(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	type AddStepRequest struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Position    int    `json:"position"` // 1-based; 0 appends to the end
+	}
+	req := new(AddStepRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Title is required"})
+	}
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := roadmap.EnsureBaseline(tx, project.ID, userID); err != nil {
+			return err
+		}
+
+		var steps []models.Step
+		if err := tx.Where("project_id = ?", project.ID).Order("step_number").Find(&steps).Error; err != nil {
+			return err
+		}
+
+		pos := req.Position
+		if pos <= 0 || pos > len(steps)+1 {
+			pos = len(steps) + 1
+		}
+
+		step := models.Step{
+			ProjectID:   project.ID,
+			StepNumber:  pos,
+			Title:       strings.TrimSpace(req.Title),
+			Description: req.Description,
+			UserCreated: true,
+		}
+		if err := tx.Create(&step).Error; err != nil {
+			return err
+		}
+
+		ordered := make([]models.Step, 0, len(steps)+1)
+		ordered = append(ordered, steps[:pos-1]...)
+		ordered = append(ordered, step)
+		ordered = append(ordered, steps[pos-1:]...)
+		if err := renumberSteps(tx, ordered); err != nil {
+			return err
+		}
+		_, err := roadmap.Record(tx, project.ID, roadmap.SourceUserEdit, userID, nil)
+		return err
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to add step"})
+	}
+
+	return h.respondRoadmap(c, project.ID)
+}
+
+func (h *Handler) UpdateStep(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	type UpdateStepRequest struct {
+		Title       *string `json:"title"`
+		Description *string `json:"description"`
+	}
+	req := new(UpdateStepRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	step, err := h.findProjectStep(project.ID, c.Param("stepNumber"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Step not found"})
+	}
+
+	if req.Title != nil {
+		if strings.TrimSpace(*req.Title) == "" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Title cannot be empty"})
+		}
+		step.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Description != nil {
+		step.Description = *req.Description
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := roadmap.EnsureBaseline(tx, project.ID, userID); err != nil {
+			return err
+		}
+		if err := tx.Save(&step).Error; err != nil {
+			return err
+		}
+		_, err := roadmap.Record(tx, project.ID, roadmap.SourceUserEdit, userID, nil)
+		return err
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update step"})
+	}
+
+	return h.respondRoadmap(c, project.ID)
+}
+
+func (h *Handler) ReorderSteps(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	type ReorderRequest struct {
+		Order []int `json:"order"` // current step numbers in the desired order
+	}
+	req := new(ReorderRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	var steps []models.Step
+	h.DB.Where("project_id = ?", project.ID).Order("step_number").Find(&steps)
+
+	byNumber := make(map[int]models.Step)
+	for _, s := range steps {
+		byNumber[s.StepNumber] = s
+	}
+
+	// The order must list every existing step exactly once
+	if len(req.Order) != len(steps) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Order must include every step exactly once"})
+	}
+	ordered := make([]models.Step, 0, len(steps))
+	for _, n := range req.Order {
+		s, ok := byNumber[n]
+		if !ok {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Order must include every step exactly once"})
+		}
+		delete(byNumber, n)
+		ordered = append(ordered, s)
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		locked, err := gating.StepLocks(tx, project)
+		if err != nil {
+			return err
+		}
+		if err := roadmap.EnsureBaseline(tx, project.ID, userID); err != nil {
+			return err
+		}
+		if err := renumberSteps(tx, ordered); err != nil {
+			return err
+		}
+		if err := keepLocked(tx, project, locked); err != nil {
+			return err
+		}
+		_, err = roadmap.Record(tx, project.ID, roadmap.SourceUserEdit, userID, nil)
+		return err
+	})
+	if errors.Is(err, errUnlocksSteps) {
+		return respondUnlocksSteps(c)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to reorder steps"})
+	}
+
+	return h.respondRoadmap(c, project.ID)
+}
+
+func (h *Handler) DeleteStep(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	step, err := h.findProjectStep(project.ID, c.Param("stepNumber"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Step not found"})
+	}
+
+	var count int64
+	h.DB.Model(&models.Step{}).Where("project_id = ?", project.ID).Count(&count)
+	if count <= 1 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "A roadmap must have at least one step"})
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		locked, err := gating.StepLocks(tx, project)
+		if err != nil {
+			return err
+		}
+		if err := roadmap.EnsureBaseline(tx, project.ID, userID); err != nil {
+			return err
+		}
+		if err := roadmap.DeleteStep(tx, step.ID); err != nil {
+			return err
+		}
+
+		var steps []models.Step
+		if err := tx.Where("project_id = ?", project.ID).Order("step_number").Find(&steps).Error; err != nil {
+			return err
+		}
+		if err := renumberSteps(tx, steps); err != nil {
+			return err
+		}
+		if err := keepLocked(tx, project, locked); err != nil {
+			return err
+		}
+		_, err = roadmap.Record(tx, project.ID, roadmap.SourceUserEdit, userID, nil)
+		return err
+	})
+	if errors.Is(err, errUnlocksSteps) {
+		return respondUnlocksSteps(c)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete step"})
+	}
+
+	return h.respondRoadmap(c, project.ID)
+}
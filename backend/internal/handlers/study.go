@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/gating"
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/study"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultOutlierRatio is how far off the median of its group a step's time
+// must be to be listed in the study time calibration.
+const defaultOutlierRatio = 2.0
+
+// findUserSession loads a study session of the user.
+func (h *Handler) findUserSession(userID uint, sessionID string) (models.StudySession, error) {
+	var session models.StudySession
+	err := h.DB.Where("id = ? AND user_id = ?", sessionID, userID).First(&session).Error
+	return session, err
+}
+
+// StartStudySession starts timing the learner on a step. The client then
+// sends a heartbeat every HeartbeatInterval and stops the session on leaving.
+func (h *Handler) StartStudySession(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	project, err := h.findUserProject(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	step, err := h.findProjectStep(project.ID, c.Param("stepNumber"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Step not found"})
+	}
+	lock, err := gating.StepLock(h.DB, project, step.StepNumber)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check step lock"})
+	}
+	if lock != nil {
+		return respondStepLocked(c, lock)
+	}
+
+	session, err := study.Start(h.DB, userID, step, time.Now())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to start study session"})
+	}
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"session":                    session,
+		"heartbeat_interval_seconds": int(study.HeartbeatInterval.Seconds()),
+	})
+}
+
+// StudyHeartbeat counts the time since the session's previous heartbeat.
+func (h *Handler) StudyHeartbeat(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	session, err := h.findUserSession(userID, c.Param("sessionId"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Study session not found"})
+	}
+	if err := study.Heartbeat(h.DB, &session, time.Now()); err != nil {
+		if errors.Is(err, study.ErrEnded) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Study session has ended"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to record heartbeat"})
+	}
+	return c.JSON(http.StatusOK, session)
+}
+
+// StopStudySession ends the session.
+func (h *Handler) StopStudySession(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	session, err := h.findUserSession(userID, c.Param("sessionId"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Study session not found"})
+	}
+	if err := study.Stop(h.DB, &session, time.Now()); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to stop study session"})
+	}
+	return c.JSON(http.StatusOK, session)
+}
+
+// GetStudyTimeCalibration compares the time learners spend on steps with the
+// difficulty implied by the generated questions.
+func (h *Handler) GetStudyTimeCalibration(c echo.Context) error {
+	ratio := defaultOutlierRatio
+	if v, err := strconv.ParseFloat(c.QueryParam("outlier_ratio"), 64); err == nil && v > 1 {
+		ratio = v
+	}
+
+	groups, outliers, err := study.Calibration(h.DB, ratio)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to compute study time"})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"groups":        groups,
+		"outliers":      outliers,
+		"outlier_ratio": ratio,
+	})
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TemplateRequest is the body for creating or updating a roadmap template.
+// Omitted fields are kept on update.
+type TemplateRequest struct {
+	Title         *string           `json:"title"`
+	Description   *string           `json:"description"`
+	Goal          *string           `json:"goal"`
+	Stack         *string           `json:"stack"`
+	Level         *string           `json:"level"`
+	Locale        *string           `json:"locale"`
+	Tags          *[]string         `json:"tags"`
+	Steps         *[]templates.Step `json:"steps"`           // ステップと問題プール
+	FromProjectID *uint             `json:"from_project_id"` // このプロジェクトのステップと問題をコピーする（steps の代わり）
+	Published     *bool             `json:"published"`
+}
+
+// apply copies the request's fields onto the template and validates the result.
+func (r *TemplateRequest) apply(h *Handler, t *models.RoadmapTemplate) string {
+	if r.Title != nil {
+		t.Title = strings.TrimSpace(*r.Title)
+	}
+	if r.Description != nil {
+		t.Description = strings.TrimSpace(*r.Description)
+	}
+	if r.Goal != nil {
+		t.Goal = strings.TrimSpace(*r.Goal)
+	}
+	if r.Stack != nil {
+		t.Stack = strings.TrimSpace(*r.Stack)
+	}
+	if r.Level != nil {
+		t.Level = strings.TrimSpace(*r.Level)
+	}
+	if r.Locale != nil {
+		t.Locale = strings.TrimSpace(*r.Locale)
+	}
+	if r.Tags != nil {
+		tags, err := templates.JoinTags(*r.Tags)
+		if err != nil {
+			return err.Error()
+		}
+		t.Tags = tags
+	}
+	if r.Published != nil {
+		t.Published = *r.Published
+	}
+
+	steps := templates.StepsOf(*t)
+	switch {
+	case r.FromProjectID != nil:
+		var project models.Project
+		if err := h.DB.First(&project, *r.FromProjectID).Error; err != nil {
+			return "Project not found"
+		}
+		fromProject, err := templates.FromProject(h.DB, project.ID)
+		if err != nil {
+			return "Failed to copy project"
+		}
+		steps = fromProject
+		if r.Goal == nil && t.Goal == "" {
+			t.Goal, t.Stack, t.Level, t.Locale = project.Goal, project.Stack, project.Level, project.Locale
+		}
+	case r.Steps != nil:
+		steps = *r.Steps
+	}
+	if err := templates.Validate(steps); err != nil {
+		return err.Error()
+	}
+	t.Steps, _ = json.Marshal(steps)
+
+	switch {
+	case t.Title == "" || len([]rune(t.Title)) > 200:
+		return "Title must be 1 to 200 characters"
+	case t.Goal == "":
+		return "Goal is required"
+	case t.Locale == "":
+		return "Locale is required"
+	}
+	return ""
+}
+
+// GetTemplates lists the published templates. Query parameters: q (words
+// to search for), tag, level and locale.
+func (h *Handler) GetTemplates(c echo.Context) error {
+	list, err := templates.List(h.DB, templates.Filter{
+		Query:         c.QueryParam("q"),
+		Tag:           c.QueryParam("tag"),
+		Level:         c.QueryParam("level"),
+		Locale:        c.QueryParam("locale"),
+		PublishedOnly: true,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch templates"})
+	}
+	resp := make([]templates.Summary, 0, len(list))
+	for _, t := range list {
+		resp = append(resp, templates.Summarize(t))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetTemplate returns a published template with its steps.
+func (h *Handler) GetTemplate(c echo.Context) error {
+	var t models.RoadmapTemplate
+	if err := h.DB.Where("id = ? AND published = ?", c.Param("id"), true).First(&t).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Template not found"})
+	}
+	return c.JSON(http.StatusOK, templates.Summarize(t))
+}
+
+// CloneTemplate creates a project for the learner from a published template.
+func (h *Handler) CloneTemplate(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	var t models.RoadmapTemplate
+	if err := h.DB.Where("id = ? AND published = ?", c.Param("id"), true).First(&t).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Template not found"})
+	}
+	project, err := templates.Clone(h.DB, t, userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to clone template"})
+	}
+
+	h.DB.Preload("Steps", orderedSteps).First(&project, project.ID)
+	stepsResp := h.roadmapSteps(project)
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"id":            project.ID,
+		"goal":          project.Goal,
+		"stack":         project.Stack,
+		"level":         project.Level,
+		"gating":        projectGating(project),
+		"share":         projectShare(project),
+		"roadmap":       stepsResp,
+		"study_seconds": totalStudySeconds(stepsResp),
+	})
+}
+
+// GetAdminTemplates lists every template, drafts included, with its quiz pools.
+func (h *Handler) GetAdminTemplates(c echo.Context) error {
+	list, err := templates.List(h.DB, templates.Filter{
+		Query:  c.QueryParam("q"),
+		Tag:    c.QueryParam("tag"),
+		Level:  c.QueryParam("level"),
+		Locale: c.QueryParam("locale"),
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch templates"})
+	}
+	resp := make([]templates.Detail, 0, len(list))
+	for _, t := range list {
+		resp = append(resp, templates.Describe(t))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// CreateTemplate adds a template, from the steps in the request or copied
+// from an existing project. Templates are drafts until published.
+func (h *Handler) CreateTemplate(c echo.Context) error {
+	userID := c.Get("userID").(uint)
+
+	req := new(TemplateRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	t := models.RoadmapTemplate{CreatedBy: userID, Locale: "en"}
+	if msg := req.apply(h, &t); msg != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+	}
+	if err := h.DB.Create(&t).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create template"})
+	}
+	return c.JSON(http.StatusCreated, templates.Describe(t))
+}
+
+// UpdateTemplate changes a template. Projects already cloned from it keep
+// their copy.
+func (h *Handler) UpdateTemplate(c echo.Context) error {
+	var t models.RoadmapTemplate
+	if err := h.DB.First(&t, c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Template not found"})
+	}
+
+	req := new(TemplateRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	if msg := req.apply(h, &t); msg != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+	}
+	if err := h.DB.Save(&t).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update template"})
+	}
+	return c.JSON(http.StatusOK, templates.Describe(t))
+}
+
+// DeleteTemplate deletes a template. Projects cloned from it are kept.
+func (h *Handler) DeleteTemplate(c echo.Context) error {
+	result := h.DB.Delete(&models.RoadmapTemplate{}, c.Param("id"))
+	if result.Error != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete template"})
+	}
+	if result.RowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Template not found"})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Template deleted successfully"})
+}
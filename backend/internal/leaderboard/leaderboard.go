@@ -0,0 +1,297 @@
+package leaderboard
+
+import (
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Periods a leaderboard covers. Weeks start on Monday; periods are in UTC
+// so everyone is ranked over the same days.
+const (
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+	PeriodAll   = "all"
+)
+
+// Metrics learners are ranked by
+const (
+	MetricXP    = "xp"
+	MetricScore = "score" // average percentage of completed attempts, remedial ones excluded
+)
+
+const (
+	// MinAttempts is how many attempts a learner needs in the period to be
+	// ranked by score, so that one lucky quiz does not top the board.
+	MinAttempts = 3
+	// DefaultLimit and MaxLimit bound how many learners a board lists.
+	DefaultLimit = 50
+	MaxLimit     = 100
+)
+
+// ValidPeriod reports whether period is a known period.
+func ValidPeriod(period string) bool {
+	return period == PeriodWeek || period == PeriodMonth || period == PeriodAll
+}
+
+// ValidMetric reports whether metric is a known metric.
+func ValidMetric(metric string) bool {
+	return metric == MetricXP || metric == MetricScore
+}
+
+// PeriodStart returns the key of the period containing t.
+func PeriodStart(period string, t time.Time) string {
+	t = t.UTC()
+	switch period {
+	case PeriodWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)).Format("2006-01-02")
+	case PeriodMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	}
+	return PeriodAll
+}
+
+// AddXP adds XP earned at the time to the learner's totals.
+func AddXP(db *gorm.DB, userID uint, xp int, at time.Time) error {
+	if xp == 0 {
+		return nil
+	}
+	return bump(db, userID, at, xp, 0)
+}
+
+// AddAttempt adds a completed attempt's percentage to the learner's totals.
+func AddAttempt(db *gorm.DB, userID uint, percentage int, at time.Time) error {
+	return bump(db, userID, at, 0, percentage)
+}
+
+// AdjustAttempt moves a completed attempt's percentage by delta in the
+// learner's totals, e.g. after a free response was graded.
+func AdjustAttempt(db *gorm.DB, userID uint, delta int, at time.Time) error {
+	if delta == 0 {
+		return nil
+	}
+	for _, period := range []string{PeriodWeek, PeriodMonth, PeriodAll} {
+		err := db.Model(&models.LeaderboardEntry{}).
+			Where("user_id = ? AND period = ? AND period_start = ? AND attempts > 0", userID, period, PeriodStart(period, at)).
+			Updates(map[string]interface{}{
+				"percentage_sum":     gorm.Expr("percentage_sum + ?", delta),
+				"average_percentage": gorm.Expr("(percentage_sum + ?) / attempts", delta),
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bump adds XP or one attempt to the learner's entry of every period
+// containing the time, creating the entries as needed.
+func bump(db *gorm.DB, userID uint, at time.Time, xp, percentage int) error {
+	attempt := xp == 0
+	for _, period := range []string{PeriodWeek, PeriodMonth, PeriodAll} {
+		entry := models.LeaderboardEntry{UserID: userID, Period: period, PeriodStart: PeriodStart(period, at), XP: xp}
+		updates := map[string]interface{}{}
+		if attempt {
+			entry.Attempts = 1
+			entry.PercentageSum = percentage
+			entry.AveragePercentage = percentage
+			// The right-hand sides read the row as it was before the update
+			updates["attempts"] = gorm.Expr("leaderboard_entries.attempts + 1")
+			updates["percentage_sum"] = gorm.Expr("leaderboard_entries.percentage_sum + ?", percentage)
+			updates["average_percentage"] = gorm.Expr("(leaderboard_entries.percentage_sum + ?) / (leaderboard_entries.attempts + 1)", percentage)
+		} else {
+			updates["xp"] = gorm.Expr("leaderboard_entries.xp + ?", xp)
+		}
+
+		err := db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "period"}, {Name: "period_start"}},
+			DoUpdates: clause.Assignments(updates),
+		}).Create(&entry).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rebuild recomputes every entry from the XP ledger and the attempt history.
+func Rebuild(db *gorm.DB) error {
+	type key struct {
+		userID uint
+		period string
+		start  string
+	}
+	totals := make(map[key]*models.LeaderboardEntry)
+	add := func(userID uint, at time.Time, xp, percentage int, attempt bool) {
+		for _, period := range []string{PeriodWeek, PeriodMonth, PeriodAll} {
+			k := key{userID, period, PeriodStart(period, at)}
+			e, ok := totals[k]
+			if !ok {
+				e = &models.LeaderboardEntry{UserID: userID, Period: period, PeriodStart: k.start}
+				totals[k] = e
+			}
+			e.XP += xp
+			if attempt {
+				e.Attempts++
+				e.PercentageSum += percentage
+				e.AveragePercentage = e.PercentageSum / e.Attempts
+			}
+		}
+	}
+
+	var events []models.ActivityEvent
+	err := db.Select("id, user_id, xp, created_at").Where("xp > 0").FindInBatches(&events, 1000, func(tx *gorm.DB, batch int) error {
+		for _, e := range events {
+			add(e.UserID, e.CreatedAt, e.XP, 0, false)
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	var attempts []models.QuizAttempt
+	err = db.Select("id, user_id, percentage, created_at, completed_at").
+		Where("status = ? AND remedial = ?", "completed", false).
+		FindInBatches(&attempts, 1000, func(tx *gorm.DB, batch int) error {
+			for _, a := range attempts {
+				at := a.CreatedAt
+				if a.CompletedAt != nil {
+					at = *a.CompletedAt
+				}
+				add(a.UserID, at, 0, a.Percentage, true)
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	entries := make([]models.LeaderboardEntry, 0, len(totals))
+	for _, e := range totals {
+		entries = append(entries, *e)
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.LeaderboardEntry{}).Error; err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(entries, 500).Error
+	})
+}
+
+// Entry is a learner's place on a board.
+type Entry struct {
+	Rank              int    `json:"rank,omitempty"` // unset for a learner who is hidden or not ranked yet
+	UserID            uint   `json:"user_id"`
+	Username          string `json:"username"`
+	ProfileImage      string `json:"profile_image"`
+	XP                int    `json:"xp"`
+	Attempts          int    `json:"attempts"`
+	AveragePercentage int    `json:"average_percentage"`
+}
+
+// Board is a ranked list of learners for one period and metric.
+type Board struct {
+	Period      string  `json:"period"`
+	PeriodStart string  `json:"period_start"`
+	Metric      string  `json:"metric"`
+	CohortID    *uint   `json:"cohort_id,omitempty"`
+	Entries     []Entry `json:"entries"`
+	Me          *Entry  `json:"me"`     // the requesting learner, even when outside the listed entries
+	Hidden      bool    `json:"hidden"` // the requesting learner hides from leaderboards
+}
+
+// Query selects a board.
+type Query struct {
+	UserID   uint // the requesting learner
+	Period   string
+	Metric   string
+	CohortID uint // 0 for the global board
+	Limit    int
+}
+
+// value returns the entry's value for the metric.
+func value(e Entry, metric string) int {
+	if metric == MetricScore {
+		return e.AveragePercentage
+	}
+	return e.XP
+}
+
+// Get returns the board as of now.
+func Get(db *gorm.DB, q Query, now time.Time) (*Board, error) {
+	board := &Board{Period: q.Period, PeriodStart: PeriodStart(q.Period, now), Metric: q.Metric, Entries: []Entry{}}
+	if q.CohortID != 0 {
+		board.CohortID = &q.CohortID
+	}
+
+	// ranked returns a fresh query over the visible learners who qualify
+	ranked := func() *gorm.DB {
+		tx := db.Table("leaderboard_entries").
+			Joins("JOIN users ON users.id = leaderboard_entries.user_id").
+			Where("leaderboard_entries.period = ? AND leaderboard_entries.period_start = ?", q.Period, board.PeriodStart).
+			Where("users.hide_from_leaderboards = ?", false)
+		if q.CohortID != 0 {
+			tx = tx.Where("leaderboard_entries.user_id IN (?)", db.Model(&models.CohortMember{}).Select("user_id").Where("cohort_id = ?", q.CohortID))
+		}
+		if q.Metric == MetricScore {
+			return tx.Where("leaderboard_entries.attempts >= ?", MinAttempts)
+		}
+		return tx.Where("leaderboard_entries.xp > 0")
+	}
+	columns := "leaderboard_entries.user_id, users.username, users.profile_image, leaderboard_entries.xp, leaderboard_entries.attempts, leaderboard_entries.average_percentage"
+
+	order := "leaderboard_entries.xp DESC, leaderboard_entries.user_id"
+	if q.Metric == MetricScore {
+		order = "leaderboard_entries.average_percentage DESC, leaderboard_entries.attempts DESC, leaderboard_entries.user_id"
+	}
+	if err := ranked().Select(columns).Order(order).Limit(q.Limit).Scan(&board.Entries).Error; err != nil {
+		return nil, err
+	}
+	for i := range board.Entries {
+		if i > 0 && value(board.Entries[i], q.Metric) == value(board.Entries[i-1], q.Metric) {
+			board.Entries[i].Rank = board.Entries[i-1].Rank
+		} else {
+			board.Entries[i].Rank = i + 1
+		}
+	}
+
+	var user models.User
+	if err := db.First(&user, q.UserID).Error; err != nil {
+		return nil, err
+	}
+	board.Hidden = user.HideFromLeaderboards
+	me := Entry{UserID: user.ID, Username: user.Username, ProfileImage: user.ProfileImage}
+	board.Me = &me
+	var mine models.LeaderboardEntry
+	err := db.Where("user_id = ? AND period = ? AND period_start = ?", user.ID, q.Period, board.PeriodStart).Limit(1).Find(&mine).Error
+	if err != nil {
+		return nil, err
+	}
+	me.XP, me.Attempts, me.AveragePercentage = mine.XP, mine.Attempts, mine.AveragePercentage
+
+	qualifies := me.XP > 0
+	if q.Metric == MetricScore {
+		qualifies = me.Attempts >= MinAttempts
+	}
+	if board.Hidden || !qualifies {
+		return board, nil
+	}
+	column := "leaderboard_entries.xp"
+	if q.Metric == MetricScore {
+		column = "leaderboard_entries.average_percentage"
+	}
+	var ahead int64
+	if err := ranked().Where(column+" > ?", value(me, q.Metric)).Count(&ahead).Error; err != nil {
+		return nil, err
+	}
+	me.Rank = int(ahead) + 1
+	return board, nil
+}
@@ -0,0 +1,32 @@
+package leaderboard
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodStart(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	tests := []struct {
+		name   string
+		period string
+		at     time.Time
+		want   string
+	}{
+		{"week from a wednesday", PeriodWeek, time.Date(2025, 3, 12, 15, 0, 0, 0, time.UTC), "2025-03-10"},
+		{"week from a monday", PeriodWeek, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), "2025-03-10"},
+		{"week from a sunday", PeriodWeek, time.Date(2025, 3, 16, 23, 59, 0, 0, time.UTC), "2025-03-10"},
+		{"week across months", PeriodWeek, time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC), "2025-02-24"},
+		{"week in UTC", PeriodWeek, time.Date(2025, 3, 17, 8, 0, 0, 0, tokyo), "2025-03-10"},
+		{"month", PeriodMonth, time.Date(2025, 3, 31, 23, 0, 0, 0, time.UTC), "2025-03-01"},
+		{"month in UTC", PeriodMonth, time.Date(2025, 4, 1, 8, 0, 0, 0, tokyo), "2025-03-01"},
+		{"all time", PeriodAll, time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC), PeriodAll},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PeriodStart(tt.period, tt.at); got != tt.want {
+				t.Errorf("PeriodStart(%q, %v) = %q, want %q", tt.period, tt.at, got, tt.want)
+			}
+		})
+	}
+}
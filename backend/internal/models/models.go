@@ -43,6 +43,28 @@ type GenerateStepQuizRequest struct {
 	StepTitle  string `json:"step_title"`  // ステップタイトル
 	StepDesc   string `json:"step_desc"`   // ステップ説明
 	Locale     string `json:"locale"`      // 言語設定
+	Force      bool   `json:"force"`       // 既存の問題があっても新しい問題を追加する
+}
+
+type RegenerateStepRequest struct {
+	ProjectID         uint   `json:"project_id"`         // 対象プロジェクト
+	StepNumber        int    `json:"step_number"`        // 対象ステップ番号
+	Feedback          string `json:"feedback"`           // ユーザーからの改善要望（任意）
+	InvalidateQuizzes bool   `json:"invalidate_quizzes"` // 既存のクイズを破棄するか
+}
+
+type GradeAnswerRequest struct {
+	AttemptAnswerID uint `json:"attempt_answer_id"` // 採点する記述式の回答
+}
+
+type ExplainMistakeRequest struct {
+	AttemptAnswerID uint `json:"attempt_answer_id"` // 解説する不正解の回答
+}
+
+type RemedialQuizRequest struct {
+	ProjectID  uint `json:"project_id"`  // 対象プロジェクト
+	StepNumber int  `json:"step_number"` // 対象ステップ番号
+	AttemptID  uint `json:"attempt_id"`  // きっかけとなった受験
 }
 
 // --- DB Models ---
@@ -58,17 +80,36 @@ type User struct {
 	SubscriptionStatus string `gorm:"size:50"` // active, past_due, canceled, etc.
 	SubscriptionPlan   string `gorm:"size:50"` // free, pro
 	PasswordHash       string
+	// ReviewCorrectAnswers also schedules correctly answered questions for review
+	ReviewCorrectAnswers bool `gorm:"default:false"`
+	// Timezone is the IANA zone the learner's days (and streak) are counted in
+	Timezone string `gorm:"size:50;default:UTC"`
+	// HideFromLeaderboards keeps the learner off every leaderboard
+	HideFromLeaderboards bool `gorm:"default:false"`
 }
 
 type Project struct {
-	ID        uint `gorm:"primaryKey"`
-	UserID    uint `gorm:"index"`
-	Goal      string
-	Stack     string
-	Level     string
-	Locale    string
-	CreatedAt time.Time
-	Steps     []Step `gorm:"foreignKey:ProjectID"`
+	ID     uint `gorm:"primaryKey"`
+	UserID uint `gorm:"index"`
+	Goal   string
+	Stack  string
+	Level  string
+	Locale string
+	// GatingMode controls when later steps unlock: off, sequential or pass
+	GatingMode     string `gorm:"size:20;default:off"`
+	PassPercentage int    `gorm:"default:70"` // best score needed on each step in pass mode
+	// ImposedGatingMode and ImposedPassPercentage are the gating an admin or
+	// cohort owner set; the learner may tighten it but not go below it.
+	// Empty while nobody imposed gating
+	ImposedGatingMode     string `gorm:"size:20"`
+	ImposedPassPercentage int
+	TemplateID            *uint `gorm:"index"` // template the project was cloned from
+	// ShareSlug is the unguessable part of the public link to the roadmap;
+	// nil while the project is not shared
+	ShareSlug     *string `gorm:"uniqueIndex;size:32"`
+	ShareProgress bool    `gorm:"default:false"` // the public link also shows the learner's progress
+	CreatedAt     time.Time
+	Steps         []Step `gorm:"foreignKey:ProjectID"`
 }
 
 type Step struct {
@@ -77,31 +118,339 @@ type Step struct {
 	StepNumber  int
 	Title       string
 	Description string
+	// UserCreated marks steps the learner added to the roadmap; completing
+	// them earns no step XP
+	UserCreated bool   `gorm:"default:false"`
 	Quizzes     []Quiz `gorm:"foreignKey:StepID"`
 	Score       *Score `gorm:"foreignKey:StepID"`
 }
 
 type Quiz struct {
-	ID          uint `gorm:"primaryKey"`
-	StepID      uint `gorm:"index"`
+	ID          uint   `gorm:"primaryKey"`
+	StepID      uint   `gorm:"index"`
+	Type        string `gorm:"size:30;default:multiple_choice"` // see internal/questions for the types
 	Question    string
+	Code        string // snippet for code_output questions
 	Options     []byte `gorm:"type:json"` // JSON string of options
-	AnswerIndex int
+	AnswerIndex int    // correct option of multiple_choice and true_false questions
+	Answer      []byte `gorm:"type:json"` // answer of the other types (questions.Answer)
 	Explanation string
+	Difficulty  string `gorm:"size:20"`  // easy, medium, hard (empty for legacy quizzes)
+	Topic       string `gorm:"size:255"` // concept tested by the question
+	// Remedial quizzes are generated after a low score and served separately
+	Remedial bool `gorm:"default:false;index"`
+	// Hidden quizzes were reported by several learners and are not served
+	// until a moderator resolves the reports
+	Hidden bool `gorm:"default:false;index"`
 }
 
+// Score is derived from the step's quiz attempts: Score/Total/Percentage hold
+// the latest attempt and the Best* fields the best one.
 type Score struct {
-	ID         uint `gorm:"primaryKey" json:"id"`
-	StepID     uint `gorm:"uniqueIndex" json:"step_id"`
-	Score      int  `json:"score"`
-	Total      int  `json:"total"`
-	Percentage int  `json:"percentage"`
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	StepID         uint      `gorm:"uniqueIndex" json:"step_id"`
+	Score          int       `json:"score"`
+	Total          int       `json:"total"`
+	Percentage     int       `json:"percentage"`
+	BestScore      int       `json:"best_score"`
+	BestTotal      int       `json:"best_total"`
+	BestPercentage int       `json:"best_percentage"`
+	AttemptCount   int       `json:"attempt_count"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type QuizAttempt struct {
+	ID              uint            `gorm:"primaryKey" json:"id"`
+	UserID          uint            `gorm:"index" json:"user_id"`
+	StepID          uint            `gorm:"index" json:"step_id"`
+	Status          string          `gorm:"size:20;default:completed" json:"status"` // in_progress, completed, abandoned
+	Remedial        bool            `gorm:"default:false" json:"remedial"`           // served the step's remedial quizzes
+	Score           int             `json:"score"`
+	Total           int             `json:"total"`
+	Percentage      int             `json:"percentage"`
+	DurationSeconds int             `json:"duration_seconds"`
+	CreatedAt       time.Time       `json:"created_at"` // when the attempt was started
+	CompletedAt     *time.Time      `json:"completed_at,omitempty"`
+	Answers         []AttemptAnswer `gorm:"foreignKey:AttemptID" json:"answers,omitempty"`
+}
+
+// AttemptAnswer is one question served in an attempt, created unanswered
+// when the attempt starts and graded by the server once answered.
+type AttemptAnswer struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	AttemptID   uint   `gorm:"index" json:"attempt_id"`
+	QuizID      uint   `gorm:"index" json:"quiz_id"`
+	Position    int    `json:"position"`           // order in which the question was served
+	OptionOrder []byte `gorm:"type:json" json:"-"` // displayed option index -> index into Quiz.Options
+	ChosenIndex int    `json:"chosen_index"`       // index into Quiz.Options, -1 if unanswered or not a single-choice question
+	Response    []byte `gorm:"type:json" json:"-"` // graded response (questions.Response), in Quiz.Options indexes
+	// Free responses are graded by the model against the rubric; empty for other types
+	GradingStatus  string     `gorm:"size:20" json:"grading_status,omitempty"` // pending, graded, failed, overridden
+	Points         int        `json:"points"`
+	MaxPoints      int        `json:"max_points"`
+	Feedback       string     `json:"feedback,omitempty"`
+	CriteriaScores []byte     `gorm:"type:json" json:"-"` // per-criterion points and comments
+	IsCorrect      bool       `json:"is_correct"`
+	TimeTakenMs    int        `json:"time_taken_ms"` // 0 when unknown (e.g. batch submissions)
+	AnsweredAt     *time.Time `json:"answered_at,omitempty"`
+}
+
+type RoadmapRevision struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ProjectID uint      `gorm:"uniqueIndex:idx_project_revision" json:"project_id"`
+	Number    int       `gorm:"uniqueIndex:idx_project_revision" json:"number"`
+	Source    string    `gorm:"size:20" json:"source"` // generate, user_edit, regenerate, import, revert
+	UserID    uint      `json:"user_id"`
+	JobID     *uint     `json:"job_id,omitempty"`
+	Steps     []byte    `gorm:"type:json" json:"-"` // JSON snapshot of the steps
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReviewCard is the spaced-repetition state of one question for one learner.
+type ReviewCard struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	UserID         uint       `gorm:"uniqueIndex:idx_review_user_quiz" json:"user_id"`
+	QuizID         uint       `gorm:"uniqueIndex:idx_review_user_quiz" json:"quiz_id"`
+	EaseFactor     float64    `json:"ease_factor"`
+	IntervalDays   int        `json:"interval_days"`
+	Repetitions    int        `json:"repetitions"`
+	Lapses         int        `json:"lapses"`
+	DueAt          time.Time  `gorm:"index" json:"due_at"`
+	OptionOrder    []byte     `gorm:"type:json" json:"-"` // displayed option index -> index into Quiz.Options
+	LastReviewedAt *time.Time `json:"last_reviewed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// MistakeExplanation explains why a wrong response to a question is wrong.
+// It is generated once per question, response and language and reused for
+// every learner who gives the same response.
+type MistakeExplanation struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	QuizID      uint      `gorm:"uniqueIndex:idx_mistake_response" json:"quiz_id"`
+	ResponseKey string    `gorm:"size:255;uniqueIndex:idx_mistake_response" json:"-"` // questions.ResponseKey of the wrong response
+	Locale      string    `gorm:"size:10;uniqueIndex:idx_mistake_response" json:"locale"`
+	Explanation string    `json:"explanation"`
+	Concept     string    `json:"concept"` // concept to revisit
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// GradeAppeal asks a human reviewer to override the model's grade of a free response.
+type GradeAppeal struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	AttemptAnswerID uint       `gorm:"index" json:"attempt_answer_id"`
+	UserID          uint       `gorm:"index" json:"user_id"`
+	Reason          string     `json:"reason"`
+	Status          string     `gorm:"size:20;default:open;index" json:"status"` // open, accepted, rejected
+	OriginalPoints  int        `json:"original_points"`
+	ResolvedPoints  *int       `json:"resolved_points,omitempty"`
+	ReviewerID      *uint      `json:"reviewer_id,omitempty"`
+	ReviewerNote    string     `json:"reviewer_note,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty"`
+}
+
+// QuizReport is a learner's report of a problem with a question, reviewed
+// by a moderator.
+type QuizReport struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	QuizID       uint       `gorm:"index" json:"quiz_id"`
+	UserID       uint       `gorm:"index" json:"user_id"`
+	Category     string     `gorm:"size:30" json:"category"` // see internal/moderation for the categories
+	Comment      string     `json:"comment"`
+	Status       string     `gorm:"size:20;default:open;index" json:"status"` // open, fixed, deleted, dismissed
+	ReviewerID   *uint      `json:"reviewer_id,omitempty"`
+	ReviewerNote string     `json:"reviewer_note,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+}
+
+// Conversation is a learner's chat with the tutor about one step.
+type Conversation struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index" json:"user_id"`
+	ProjectID uint      `gorm:"index" json:"project_id"`
+	StepID    uint      `gorm:"index" json:"step_id"`
+	Title     string    `gorm:"size:100" json:"title"` // start of the first message
+	Messages  []Message `gorm:"foreignKey:ConversationID" json:"messages,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Message struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	ConversationID uint      `gorm:"index" json:"conversation_id"`
+	Role           string    `gorm:"size:20" json:"role"` // user, assistant
+	Content        string    `json:"content"`
+	CreatedAt      time.Time `gorm:"index" json:"created_at"`
+}
+
+// UsageRecord counts a user's daily use of a metric limited by their plan
+// (see the quota package). It is kept when the counted rows are deleted.
+type UsageRecord struct {
+	ID     uint   `gorm:"primaryKey"`
+	UserID uint   `gorm:"uniqueIndex:idx_usage_user_metric_day"`
+	Metric string `gorm:"size:50;uniqueIndex:idx_usage_user_metric_day"`
+	Day    string `gorm:"size:10;uniqueIndex:idx_usage_user_metric_day"` // UTC date, YYYY-MM-DD
+	Count  int
+}
+
+// Certificate is issued once every step of a project has a passing score.
+// The details are signed, so a copy can be checked against the record; the
+// PDF is rendered once at issue time and its hash kept with the record.
+type Certificate struct {
+	ID   uint   `gorm:"primaryKey" json:"id"`
+	Code string `gorm:"uniqueIndex;size:32" json:"code"` // verification code
+	// A project has one certificate; the unique index keeps concurrent
+	// issues from creating a second one
+	UserID            uint       `gorm:"index;uniqueIndex:idx_certificates_project_user,priority:2" json:"user_id,omitempty"`
+	ProjectID         uint       `gorm:"uniqueIndex:idx_certificates_project_user,priority:1" json:"project_id,omitempty"`
+	LearnerName       string     `gorm:"size:100" json:"learner_name"`
+	Goal              string     `json:"goal"`
+	Stack             string     `json:"stack"`
+	Locale            string     `gorm:"size:10" json:"locale"`
+	Scores            []byte     `gorm:"type:json" json:"-"` // best score of each step (certificate.StepScore)
+	AveragePercentage int        `json:"average_percentage"`
+	IssuedAt          time.Time  `json:"issued_at"`
+	Signature         string     `gorm:"size:100" json:"signature"` // base64 Ed25519 signature of the details
+	PDFHash           string     `gorm:"size:64" json:"pdf_sha256"`
+	PDF               []byte     `json:"-"`
+	RevokedAt         *time.Time `json:"revoked_at,omitempty"` // set when the project is deleted
+}
+
+// XPRule is how much XP an activity event awards. Rules live in the
+// database so they can be tuned without a deploy.
+type XPRule struct {
+	Event    string `gorm:"primaryKey;size:50" json:"event"` // see internal/activity for the events
+	XP       int    `json:"xp"`
+	DailyCap int    `json:"daily_cap"` // most XP the event awards per day, 0 for no cap
+	Enabled  bool   `json:"enabled"`
+}
+
+// ActivityEvent is one entry of the XP ledger. The source identifies what
+// earned it, so the same attempt or review is never awarded twice.
+type ActivityEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_activity_user_event_source;index:idx_activity_user_day" json:"user_id"`
+	Event     string    `gorm:"size:50;uniqueIndex:idx_activity_user_event_source" json:"event"`
+	Source    string    `gorm:"size:100;uniqueIndex:idx_activity_user_event_source" json:"source"` // e.g. attempt:12
+	XP        int       `json:"xp"`
+	Day       string    `gorm:"size:10;index:idx_activity_user_day" json:"day"` // the learner's local date, YYYY-MM-DD
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Streak is a learner's run of consecutive active days. Freezes cover
+// missed days so that the run is kept.
+type Streak struct {
+	UserID        uint      `gorm:"primaryKey" json:"user_id"`
+	Current       int       `json:"current"`
+	Longest       int       `json:"longest"`
+	LastActiveDay string    `gorm:"size:10" json:"last_active_day"` // local date, YYYY-MM-DD
+	Freezes       int       `json:"freezes"`                        // freezes available
+	FreezesUsed   int       `json:"freezes_used"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Badge is an achievement awarded once a learner's metric reaches the
+// threshold. Definitions live in the database so admins can add badges
+// without a deploy; see internal/badges for the metrics and the defaults.
+type Badge struct {
+	Key           string `gorm:"primaryKey;size:50" json:"key"`
+	Name          string `gorm:"size:100" json:"name"`
+	NameJa        string `gorm:"size:100" json:"name_ja"`
+	Description   string `json:"description"`
+	DescriptionJa string `json:"description_ja"`
+	Metric        string `gorm:"size:50" json:"metric"`
+	Threshold     int    `json:"threshold"`
+	Filter        string `gorm:"size:200" json:"filter"` // comma-separated keywords narrowing the metric, e.g. security
+	Enabled       bool   `json:"enabled"`
+}
+
+// UserBadge records when a learner earned a badge.
+type UserBadge struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_user_badge" json:"user_id"`
+	BadgeKey  string    `gorm:"size:50;uniqueIndex:idx_user_badge" json:"badge_key"`
+	AwardedAt time.Time `json:"awarded_at"`
+}
+
+// LeaderboardEntry is a learner's running totals for one leaderboard period.
+// It is updated as XP and attempts come in, so boards never scan the history.
+type LeaderboardEntry struct {
+	UserID            uint   `gorm:"primaryKey" json:"user_id"`
+	Period            string `gorm:"primaryKey;size:10;index:idx_leaderboard_xp,priority:1;index:idx_leaderboard_score,priority:1" json:"period"`       // week, month, all
+	PeriodStart       string `gorm:"primaryKey;size:10;index:idx_leaderboard_xp,priority:2;index:idx_leaderboard_score,priority:2" json:"period_start"` // YYYY-MM-DD in UTC, "all" for all time
+	XP                int    `gorm:"index:idx_leaderboard_xp,priority:3" json:"xp"`
+	Attempts          int    `json:"attempts"`
+	PercentageSum     int    `json:"-"`
+	AveragePercentage int    `gorm:"index:idx_leaderboard_score,priority:3" json:"average_percentage"`
+}
+
+// Cohort is a group of learners with its own leaderboards.
+type Cohort struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"size:100" json:"name"`
+	Code      string    `gorm:"uniqueIndex;size:16" json:"code"` // shared with learners to join
+	OwnerID   uint      `gorm:"index" json:"owner_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CohortMember struct {
+	CohortID uint      `gorm:"primaryKey" json:"cohort_id"`
+	UserID   uint      `gorm:"primaryKey;index" json:"user_id"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// StudySession is a stretch of time a learner spent on a step. Seconds only
+// counts the time covered by heartbeats, so idle time is left out.
+type StudySession struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	UserID          uint       `gorm:"index" json:"user_id"`
+	ProjectID       uint       `gorm:"index" json:"project_id"`
+	StepID          uint       `gorm:"index" json:"step_id"`
+	StartedAt       time.Time  `json:"started_at"`
+	LastHeartbeatAt time.Time  `json:"last_heartbeat_at"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"`
+	Seconds         int        `json:"seconds"`
+}
+
+// Note is a learner's personal Markdown note on a step.
+type Note struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index" json:"user_id"`
+	ProjectID uint      `gorm:"index" json:"project_id"`
+	StepID    uint      `gorm:"index" json:"step_id"`
+	Body      string    `gorm:"type:text" json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type NoteRequest struct {
+	Body string `json:"body"` // Markdown本文
+}
+
+// RoadmapTemplate is a vetted roadmap published by admins. Learners clone it
+// into a project of their own without a generation job.
+type RoadmapTemplate struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Goal        string    `json:"goal"`
+	Stack       string    `json:"stack"`
+	Level       string    `gorm:"size:20;index" json:"level"`
+	Locale      string    `gorm:"size:10;index" json:"locale"`
+	Tags        string    `json:"-"`                  // comma-separated, lowercase
+	Steps       []byte    `gorm:"type:json" json:"-"` // templates.Step list with the quiz pools
+	Published   bool      `gorm:"default:false;index" json:"published"`
+	CloneCount  int       `json:"clone_count"`
+	CreatedBy   uint      `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 type Job struct {
 	ID        uint   `gorm:"primaryKey"`
 	UserID    uint   `gorm:"index"`                   // Added UserID
-	Type      string `gorm:"size:50"`                 // propose_plan, generate_roadmap, generate_quiz
+	Type      string `gorm:"size:50"`                 // propose_plan, generate_roadmap, generate_quiz, regenerate_step, generate_remedial_quiz, grade_free_response, explain_mistake
 	Status    string `gorm:"size:20;default:pending"` // pending, processing, completed, failed
 	Input     []byte `gorm:"type:json"`
 	Result    []byte `gorm:"type:json"`
@@ -0,0 +1,76 @@
+package moderation
+
+import (
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Report categories
+const (
+	CategoryWrongAnswer = "wrong_answer" // the marked answer is wrong
+	CategoryUnclear     = "unclear"      // the question or options are ambiguous
+	CategoryTypo        = "typo"         // spelling or formatting mistakes
+	CategoryOffTopic    = "off_topic"    // unrelated to the step
+	CategoryOther       = "other"
+)
+
+// ValidCategory reports whether c is a known report category.
+func ValidCategory(c string) bool {
+	switch c {
+	case CategoryWrongAnswer, CategoryUnclear, CategoryTypo, CategoryOffTopic, CategoryOther:
+		return true
+	}
+	return false
+}
+
+// HideThreshold is the number of learners with open reports on a question
+// at which it is hidden until a moderator resolves them.
+const HideThreshold = 3
+
+// Report resolutions
+const (
+	StatusOpen      = "open"
+	StatusFixed     = "fixed"     // the question was corrected
+	StatusDeleted   = "deleted"   // the question was removed
+	StatusDismissed = "dismissed" // nothing was wrong
+)
+
+// RefreshHidden hides a quiz once enough learners have open reports on it.
+// It returns whether the quiz is hidden.
+func RefreshHidden(db *gorm.DB, quizID uint) (bool, error) {
+	var reporters int64
+	if err := db.Model(&models.QuizReport{}).Where("quiz_id = ? AND status = ?", quizID, StatusOpen).Distinct("user_id").Count(&reporters).Error; err != nil {
+		return false, err
+	}
+	hidden := reporters >= HideThreshold
+	if hidden {
+		if err := db.Model(&models.Quiz{}).Where("id = ?", quizID).Update("hidden", true).Error; err != nil {
+			return false, err
+		}
+	}
+	return hidden, nil
+}
+
+// Resolve closes every open report on a quiz with the given status and
+// shows the quiz again. It returns the number of reports resolved.
+func Resolve(db *gorm.DB, quizID uint, status string, reviewerID uint, note string) (int64, error) {
+	now := time.Now()
+	result := db.Model(&models.QuizReport{}).Where("quiz_id = ? AND status = ?", quizID, StatusOpen).Updates(map[string]interface{}{
+		"status":        status,
+		"reviewer_id":   reviewerID,
+		"reviewer_note": note,
+		"resolved_at":   now,
+	})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if status != StatusDeleted {
+		if err := db.Model(&models.Quiz{}).Where("id = ?", quizID).Update("hidden", false).Error; err != nil {
+			return 0, err
+		}
+	}
+	return result.RowsAffected, nil
+}
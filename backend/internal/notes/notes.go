@@ -0,0 +1,87 @@
+package notes
+
+import (
+	"strings"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/search"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxBodyLength is the longest note body, in characters.
+	MaxBodyLength = 20000
+	// DefaultLimit and MaxLimit bound the number of search results.
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// index is the full-text index over note bodies.
+var index = search.Index{Table: "notes", Columns: []string{"body"}}
+
+// Setup creates the full-text index over note bodies.
+func Setup(db *gorm.DB) error {
+	return search.Create(db, index)
+}
+
+// Result is a note matching a search, with where it belongs.
+type Result struct {
+	ID          uint      `json:"id"`
+	ProjectID   uint      `json:"project_id"`
+	ProjectGoal string    `json:"project_goal"`
+	StepID      uint      `json:"step_id"`
+	StepNumber  int       `json:"step_number"`
+	StepTitle   string    `json:"step_title"`
+	Body        string    `json:"body"`
+	Snippet     string    `json:"snippet"` // matches wrapped in [ ]
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// resultColumns are selected by every search query.
+const resultColumns = `notes.id, notes.project_id, projects.goal AS project_goal, notes.step_id,
+	steps.step_number, steps.title AS step_title, notes.body, notes.created_at, notes.updated_at`
+
+// base starts a query over the user's notes joined with their step and project.
+func base(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Table("notes").
+		Joins("JOIN steps ON steps.id = notes.step_id").
+		Joins("JOIN projects ON projects.id = notes.project_id").
+		Where("notes.user_id = ?", userID)
+}
+
+// Search finds the user's notes matching all terms of the query, best
+// matches first.
+func Search(db *gorm.DB, userID uint, query string, limit int) ([]Result, error) {
+	terms := strings.Fields(query)
+	results := []Result{}
+	if len(terms) == 0 {
+		return results, nil
+	}
+
+	var err error
+	switch {
+	case db.Dialector.Name() == "postgres":
+		q := base(db, userID).
+			Select(resultColumns+`, ts_headline('simple', notes.body, plainto_tsquery('simple', ?),
+				'StartSel=[, StopSel=], MaxFragments=1, MaxWords=20, MinWords=5') AS snippet`, query).
+			Where("notes.search @@ plainto_tsquery('simple', ?)", query).
+			Order(gorm.Expr("ts_rank(notes.search, plainto_tsquery('simple', ?)) DESC", query))
+		err = q.Limit(limit).Scan(&results).Error
+	case search.UseFTS(db, index, terms):
+		q := base(db, userID).
+			Select(resultColumns+", snippet(notes_fts, 0, '[', ']', '…', 64) AS snippet").
+			Joins("JOIN notes_fts ON notes_fts.rowid = notes.id").
+			Where("notes_fts MATCH ?", search.MatchExpr(terms)).
+			Order("notes_fts.rank")
+		err = q.Limit(limit).Scan(&results).Error
+	default:
+		q := search.LikeAny(base(db, userID).Select(resultColumns), []string{"notes.body"}, terms)
+		err = q.Order("notes.updated_at DESC").Limit(limit).Scan(&results).Error
+		for i := range results {
+			results[i].Snippet = search.Excerpt(results[i].Body, terms)
+		}
+	}
+	return results, err
+}
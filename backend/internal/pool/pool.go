@@ -0,0 +1,166 @@
+package pool
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github/meso1007/reverse-learn/backend/internal/adaptive"
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Size is the most questions a step's pool grows to.
+	Size = 30
+	// BatchSize is how many questions one generation adds to the pool.
+	BatchSize = 10
+	// AttemptSize is how many questions of the pool an attempt asks.
+	AttemptSize = 10
+)
+
+// duplicateThreshold is the bigram similarity from which two questions are
+// considered the same question reworded.
+const duplicateThreshold = 0.6
+
+// Quizzes returns the questions of a step's pool (remedial questions are not
+// part of it), hidden ones included.
+func Quizzes(db *gorm.DB, stepID uint) ([]models.Quiz, error) {
+	var quizzes []models.Quiz
+	err := db.Where("step_id = ? AND remedial = ?", stepID, false).Order("id").Find(&quizzes).Error
+	return quizzes, err
+}
+
+// Room returns how many questions can still be added to a pool.
+// Hidden questions do not take up room.
+func Room(quizzes []models.Quiz) int {
+	room := Size
+	for _, q := range quizzes {
+		if !q.Hidden {
+			room--
+		}
+	}
+	if room < 0 {
+		return 0
+	}
+	return room
+}
+
+// Text is what two questions are compared on: the question with its code.
+func Text(q models.Quiz) string {
+	if q.Code == "" {
+		return q.Question
+	}
+	return q.Question + "\n" + q.Code
+}
+
+// bigrams returns the set of character pairs of the text, ignoring case,
+// spaces and punctuation. Characters rather than words are used so that
+// Japanese text is compared the same way.
+func bigrams(text string) map[string]bool {
+	var runes []rune
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			runes = append(runes, r)
+		}
+	}
+	set := make(map[string]bool)
+	if len(runes) == 1 {
+		set[string(runes)] = true
+	}
+	for i := 0; i+1 < len(runes); i++ {
+		set[string(runes[i:i+2])] = true
+	}
+	return set
+}
+
+// NearDuplicate reports whether two question texts are (almost) the same.
+func NearDuplicate(a, b string) bool {
+	setA, setB := bigrams(a), bigrams(b)
+	if len(setA) == 0 || len(setB) == 0 {
+		return len(setA) == len(setB)
+	}
+	shared := 0
+	for g := range setA {
+		if setB[g] {
+			shared++
+		}
+	}
+	union := len(setA) + len(setB) - shared
+	return float64(shared)/float64(union) >= duplicateThreshold
+}
+
+// Duplicates reports whether the text is a near duplicate of any of the others.
+func Duplicates(text string, others []string) bool {
+	for _, o := range others {
+		if NearDuplicate(text, o) {
+			return true
+		}
+	}
+	return false
+}
+
+// Served counts how often each question of the step has been served to the user.
+func Served(db *gorm.DB, userID, stepID uint) (map[uint]int, error) {
+	var rows []struct {
+		QuizID uint
+		Count  int
+	}
+	err := db.Table("attempt_answers").
+		Select("attempt_answers.quiz_id, COUNT(*) AS count").
+		Joins("JOIN quiz_attempts ON quiz_attempts.id = attempt_answers.attempt_id").
+		Where("quiz_attempts.user_id = ? AND quiz_attempts.step_id = ?", userID, stepID).
+		Group("attempt_answers.quiz_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	served := make(map[uint]int, len(rows))
+	for _, r := range rows {
+		served[r.QuizID] = r.Count
+	}
+	return served, nil
+}
+
+// Sample picks n questions of the pool following the difficulty mix,
+// preferring the questions served least often. Questions without a
+// difficulty, or from a difficulty with too few questions, fill the rest.
+// The picked questions keep their order in the pool.
+func Sample(quizzes []models.Quiz, served map[uint]int, mix map[string]int, n int) []models.Quiz {
+	if len(quizzes) <= n {
+		return quizzes
+	}
+
+	// Least served first, in random order among equals
+	candidates := make([]models.Quiz, len(quizzes))
+	copy(candidates, quizzes)
+	rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return served[candidates[i].ID] < served[candidates[j].ID]
+	})
+
+	want := adaptive.ScaleMix(mix, n)
+	picked := make(map[uint]bool, n)
+	for _, q := range candidates {
+		if want[q.Difficulty] > 0 {
+			want[q.Difficulty]--
+			picked[q.ID] = true
+		}
+	}
+	for _, q := range candidates {
+		if len(picked) == n {
+			break
+		}
+		picked[q.ID] = true
+	}
+
+	sample := make([]models.Quiz, 0, n)
+	for _, q := range quizzes {
+		if picked[q.ID] {
+			sample = append(sample, q)
+		}
+	}
+	return sample
+}
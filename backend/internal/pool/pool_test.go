@@ -0,0 +1,83 @@
+package pool
+
+import (
+	"testing"
+
+	"github/meso1007/reverse-learn/backend/internal/adaptive"
+	"github/meso1007/reverse-learn/backend/internal/models"
+)
+
+func pool(difficulties ...string) []models.Quiz {
+	quizzes := make([]models.Quiz, len(difficulties))
+	for i, d := range difficulties {
+		quizzes[i] = models.Quiz{ID: uint(i + 1), Difficulty: d}
+	}
+	return quizzes
+}
+
+func TestSampleSmallPool(t *testing.T) {
+	quizzes := pool("easy", "hard")
+	if got := Sample(quizzes, nil, nil, 10); len(got) != 2 {
+		t.Errorf("Sample returned %d questions, want the whole pool", len(got))
+	}
+}
+
+func TestSample(t *testing.T) {
+	const (
+		easy   = adaptive.DifficultyEasy
+		medium = adaptive.DifficultyMedium
+		hard   = adaptive.DifficultyHard
+	)
+	quizzes := pool(easy, easy, easy, easy, medium, medium, medium, medium, hard, hard, hard, hard, "")
+	// Every question but 2, 6 and 10 has been served before
+	served := map[uint]int{1: 3, 3: 1, 4: 2, 5: 1, 7: 1, 8: 1, 9: 4, 11: 1, 12: 1, 13: 1}
+	mix := map[string]int{easy: 1, medium: 1, hard: 1}
+
+	for i := 0; i < 50; i++ {
+		got := Sample(quizzes, served, mix, 6)
+		if len(got) != 6 {
+			t.Fatalf("Sample returned %d questions, want 6", len(got))
+		}
+
+		counts := map[string]int{}
+		ids := map[uint]bool{}
+		for j, q := range got {
+			counts[q.Difficulty]++
+			ids[q.ID] = true
+			if j > 0 && got[j-1].ID >= q.ID {
+				t.Fatalf("sample %v is not in pool order", got)
+			}
+		}
+		if counts[easy] != 2 || counts[medium] != 2 || counts[hard] != 2 {
+			t.Fatalf("sample difficulties = %v, want 2 of each", counts)
+		}
+		for _, id := range []uint{2, 6, 10} {
+			if !ids[id] {
+				t.Fatalf("unserved question %d was not picked", id)
+			}
+		}
+		for _, id := range []uint{1, 9} {
+			if ids[id] {
+				t.Fatalf("most served question %d was picked", id)
+			}
+		}
+	}
+}
+
+func TestNearDuplicate(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"What does defer do?", "what does DEFER do", true},
+		{"What does defer do?", "What is a goroutine?", false},
+		{"deferの役割は？", "deferの役割は何？", true},
+		{"", "", true},
+		{"?", "What?", false},
+	}
+	for _, tt := range tests {
+		if got := NearDuplicate(tt.a, tt.b); got != tt.want {
+			t.Errorf("NearDuplicate(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package progress
+
+import (
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/study"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// trendWeeks is how many weeks of scores the trend covers.
+	trendWeeks = 8
+	// trendWindow is how many attempts on each side are compared for the trend direction.
+	trendWindow = 5
+	// trendMargin is the change in percentage points that counts as improving or declining.
+	trendMargin = 5
+	// maxWeakTopics limits how many weak topics are listed.
+	maxWeakTopics = 5
+	// minTopicAnswers is how many answers a topic needs before it is judged.
+	minTopicAnswers = 2
+)
+
+// Trend directions
+const (
+	TrendUp   = "up"
+	TrendDown = "down"
+	TrendFlat = "flat"
+)
+
+// ProjectProgress is how far the learner is in one project.
+type ProjectProgress struct {
+	ID                   uint       `json:"id"`
+	Goal                 string     `json:"goal"`
+	Stack                string     `json:"stack"`
+	Locale               string     `json:"locale"`
+	StepsTotal           int        `json:"steps_total"`
+	StepsCompleted       int        `json:"steps_completed"`
+	CompletionPercentage int        `json:"completion_percentage"`
+	AveragePercentage    *int       `json:"average_percentage"` // latest score averaged over completed steps
+	LastActivityAt       *time.Time `json:"last_activity_at"`
+	StudySeconds         int        `json:"study_seconds"`
+	CreatedAt            time.Time  `json:"created_at"`
+}
+
+// WeekScore is the average score of the attempts completed in a week.
+type WeekScore struct {
+	WeekStart         time.Time `json:"week_start"` // Monday, UTC
+	Attempts          int       `json:"attempts"`
+	AveragePercentage *int      `json:"average_percentage"`
+}
+
+// Trend tells whether recent scores are better than earlier ones.
+type Trend struct {
+	Direction string      `json:"direction"` // up, down, flat
+	Change    int         `json:"change"`    // recent minus earlier average, in percentage points
+	Weeks     []WeekScore `json:"weeks"`     // oldest first
+}
+
+// Topic is a concept the learner answers wrongly most often.
+type Topic struct {
+	Topic       string `json:"topic"`
+	Answered    int    `json:"answered"`
+	Wrong       int    `json:"wrong"`
+	CorrectRate int    `json:"correct_rate"` // percentage
+}
+
+// Progress aggregates the learner's results across all projects.
+type Progress struct {
+	Projects                 []ProjectProgress `json:"projects"`
+	StepsTotal               int               `json:"steps_total"`
+	StepsCompleted           int               `json:"steps_completed"`
+	AttemptsCompleted        int               `json:"attempts_completed"`
+	StudySeconds             int               `json:"study_seconds"`      // time spent on steps, over all projects
+	AveragePercentage        *int              `json:"average_percentage"` // over all completed attempts
+	Trend                    Trend             `json:"trend"`
+	LastActivityAt           *time.Time        `json:"last_activity_at"`
+	SecondsSinceLastActivity *int64            `json:"seconds_since_last_activity"`
+	WeakTopics               []Topic           `json:"weak_topics"`
+}
+
+// average returns the rounded average, or nil without values.
+func average(sum, n int) *int {
+	if n == 0 {
+		return nil
+	}
+	avg := (sum + n/2) / n
+	return &avg
+}
+
+// later returns the later of two optional times.
+func later(a, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.After(*a)) {
+		return b
+	}
+	return a
+}
+
+// weekStart returns the Monday starting the week of t, in UTC.
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// Summarize computes the learner's progress as of now.
+func Summarize(db *gorm.DB, userID uint, now time.Time) (*Progress, error) {
+	p := &Progress{Projects: []ProjectProgress{}, WeakTopics: []Topic{}}
+
+	var projects []models.Project
+	if err := db.Where("user_id = ?", userID).Order("created_at desc").Find(&projects).Error; err != nil {
+		return nil, err
+	}
+
+	// Steps of every project with their score, in one query
+	var steps []struct {
+		ID         uint
+		ProjectID  uint
+		Percentage *int
+	}
+	err := db.Table("steps").
+		Select("steps.id, steps.project_id, scores.percentage").
+		Joins("JOIN projects ON projects.id = steps.project_id").
+		Joins("LEFT JOIN scores ON scores.step_id = steps.id").
+		Where("projects.user_id = ?", userID).
+		Scan(&steps).Error
+	if err != nil {
+		return nil, err
+	}
+	stepProject := make(map[uint]uint, len(steps))
+	totals := make(map[uint][2]int) // project -> steps, completed
+	sums := make(map[uint]int)
+	for _, s := range steps {
+		stepProject[s.ID] = s.ProjectID
+		t := totals[s.ProjectID]
+		t[0]++
+		if s.Percentage != nil {
+			t[1]++
+			sums[s.ProjectID] += *s.Percentage
+		}
+		totals[s.ProjectID] = t
+	}
+
+	// Completed attempts, oldest first, for the averages and the trend
+	var attempts []models.QuizAttempt
+	err = db.Where("user_id = ? AND status = ? AND remedial = ?", userID, "completed", false).
+		Order("COALESCE(completed_at, created_at), id").
+		Find(&attempts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Latest activity per project: attempts started or completed, answers given, time studied
+	lastByProject := make(map[uint]*time.Time)
+	var allAttempts []models.QuizAttempt
+	if err := db.Select("id, step_id, created_at, completed_at").Where("user_id = ?", userID).Find(&allAttempts).Error; err != nil {
+		return nil, err
+	}
+	attemptStep := make(map[uint]uint, len(allAttempts))
+	for _, a := range allAttempts {
+		attemptStep[a.ID] = a.StepID
+		created := a.CreatedAt
+		last := later(&created, a.CompletedAt)
+		pid := stepProject[a.StepID]
+		lastByProject[pid] = later(lastByProject[pid], last)
+		p.LastActivityAt = later(p.LastActivityAt, last)
+	}
+	var answered []models.AttemptAnswer
+	err = db.Select("attempt_answers.attempt_id, attempt_answers.answered_at").
+		Joins("JOIN quiz_attempts ON quiz_attempts.id = attempt_answers.attempt_id").
+		Where("quiz_attempts.user_id = ? AND attempt_answers.answered_at IS NOT NULL", userID).
+		Find(&answered).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range answered {
+		pid := stepProject[attemptStep[a.AttemptID]]
+		lastByProject[pid] = later(lastByProject[pid], a.AnsweredAt)
+		p.LastActivityAt = later(p.LastActivityAt, a.AnsweredAt)
+	}
+	var sessions []models.StudySession
+	if err := db.Select("project_id, last_heartbeat_at").Where("user_id = ?", userID).Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	for _, s := range sessions {
+		last := s.LastHeartbeatAt
+		lastByProject[s.ProjectID] = later(lastByProject[s.ProjectID], &last)
+		p.LastActivityAt = later(p.LastActivityAt, &last)
+	}
+	var lastReview models.ReviewCard
+	db.Where("user_id = ? AND last_reviewed_at IS NOT NULL", userID).Order("last_reviewed_at desc").Limit(1).Find(&lastReview)
+	p.LastActivityAt = later(p.LastActivityAt, lastReview.LastReviewedAt)
+	if p.LastActivityAt != nil {
+		since := int64(now.Sub(*p.LastActivityAt).Seconds())
+		p.SecondsSinceLastActivity = &since
+	}
+
+	studySeconds, err := study.ProjectSeconds(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, project := range projects {
+		t := totals[project.ID]
+		pp := ProjectProgress{
+			ID:                project.ID,
+			Goal:              project.Goal,
+			Stack:             project.Stack,
+			Locale:            project.Locale,
+			StepsTotal:        t[0],
+			StepsCompleted:    t[1],
+			AveragePercentage: average(sums[project.ID], t[1]),
+			LastActivityAt:    lastByProject[project.ID],
+			StudySeconds:      studySeconds[project.ID],
+			CreatedAt:         project.CreatedAt,
+		}
+		if t[0] > 0 {
+			pp.CompletionPercentage = t[1] * 100 / t[0]
+		}
+		p.Projects = append(p.Projects, pp)
+		p.StepsTotal += t[0]
+		p.StepsCompleted += t[1]
+		p.StudySeconds += studySeconds[project.ID]
+	}
+
+	sum := 0
+	for _, a := range attempts {
+		sum += a.Percentage
+	}
+	p.AttemptsCompleted = len(attempts)
+	p.AveragePercentage = average(sum, len(attempts))
+	p.Trend = trend(attempts, now)
+
+	topics, err := weakTopics(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	p.WeakTopics = topics
+	return p, nil
+}
+
+// trend compares the latest attempts with the ones before them and averages
+// the scores per week. attempts must be completed and oldest first.
+func trend(attempts []models.QuizAttempt, now time.Time) Trend {
+	t := Trend{Direction: TrendFlat, Weeks: make([]WeekScore, trendWeeks)}
+
+	first := weekStart(now).AddDate(0, 0, -7*(trendWeeks-1))
+	sums := make([]int, trendWeeks)
+	for i := range t.Weeks {
+		t.Weeks[i].WeekStart = first.AddDate(0, 0, 7*i)
+	}
+	for _, a := range attempts {
+		at := a.CreatedAt
+		if a.CompletedAt != nil {
+			at = *a.CompletedAt
+		}
+		week := int(weekStart(at).Sub(first).Hours() / (24 * 7))
+		if week < 0 || week >= trendWeeks {
+			continue
+		}
+		t.Weeks[week].Attempts++
+		sums[week] += a.Percentage
+	}
+	for i := range t.Weeks {
+		t.Weeks[i].AveragePercentage = average(sums[i], t.Weeks[i].Attempts)
+	}
+
+	if len(attempts) < 2 {
+		return t
+	}
+	window := trendWindow
+	if len(attempts) < 2*window {
+		window = len(attempts) / 2
+	}
+	recent := attempts[len(attempts)-window:]
+	earlier := attempts[len(attempts)-2*window : len(attempts)-window]
+	recentSum, earlierSum := 0, 0
+	for i := 0; i < window; i++ {
+		recentSum += recent[i].Percentage
+		earlierSum += earlier[i].Percentage
+	}
+	t.Change = *average(recentSum, window) - *average(earlierSum, window)
+	switch {
+	case t.Change >= trendMargin:
+		t.Direction = TrendUp
+	case t.Change <= -trendMargin:
+		t.Direction = TrendDown
+	}
+	return t
+}
+
+// weakTopics returns the topics with the lowest correct rate among the
+// graded answers of the learner's completed attempts.
+func weakTopics(db *gorm.DB, userID uint) ([]Topic, error) {
+	var rows []struct {
+		Topic    string
+		Answered int
+		Correct  int
+	}
+	err := db.Table("attempt_answers").
+		Select("quizzes.topic AS topic, COUNT(*) AS answered, SUM(CASE WHEN attempt_answers.is_correct THEN 1 ELSE 0 END) AS correct").
+		Joins("JOIN quiz_attempts ON quiz_attempts.id = attempt_answers.attempt_id").
+		Joins("JOIN quizzes ON quizzes.id = attempt_answers.quiz_id").
+		Where("quiz_attempts.user_id = ? AND quiz_attempts.status = ? AND quizzes.topic <> ''", userID, "completed").
+		Where("attempt_answers.grading_status IS NULL OR attempt_answers.grading_status NOT IN ?", []string{"pending", "failed"}).
+		Group("quizzes.topic").
+		Having("COUNT(*) >= ? AND SUM(CASE WHEN attempt_answers.is_correct THEN 1 ELSE 0 END) < COUNT(*)", minTopicAnswers).
+		Order("CAST(SUM(CASE WHEN attempt_answers.is_correct THEN 1 ELSE 0 END) AS REAL) / COUNT(*), COUNT(*) DESC").
+		Limit(maxWeakTopics).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	topics := make([]Topic, 0, len(rows))
+	for _, r := range rows {
+		topics = append(topics, Topic{
+			Topic:       r.Topic,
+			Answered:    r.Answered,
+			Wrong:       r.Answered - r.Correct,
+			CorrectRate: r.Correct * 100 / r.Answered,
+		})
+	}
+	return topics, nil
+}
@@ -0,0 +1,304 @@
+package questions
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+)
+
+// Generated is a question as returned by the model.
+type Generated struct {
+	Type            string      `json:"type"`
+	Question        string      `json:"question"`
+	Code            string      `json:"code"`
+	Options         []string    `json:"options"`
+	AnswerIndex     int         `json:"answer_index"`
+	AnswerIndexes   []int       `json:"answer_indexes"`
+	AcceptedAnswers []string    `json:"accepted_answers"`
+	Rubric          []Criterion `json:"rubric"`
+	ModelAnswer     string      `json:"model_answer"`
+	Explanation     string      `json:"explanation"`
+	Difficulty      string      `json:"difficulty"`
+	Topic           string      `json:"topic"`
+}
+
+// ToQuiz validates a generated question and converts it into a quiz of the
+// step. Difficulty is stored as given; callers normalize it.
+func (g Generated) ToQuiz(stepID uint) (models.Quiz, error) {
+	t := g.Type
+	if t == "" {
+		t = TypeMultipleChoice
+	}
+	if strings.TrimSpace(g.Question) == "" {
+		return models.Quiz{}, fmt.Errorf("empty question")
+	}
+
+	quiz := models.Quiz{
+		StepID:      stepID,
+		Type:        t,
+		Question:    g.Question,
+		Code:        g.Code,
+		Explanation: g.Explanation,
+		Difficulty:  g.Difficulty,
+		Topic:       g.Topic,
+	}
+	options := g.Options
+	var answer *Answer
+
+	switch t {
+	case TypeMultipleChoice:
+		if len(options) < 2 {
+			return models.Quiz{}, fmt.Errorf("multiple_choice needs at least 2 options")
+		}
+		if g.AnswerIndex < 0 || g.AnswerIndex >= len(options) {
+			return models.Quiz{}, fmt.Errorf("answer_index out of range")
+		}
+		quiz.AnswerIndex = g.AnswerIndex
+
+	case TypeTrueFalse:
+		if len(options) != 2 {
+			options = []string{"True", "False"}
+		}
+		if g.AnswerIndex < 0 || g.AnswerIndex > 1 {
+			return models.Quiz{}, fmt.Errorf("answer_index must be 0 or 1")
+		}
+		quiz.AnswerIndex = g.AnswerIndex
+
+	case TypeMultipleSelect:
+		if len(options) < 3 {
+			return models.Quiz{}, fmt.Errorf("multiple_select needs at least 3 options")
+		}
+		// One correct option would make it a multiple_choice question
+		if len(g.AnswerIndexes) < 2 || len(g.AnswerIndexes) >= len(options) {
+			return models.Quiz{}, fmt.Errorf("multiple_select needs between 2 and %d correct options", len(options)-1)
+		}
+		seen := make(map[int]bool)
+		for _, idx := range g.AnswerIndexes {
+			if idx < 0 || idx >= len(options) || seen[idx] {
+				return models.Quiz{}, fmt.Errorf("invalid answer_indexes")
+			}
+			seen[idx] = true
+		}
+		answer = &Answer{Indexes: g.AnswerIndexes}
+
+	case TypeOrdering:
+		// Options are generated in their correct order and shuffled when served
+		if len(options) < 3 {
+			return models.Quiz{}, fmt.Errorf("ordering needs at least 3 items")
+		}
+		indexes := make([]int, len(options))
+		for i := range indexes {
+			indexes[i] = i
+		}
+		answer = &Answer{Indexes: indexes}
+
+	case TypeFillBlank, TypeCodeOutput:
+		if t == TypeFillBlank && !strings.Contains(g.Question, "___") {
+			return models.Quiz{}, fmt.Errorf("fill_blank question has no blank")
+		}
+		if t == TypeCodeOutput && strings.TrimSpace(g.Code) == "" {
+			return models.Quiz{}, fmt.Errorf("code_output needs code")
+		}
+		var accepted []string
+		for _, a := range g.AcceptedAnswers {
+			if strings.TrimSpace(a) != "" {
+				accepted = append(accepted, a)
+			}
+		}
+		if len(accepted) == 0 {
+			return models.Quiz{}, fmt.Errorf("%s needs accepted_answers", t)
+		}
+		options = []string{}
+		answer = &Answer{Accepted: accepted}
+
+	case TypeFreeResponse:
+		var rubric []Criterion
+		for _, c := range g.Rubric {
+			if strings.TrimSpace(c.Criterion) != "" && c.Points > 0 {
+				rubric = append(rubric, c)
+			}
+		}
+		if len(rubric) == 0 {
+			return models.Quiz{}, fmt.Errorf("free_response needs a rubric")
+		}
+		options = []string{}
+		answer = &Answer{Rubric: rubric, ModelAnswer: g.ModelAnswer}
+
+	default:
+		return models.Quiz{}, fmt.Errorf("unknown question type %q", t)
+	}
+
+	quiz.Options, _ = json.Marshal(options)
+	if answer != nil {
+		quiz.Answer, _ = json.Marshal(answer)
+	}
+	return quiz, nil
+}
+
+// FromQuiz converts a stored quiz back into the generated form, so that it
+// can be copied with ToQuiz. Ordering options are put in their correct order.
+func FromQuiz(q models.Quiz) Generated {
+	answer := AnswerOf(q)
+	g := Generated{
+		Type:        TypeOf(q),
+		Question:    q.Question,
+		Code:        q.Code,
+		Options:     Options(q),
+		AnswerIndex: q.AnswerIndex,
+		Explanation: q.Explanation,
+		Difficulty:  q.Difficulty,
+		Topic:       q.Topic,
+	}
+	switch g.Type {
+	case TypeMultipleSelect:
+		g.AnswerIndexes = answer.Indexes
+	case TypeOrdering:
+		ordered := make([]string, 0, len(answer.Indexes))
+		for _, idx := range answer.Indexes {
+			if idx >= 0 && idx < len(g.Options) {
+				ordered = append(ordered, g.Options[idx])
+			}
+		}
+		if len(ordered) == len(g.Options) {
+			g.Options = ordered
+		}
+	case TypeFillBlank, TypeCodeOutput:
+		g.AcceptedAnswers = answer.Accepted
+	case TypeFreeResponse:
+		g.Rubric = answer.Rubric
+		g.ModelAnswer = answer.ModelAnswer
+	}
+	return g
+}
+
+// Validate checks that a stored quiz is answerable: its answer fits its type
+// and options. Used when a quiz is edited by hand.
+func Validate(q models.Quiz) error {
+	if strings.TrimSpace(q.Question) == "" {
+		return fmt.Errorf("empty question")
+	}
+	options := Options(q)
+	answer := AnswerOf(q)
+
+	switch t := TypeOf(q); t {
+	case TypeMultipleChoice, TypeTrueFalse:
+		if t == TypeTrueFalse && len(options) != 2 {
+			return fmt.Errorf("true_false needs 2 options")
+		}
+		if len(options) < 2 {
+			return fmt.Errorf("multiple_choice needs at least 2 options")
+		}
+		if q.AnswerIndex < 0 || q.AnswerIndex >= len(options) {
+			return fmt.Errorf("answer_index out of range")
+		}
+
+	case TypeMultipleSelect, TypeOrdering:
+		if len(options) < 3 {
+			return fmt.Errorf("%s needs at least 3 options", t)
+		}
+		seen := make(map[int]bool)
+		for _, idx := range answer.Indexes {
+			if idx < 0 || idx >= len(options) || seen[idx] {
+				return fmt.Errorf("invalid answer_indexes")
+			}
+			seen[idx] = true
+		}
+		if t == TypeMultipleSelect && (len(seen) < 2 || len(seen) >= len(options)) {
+			return fmt.Errorf("multiple_select needs between 2 and %d correct options", len(options)-1)
+		}
+		if t == TypeOrdering && len(seen) != len(options) {
+			return fmt.Errorf("ordering answer_indexes must list every option")
+		}
+
+	case TypeFillBlank, TypeCodeOutput:
+		if t == TypeFillBlank && !strings.Contains(q.Question, "___") {
+			return fmt.Errorf("fill_blank question has no blank")
+		}
+		if t == TypeCodeOutput && strings.TrimSpace(q.Code) == "" {
+			return fmt.Errorf("code_output needs code")
+		}
+		if len(answer.Accepted) == 0 {
+			return fmt.Errorf("%s needs accepted_answers", t)
+		}
+
+	case TypeFreeResponse:
+		if len(answer.Rubric) == 0 {
+			return fmt.Errorf("free_response needs a rubric")
+		}
+		for _, c := range answer.Rubric {
+			if strings.TrimSpace(c.Criterion) == "" || c.Points <= 0 {
+				return fmt.Errorf("invalid rubric criterion")
+			}
+		}
+
+	default:
+		return fmt.Errorf("unknown question type %q", t)
+	}
+	return nil
+}
+
+// SimpleTypes are the automatically graded types with a single answer.
+var SimpleTypes = []string{TypeMultipleChoice, TypeTrueFalse, TypeFillBlank}
+
+// TypesForLevel lists the question types to ask for at a level. Beginners
+// get mostly single-answer questions; others also get the types that are
+// hard to guess. Every level gets a free-response question.
+func TypesForLevel(level string) []string {
+	if level == "beginner" {
+		return append(append([]string{}, SimpleTypes...), TypeFreeResponse)
+	}
+	return []string{TypeMultipleChoice, TypeMultipleSelect, TypeTrueFalse, TypeOrdering, TypeFillBlank, TypeCodeOutput, TypeFreeResponse}
+}
+
+// PromptSection describes the question types and their answer fields for
+// the quiz generation prompts.
+func PromptSection(locale string, types []string) string {
+	descriptions := map[string][2]string{
+		TypeMultipleChoice: {
+			`"multiple_choice": 4 options, exactly one correct. Set "answer_index".`,
+			`"multiple_choice": 選択肢4つ、正解は1つ。"answer_index" を設定。`,
+		},
+		TypeMultipleSelect: {
+			`"multiple_select": 4-5 options, two or more correct. Set "answer_indexes".`,
+			`"multiple_select": 選択肢4〜5つ、正解は2つ以上。"answer_indexes" を設定。`,
+		},
+		TypeTrueFalse: {
+			`"true_false": a statement to judge. Options are ["True", "False"]; set "answer_index" (0 = true, 1 = false).`,
+			`"true_false": 正誤を判断する文。選択肢は ["正しい", "誤り"]。"answer_index" を設定（0 = 正しい, 1 = 誤り）。`,
+		},
+		TypeOrdering: {
+			`"ordering": 3-6 items the learner puts in order. List "options" in the CORRECT order; they are shuffled when shown.`,
+			`"ordering": 学習者が並べ替える3〜6個の項目。"options" は正しい順序で並べてください（表示時にシャッフルされます）。`,
+		},
+		TypeFillBlank: {
+			`"fill_blank": the question contains "___" for one missing term. Set "accepted_answers" with every acceptable spelling; no options.`,
+			`"fill_blank": 問題文に空欄 "___" を1つ含める。"accepted_answers" に許容する表記をすべて設定。選択肢は不要。`,
+		},
+		TypeCodeOutput: {
+			`"code_output": put a short, deterministic snippet in "code" and ask what it prints. Set "accepted_answers" to the exact output; no options.`,
+			`"code_output": 短く決定的なコードを "code" に入れ、出力を問う。"accepted_answers" に正確な出力を設定。選択肢は不要。`,
+		},
+		TypeFreeResponse: {
+			`"free_response": exactly one open-ended question asking the learner to explain a decision or concept in a few sentences. Set "rubric" to 2-4 criteria ({"criterion": "...", "points": 1-3}) and "model_answer"; no options.`,
+			`"free_response": 判断や概念を数文で説明させる記述式の問題をちょうど1問。"rubric" に2〜4個の採点基準（{"criterion": "...", "points": 1〜3}）と "model_answer" を設定。選択肢は不要。`,
+		},
+	}
+
+	lang := 1
+	if locale == "en" {
+		lang = 0
+	}
+
+	var b strings.Builder
+	if locale == "en" {
+		b.WriteString("# Question Types\nUse a mix of the following types and set \"type\" on each quiz:\n")
+	} else {
+		b.WriteString("# 問題形式\n以下の形式を組み合わせ、各クイズに \"type\" を設定してください：\n")
+	}
+	for _, t := range types {
+		fmt.Fprintf(&b, "- %s\n", descriptions[t][lang])
+	}
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package questions
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToQuiz(t *testing.T) {
+	abcd := []string{"a", "b", "c", "d"}
+	tests := []struct {
+		name    string
+		gen     Generated
+		wantErr bool
+	}{
+		{"multiple choice", Generated{Question: "Q", Options: abcd, AnswerIndex: 3}, false},
+		{"answer index out of range", Generated{Question: "Q", Options: abcd, AnswerIndex: 4}, true},
+		{"empty question", Generated{Question: " ", Options: abcd}, true},
+		{"multiple select", Generated{Type: TypeMultipleSelect, Question: "Q", Options: abcd, AnswerIndexes: []int{0, 2}}, false},
+		{"multiple select with one answer", Generated{Type: TypeMultipleSelect, Question: "Q", Options: abcd, AnswerIndexes: []int{1}}, true},
+		{"multiple select with every option", Generated{Type: TypeMultipleSelect, Question: "Q", Options: abcd, AnswerIndexes: []int{0, 1, 2, 3}}, true},
+		{"multiple select repeats an answer", Generated{Type: TypeMultipleSelect, Question: "Q", Options: abcd, AnswerIndexes: []int{1, 1}}, true},
+		{"ordering", Generated{Type: TypeOrdering, Question: "Q", Options: abcd}, false},
+		{"fill blank without blank", Generated{Type: TypeFillBlank, Question: "Q", AcceptedAnswers: []string{"x"}}, true},
+		{"fill blank", Generated{Type: TypeFillBlank, Question: "Q ___", AcceptedAnswers: []string{"x"}}, false},
+		{"code output without code", Generated{Type: TypeCodeOutput, Question: "Q", AcceptedAnswers: []string{"1"}}, true},
+		{"free response without rubric", Generated{Type: TypeFreeResponse, Question: "Q"}, true},
+		{"unknown type", Generated{Type: "essay", Question: "Q"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := tt.gen.ToQuiz(1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToQuiz error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			// Whatever ToQuiz accepts, Validate accepts too
+			if err := Validate(q); err != nil {
+				t.Errorf("Validate rejects the converted quiz: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateMultipleSelectNeedsTwoAnswers(t *testing.T) {
+	one := quiz(TypeMultipleSelect, []string{"a", "b", "c"}, 0, &Answer{Indexes: []int{1}})
+	if err := Validate(one); err == nil {
+		t.Error("multiple select with one correct option is accepted")
+	}
+	two := quiz(TypeMultipleSelect, []string{"a", "b", "c"}, 0, &Answer{Indexes: []int{0, 1}})
+	if err := Validate(two); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+// FromQuiz undoes ToQuiz, so that templates copy questions unchanged.
+func TestFromQuizRoundTrip(t *testing.T) {
+	gens := []Generated{
+		{Type: TypeMultipleChoice, Question: "Q", Options: []string{"a", "b"}, AnswerIndex: 1, Explanation: "e", Difficulty: "easy", Topic: "t"},
+		{Type: TypeMultipleSelect, Question: "Q", Options: []string{"a", "b", "c"}, AnswerIndexes: []int{0, 2}},
+		{Type: TypeOrdering, Question: "Q", Options: []string{"first", "second", "third"}},
+		{Type: TypeFillBlank, Question: "Q ___", Options: []string{}, AcceptedAnswers: []string{"x", "y"}},
+		{Type: TypeFreeResponse, Question: "Q", Options: []string{}, Rubric: []Criterion{{Criterion: "c", Points: 2}}, ModelAnswer: "m"},
+	}
+	for _, g := range gens {
+		t.Run(g.Type, func(t *testing.T) {
+			q, err := g.ToQuiz(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := FromQuiz(q); !reflect.DeepEqual(got, g) {
+				t.Errorf("FromQuiz = %+v, want %+v", got, g)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package questions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+)
+
+// ErrInvalidResponse is returned for a response that does not fit the question.
+var ErrInvalidResponse = errors.New("invalid response")
+
+// ToOriginal validates a response given in displayed indexes and converts it
+// to indexes into Quiz.Options, using the order the options were served in.
+func ToOriginal(q models.Quiz, order []int, resp Response) (Response, error) {
+	t := TypeOf(q)
+	switch {
+	case SingleChoice(t):
+		if resp.Choice < 0 || resp.Choice >= len(order) {
+			return Response{}, ErrInvalidResponse
+		}
+		return Response{Choice: order[resp.Choice]}, nil
+
+	case t == TypeMultipleSelect || t == TypeOrdering:
+		if len(resp.Choices) == 0 {
+			return Response{}, ErrInvalidResponse
+		}
+		seen := make(map[int]bool)
+		choices := make([]int, len(resp.Choices))
+		for i, c := range resp.Choices {
+			if c < 0 || c >= len(order) || seen[c] {
+				return Response{}, ErrInvalidResponse
+			}
+			seen[c] = true
+			choices[i] = order[c]
+		}
+		if t == TypeOrdering && len(choices) != len(order) {
+			return Response{}, ErrInvalidResponse
+		}
+		return Response{Choice: -1, Choices: choices}, nil
+
+	default:
+		text := strings.TrimSpace(resp.Text)
+		if text == "" {
+			return Response{}, ErrInvalidResponse
+		}
+		return Response{Choice: -1, Text: text}, nil
+	}
+}
+
+// FromOriginal converts indexes into Quiz.Options back to displayed indexes.
+func FromOriginal(order []int, resp Response) Response {
+	displayed := make(map[int]int)
+	for i, idx := range order {
+		displayed[idx] = i
+	}
+
+	out := Response{Choice: -1, Text: resp.Text}
+	if d, ok := displayed[resp.Choice]; ok {
+		out.Choice = d
+	}
+	for _, c := range resp.Choices {
+		if d, ok := displayed[c]; ok {
+			out.Choices = append(out.Choices, d)
+		}
+	}
+	return out
+}
+
+// Grade reports whether a response (in indexes into Quiz.Options) is correct.
+// Free responses are graded by the model and always report false here.
+func Grade(q models.Quiz, resp Response) bool {
+	answer := AnswerOf(q)
+	switch t := TypeOf(q); t {
+	case TypeMultipleChoice, TypeTrueFalse:
+		return resp.Choice == q.AnswerIndex
+
+	case TypeMultipleSelect:
+		if len(resp.Choices) != len(answer.Indexes) {
+			return false
+		}
+		got := append([]int(nil), resp.Choices...)
+		want := append([]int(nil), answer.Indexes...)
+		sort.Ints(got)
+		sort.Ints(want)
+		for i := range got {
+			if got[i] != want[i] {
+				return false
+			}
+		}
+		return true
+
+	case TypeOrdering:
+		if len(resp.Choices) != len(answer.Indexes) {
+			return false
+		}
+		for i := range resp.Choices {
+			if resp.Choices[i] != answer.Indexes[i] {
+				return false
+			}
+		}
+		return true
+
+	case TypeFillBlank, TypeCodeOutput:
+		got := normalizeText(t, resp.Text, answer.CaseSensitive)
+		for _, accepted := range answer.Accepted {
+			if got == normalizeText(t, accepted, answer.CaseSensitive) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ResponseKey identifies a response (in indexes into Quiz.Options) so that
+// equivalent responses to a question share a key: selections regardless of
+// order, text as it would be graded.
+func ResponseKey(q models.Quiz, resp Response) string {
+	switch t := TypeOf(q); {
+	case SingleChoice(t):
+		return "choice:" + strconv.Itoa(resp.Choice)
+	case t == TypeMultipleSelect || t == TypeOrdering:
+		choices := append([]int(nil), resp.Choices...)
+		if t == TypeMultipleSelect {
+			sort.Ints(choices)
+		}
+		parts := make([]string, len(choices))
+		for i, c := range choices {
+			parts[i] = strconv.Itoa(c)
+		}
+		return "choices:" + strings.Join(parts, ",")
+	default:
+		text := normalizeText(t, resp.Text, AnswerOf(q).CaseSensitive)
+		// Long answers are hashed to fit the key column
+		if len(text) > 200 {
+			sum := sha256.Sum256([]byte(text))
+			return "text#" + hex.EncodeToString(sum[:])
+		}
+		return "text:" + text
+	}
+}
+
+// normalizeText makes text answers comparable: blanks are compared ignoring
+// extra spaces (and case unless caseSensitive), code output exactly, line by
+// line, ignoring trailing spaces and surrounding blank lines.
+func normalizeText(t, s string, caseSensitive bool) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	if t == TypeCodeOutput {
+		lines := strings.Split(strings.TrimSpace(s), "\n")
+		for i, l := range lines {
+			lines[i] = strings.TrimRight(l, " \t")
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	s = strings.Join(strings.Fields(s), " ")
+	if !caseSensitive {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+// Reveal returns the correct answer in displayed indexes (or accepted text,
+// or the model answer of a free response), for showing after the question
+// is answered.
+func Reveal(q models.Quiz, order []int) Response {
+	answer := AnswerOf(q)
+	switch t := TypeOf(q); {
+	case SingleChoice(t):
+		return FromOriginal(order, Response{Choice: q.AnswerIndex})
+	case t == TypeMultipleSelect || t == TypeOrdering:
+		return FromOriginal(order, Response{Choice: -1, Choices: answer.Indexes})
+	case t == TypeFreeResponse:
+		return Response{Choice: -1, Text: answer.ModelAnswer}
+	default:
+		text := ""
+		if len(answer.Accepted) > 0 {
+			text = answer.Accepted[0]
+		}
+		return Response{Choice: -1, Text: text}
+	}
+}
@@ -0,0 +1,146 @@
+package questions
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+)
+
+func quiz(t string, options []string, answerIndex int, answer *Answer) models.Quiz {
+	q := models.Quiz{Type: t, Question: "Q ___", AnswerIndex: answerIndex}
+	q.Options, _ = json.Marshal(options)
+	if answer != nil {
+		q.Answer, _ = json.Marshal(answer)
+	}
+	return q
+}
+
+func TestGradeDisplayedResponses(t *testing.T) {
+	abcd := []string{"a", "b", "c", "d"}
+	tests := []struct {
+		name  string
+		quiz  models.Quiz
+		order []int // displayed index -> index into the options
+		resp  Response
+		want  bool
+	}{
+		{"multiple choice correct", quiz(TypeMultipleChoice, abcd, 2, nil), []int{3, 2, 0, 1}, Response{Choice: 1}, true},
+		{"multiple choice wrong", quiz(TypeMultipleChoice, abcd, 2, nil), []int{3, 2, 0, 1}, Response{Choice: 2}, false},
+		{"legacy quiz is multiple choice", quiz("", abcd, 0, nil), []int{1, 0, 2, 3}, Response{Choice: 1}, true},
+		{"true false", quiz(TypeTrueFalse, []string{"True", "False"}, 1, nil), []int{0, 1}, Response{Choice: 1}, true},
+		{"multiple select in any order", quiz(TypeMultipleSelect, abcd, 0, &Answer{Indexes: []int{0, 2}}), []int{2, 0, 1, 3}, Response{Choices: []int{0, 1}}, true},
+		{"multiple select missing one", quiz(TypeMultipleSelect, abcd, 0, &Answer{Indexes: []int{0, 2}}), []int{2, 0, 1, 3}, Response{Choices: []int{1}}, false},
+		{"multiple select one too many", quiz(TypeMultipleSelect, abcd, 0, &Answer{Indexes: []int{0, 2}}), []int{2, 0, 1, 3}, Response{Choices: []int{0, 1, 2}}, false},
+		{"ordering correct", quiz(TypeOrdering, []string{"x", "y", "z"}, 0, &Answer{Indexes: []int{0, 1, 2}}), []int{2, 0, 1}, Response{Choices: []int{1, 2, 0}}, true},
+		{"ordering as displayed", quiz(TypeOrdering, []string{"x", "y", "z"}, 0, &Answer{Indexes: []int{0, 1, 2}}), []int{2, 0, 1}, Response{Choices: []int{0, 1, 2}}, false},
+		{"fill blank ignores case and spaces", quiz(TypeFillBlank, nil, 0, &Answer{Accepted: []string{"Go routine"}}), nil, Response{Text: "  go   ROUTINE "}, true},
+		{"fill blank case sensitive", quiz(TypeFillBlank, nil, 0, &Answer{Accepted: []string{"Go"}, CaseSensitive: true}), nil, Response{Text: "go"}, false},
+		{"fill blank second accepted answer", quiz(TypeFillBlank, nil, 0, &Answer{Accepted: []string{"map", "hash map"}}), nil, Response{Text: "hash map"}, true},
+		{"code output trailing spaces", quiz(TypeCodeOutput, nil, 0, &Answer{Accepted: []string{"1\n2"}}), nil, Response{Text: "\n1  \r\n2\n"}, true},
+		{"code output keeps case", quiz(TypeCodeOutput, nil, 0, &Answer{Accepted: []string{"True"}}), nil, Response{Text: "true"}, false},
+		{"free response is never graded here", quiz(TypeFreeResponse, nil, 0, &Answer{Rubric: []Criterion{{Criterion: "c", Points: 1}}}), nil, Response{Text: "anything"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			given, err := ToOriginal(tt.quiz, tt.order, tt.resp)
+			if err != nil {
+				t.Fatalf("ToOriginal: %v", err)
+			}
+			if got := Grade(tt.quiz, given); got != tt.want {
+				t.Errorf("Grade = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToOriginalRejectsInvalidResponses(t *testing.T) {
+	abcd := []string{"a", "b", "c", "d"}
+	order := []int{0, 1, 2, 3}
+	tests := []struct {
+		name string
+		quiz models.Quiz
+		resp Response
+	}{
+		{"choice out of range", quiz(TypeMultipleChoice, abcd, 0, nil), Response{Choice: 4}},
+		{"negative choice", quiz(TypeMultipleChoice, abcd, 0, nil), Response{Choice: -1}},
+		{"no selection", quiz(TypeMultipleSelect, abcd, 0, &Answer{Indexes: []int{0, 1}}), Response{}},
+		{"selection twice", quiz(TypeMultipleSelect, abcd, 0, &Answer{Indexes: []int{0, 1}}), Response{Choices: []int{1, 1}}},
+		{"ordering misses an item", quiz(TypeOrdering, abcd, 0, &Answer{Indexes: []int{0, 1, 2, 3}}), Response{Choices: []int{0, 1, 2}}},
+		{"empty text", quiz(TypeFillBlank, nil, 0, &Answer{Accepted: []string{"x"}}), Response{Text: "   "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ToOriginal(tt.quiz, order, tt.resp); !errors.Is(err, ErrInvalidResponse) {
+				t.Errorf("ToOriginal error = %v, want ErrInvalidResponse", err)
+			}
+		})
+	}
+}
+
+// Converting a displayed response to option indexes and back must give the
+// response that was sent, whatever the shuffle.
+func TestShuffleRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	options := []string{"a", "b", "c", "d", "e"}
+	mc := quiz(TypeMultipleChoice, options, 3, nil)
+	ms := quiz(TypeMultipleSelect, options, 0, &Answer{Indexes: []int{1, 4}})
+	ord := quiz(TypeOrdering, options, 0, &Answer{Indexes: []int{0, 1, 2, 3, 4}})
+
+	for i := 0; i < 100; i++ {
+		order := rng.Perm(len(options))
+
+		choice := Response{Choice: rng.Intn(len(options))}
+		given, err := ToOriginal(mc, order, choice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if back := FromOriginal(order, given); back.Choice != choice.Choice {
+			t.Fatalf("order %v: choice %d came back as %d", order, choice.Choice, back.Choice)
+		}
+
+		for _, q := range []models.Quiz{ms, ord} {
+			n := len(options)
+			if TypeOf(q) == TypeMultipleSelect {
+				n = 1 + rng.Intn(len(options)-1)
+			}
+			choices := Response{Choice: -1, Choices: rng.Perm(len(options))[:n]}
+			given, err := ToOriginal(q, order, choices)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if back := FromOriginal(order, given); !reflect.DeepEqual(back.Choices, choices.Choices) {
+				t.Fatalf("%s order %v: choices %v came back as %v", TypeOf(q), order, choices.Choices, back.Choices)
+			}
+		}
+
+		// The revealed answer, sent back as the response, is graded correct
+		for _, q := range []models.Quiz{mc, ms, ord} {
+			given, err := ToOriginal(q, order, Reveal(q, order))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Grade(q, given) {
+				t.Fatalf("%s order %v: revealed answer graded wrong", TypeOf(q), order)
+			}
+		}
+	}
+}
+
+func TestResponseKeyIgnoresSelectionOrder(t *testing.T) {
+	ms := quiz(TypeMultipleSelect, []string{"a", "b", "c"}, 0, &Answer{Indexes: []int{0, 1}})
+	if ResponseKey(ms, Response{Choices: []int{2, 0}}) != ResponseKey(ms, Response{Choices: []int{0, 2}}) {
+		t.Error("multiple select keys differ by selection order")
+	}
+	ord := quiz(TypeOrdering, []string{"a", "b", "c"}, 0, &Answer{Indexes: []int{0, 1, 2}})
+	if ResponseKey(ord, Response{Choices: []int{2, 0, 1}}) == ResponseKey(ord, Response{Choices: []int{0, 1, 2}}) {
+		t.Error("ordering keys ignore the order")
+	}
+	fill := quiz(TypeFillBlank, nil, 0, &Answer{Accepted: []string{"x"}})
+	if ResponseKey(fill, Response{Text: " Go  Routine"}) != ResponseKey(fill, Response{Text: "go routine"}) {
+		t.Error("fill blank keys differ by case and spaces")
+	}
+}
@@ -0,0 +1,211 @@
+package questions
+
+import (
+	"encoding/json"
+	"math/rand"
+	"strings"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+)
+
+// Question types
+const (
+	TypeMultipleChoice = "multiple_choice" // one correct option (Quiz.AnswerIndex)
+	TypeMultipleSelect = "multiple_select" // several correct options (Answer.Indexes)
+	TypeTrueFalse      = "true_false"      // options are true/false (Quiz.AnswerIndex)
+	TypeOrdering       = "ordering"        // put the options in order (Answer.Indexes)
+	TypeFillBlank      = "fill_blank"      // type the missing word (Answer.Accepted)
+	TypeCodeOutput     = "code_output"     // predict what Quiz.Code prints (Answer.Accepted)
+	TypeFreeResponse   = "free_response"   // open-ended, graded by the model (Answer.Rubric)
+)
+
+// FreeResponsePassPercent is the share of rubric points a free response
+// needs to count as correct.
+const FreeResponsePassPercent = 60
+
+// Criterion is one item of a free-response rubric.
+type Criterion struct {
+	Criterion string `json:"criterion"`
+	Points    int    `json:"points"`
+}
+
+// Answer is the type-specific answer stored in Quiz.Answer.
+type Answer struct {
+	// Indexes are the correct options for multiple_select, and the options
+	// in their correct order for ordering.
+	Indexes []int `json:"indexes,omitempty"`
+	// Accepted lists the accepted texts for fill_blank and code_output.
+	Accepted []string `json:"accepted,omitempty"`
+	// CaseSensitive makes fill_blank answers match case; code output always does.
+	CaseSensitive bool `json:"case_sensitive,omitempty"`
+	// Rubric and ModelAnswer are used to grade free_response answers.
+	Rubric      []Criterion `json:"rubric,omitempty"`
+	ModelAnswer string      `json:"model_answer,omitempty"`
+}
+
+// MaxPoints is the total of the rubric's points.
+func (a Answer) MaxPoints() int {
+	total := 0
+	for _, c := range a.Rubric {
+		total += c.Points
+	}
+	return total
+}
+
+// Response is a learner's answer. Choice is used by single-answer types,
+// Choices by multiple_select (selected options) and ordering (options in the
+// chosen order), and Text by fill_blank and code_output. Indexes refer to
+// the options as displayed when sent by the client, and to Quiz.Options
+// once converted with ToOriginal.
+type Response struct {
+	Choice  int    `json:"choice"`
+	Choices []int  `json:"choices,omitempty"`
+	Text    string `json:"text,omitempty"`
+}
+
+// TypeOf returns the quiz type, treating legacy quizzes as multiple choice.
+func TypeOf(q models.Quiz) string {
+	if q.Type == "" {
+		return TypeMultipleChoice
+	}
+	return q.Type
+}
+
+// SingleChoice reports whether the type is answered with one option.
+func SingleChoice(t string) bool {
+	return t == TypeMultipleChoice || t == TypeTrueFalse
+}
+
+// HasOptions reports whether the type is answered by picking options.
+func HasOptions(t string) bool {
+	return t != TypeFillBlank && t != TypeCodeOutput && t != TypeFreeResponse
+}
+
+// AnswerOf decodes the quiz's type-specific answer.
+func AnswerOf(q models.Quiz) Answer {
+	var a Answer
+	if len(q.Answer) > 0 {
+		json.Unmarshal(q.Answer, &a)
+	}
+	return a
+}
+
+// ResponseOf decodes the graded response recorded on an answer row, in
+// indexes into Quiz.Options.
+func ResponseOf(a models.AttemptAnswer) Response {
+	if len(a.Response) == 0 {
+		// Answers recorded before question types only have ChosenIndex
+		return Response{Choice: a.ChosenIndex}
+	}
+	var resp Response
+	json.Unmarshal(a.Response, &resp)
+	return resp
+}
+
+// Options decodes the quiz options.
+func Options(q models.Quiz) []string {
+	var options []string
+	json.Unmarshal(q.Options, &options)
+	return options
+}
+
+// NewOrder returns the option order for a new serving of the quiz:
+// shuffled, except for true/false which keeps its natural order.
+func NewOrder(q models.Quiz) []int {
+	n := len(Options(q))
+	t := TypeOf(q)
+	if !HasOptions(t) {
+		return []int{}
+	}
+	if t == TypeTrueFalse {
+		order := make([]int, n)
+		for i := range order {
+			order[i] = i
+		}
+		return order
+	}
+	return rand.Perm(n)
+}
+
+// Preview is a quiz as listed outside of an attempt, without its answer.
+type Preview struct {
+	ID       uint     `json:"id"`
+	Type     string   `json:"type"`
+	Question string   `json:"question"`
+	Code     string   `json:"code,omitempty"`
+	Options  []string `json:"options"`
+}
+
+// PreviewOf lists a quiz without its answer. Ordering items are stored in
+// their correct order, so they are shuffled here.
+func PreviewOf(q models.Quiz) Preview {
+	options := Options(q)
+	if TypeOf(q) == TypeOrdering {
+		shuffled := make([]string, len(options))
+		for i, idx := range rand.Perm(len(options)) {
+			shuffled[i] = options[idx]
+		}
+		options = shuffled
+	}
+	if options == nil {
+		options = []string{}
+	}
+	return Preview{
+		ID:       q.ID,
+		Type:     TypeOf(q),
+		Question: q.Question,
+		Code:     q.Code,
+		Options:  options,
+	}
+}
+
+// AnswerText describes the correct answer in words, e.g. for prompts.
+func AnswerText(q models.Quiz) string {
+	options := Options(q)
+	answer := AnswerOf(q)
+	switch t := TypeOf(q); {
+	case SingleChoice(t):
+		if q.AnswerIndex >= 0 && q.AnswerIndex < len(options) {
+			return options[q.AnswerIndex]
+		}
+	case t == TypeMultipleSelect || t == TypeOrdering:
+		return optionsText(t, options, answer.Indexes)
+	case t == TypeFreeResponse:
+		return answer.ModelAnswer
+	default:
+		if len(answer.Accepted) > 0 {
+			return answer.Accepted[0]
+		}
+	}
+	return ""
+}
+
+// ResponseText describes a response (in indexes into Quiz.Options) in words.
+func ResponseText(q models.Quiz, resp Response) string {
+	options := Options(q)
+	switch t := TypeOf(q); {
+	case SingleChoice(t):
+		if resp.Choice >= 0 && resp.Choice < len(options) {
+			return options[resp.Choice]
+		}
+		return ""
+	case t == TypeMultipleSelect || t == TypeOrdering:
+		return optionsText(t, options, resp.Choices)
+	default:
+		return resp.Text
+	}
+}
+
+func optionsText(t string, options []string, indexes []int) string {
+	var parts []string
+	for _, idx := range indexes {
+		if idx >= 0 && idx < len(options) {
+			parts = append(parts, options[idx])
+		}
+	}
+	sep := ", "
+	if t == TypeOrdering {
+		sep = " -> "
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,77 @@
+package quota
+
+import (
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Metrics counted against plan limits
+const (
+	MetricChatMessages = "chat_messages" // messages answered by the tutor
+)
+
+// Limits are the daily usage limits of a subscription plan, by metric.
+type Limits map[string]int
+
+var plans = map[string]Limits{
+	"free": {MetricChatMessages: 20},
+	"pro":  {MetricChatMessages: 200},
+}
+
+// For returns the limits of a plan. Users without a plan get the free limits.
+func For(plan string) Limits {
+	if l, ok := plans[plan]; ok {
+		return l
+	}
+	return plans["free"]
+}
+
+// Usage is how much of a limit has been used today (UTC).
+type Usage struct {
+	Metric   string    `json:"metric"`
+	Limit    int       `json:"limit"`
+	Used     int       `json:"used"`
+	ResetsAt time.Time `json:"resets_at"`
+}
+
+// Exceeded reports whether nothing is left of the limit.
+func (u Usage) Exceeded() bool {
+	return u.Used >= u.Limit
+}
+
+func today() (string, time.Time) {
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return start.Format("2006-01-02"), start.AddDate(0, 0, 1)
+}
+
+// Get returns the user's usage of a metric today.
+func Get(db *gorm.DB, user models.User, metric string) (Usage, error) {
+	day, resetsAt := today()
+
+	var record models.UsageRecord
+	if err := db.Where("user_id = ? AND metric = ? AND day = ?", user.ID, metric, day).Limit(1).Find(&record).Error; err != nil {
+		return Usage{}, err
+	}
+
+	return Usage{
+		Metric:   metric,
+		Limit:    For(user.SubscriptionPlan)[metric],
+		Used:     record.Count,
+		ResetsAt: resetsAt,
+	}, nil
+}
+
+// Record counts one use of a metric by the user today.
+func Record(db *gorm.DB, userID uint, metric string) error {
+	day, _ := today()
+
+	record := models.UsageRecord{UserID: userID, Metric: metric, Day: day}
+	if err := db.Where(record).FirstOrCreate(&record).Error; err != nil {
+		return err
+	}
+	return db.Model(&record).UpdateColumn("count", gorm.Expr("count + ?", 1)).Error
+}
@@ -0,0 +1,112 @@
+package review
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/questions"
+
+	"gorm.io/gorm"
+)
+
+// Answer qualities on the SM-2 0-5 scale
+const (
+	QualityWrong   = 1 // incorrect, but the answer was recognised once shown
+	QualityHard    = 3 // correct with serious difficulty
+	QualityDefault = 4 // correct after some hesitation
+	QualityEasy    = 5 // perfect response
+)
+
+const (
+	initialEase = 2.5
+	minEase     = 1.3
+)
+
+// Schedule applies one review with the given quality to the card and sets
+// its next due date, following the SM-2 algorithm.
+func Schedule(card *models.ReviewCard, quality int, now time.Time) {
+	if quality < 0 {
+		quality = 0
+	}
+	if quality > 5 {
+		quality = 5
+	}
+	if card.EaseFactor == 0 {
+		card.EaseFactor = initialEase
+	}
+
+	if quality < 3 {
+		// Lapse: start the repetitions over
+		card.Lapses++
+		card.Repetitions = 0
+		card.IntervalDays = 1
+	} else {
+		switch card.Repetitions {
+		case 0:
+			card.IntervalDays = 1
+		case 1:
+			card.IntervalDays = 6
+		default:
+			card.IntervalDays = int(math.Round(float64(card.IntervalDays) * card.EaseFactor))
+		}
+		card.Repetitions++
+	}
+
+	q := float64(5 - quality)
+	card.EaseFactor += 0.1 - q*(0.08+q*0.02)
+	if card.EaseFactor < minEase {
+		card.EaseFactor = minEase
+	}
+
+	card.LastReviewedAt = &now
+	card.DueAt = now.AddDate(0, 0, card.IntervalDays)
+
+	// Show the options in a new order next time
+	card.OptionOrder = nil
+}
+
+// ShuffleOptions gives the card a fresh option order for its quiz.
+func ShuffleOptions(card *models.ReviewCard, q models.Quiz) {
+	card.OptionOrder, _ = json.Marshal(questions.NewOrder(q))
+}
+
+// RecordAttempt creates or updates review cards from a completed attempt.
+// Missed questions are always scheduled; correctly answered ones only when
+// includeCorrect is set and the question has no card yet.
+func RecordAttempt(db *gorm.DB, attempt models.QuizAttempt, includeCorrect bool) error {
+	now := time.Now()
+	for _, a := range attempt.Answers {
+		// Free responses need the model to grade them, so they are not reviewed
+		if a.GradingStatus != "" {
+			continue
+		}
+
+		var card models.ReviewCard
+		db.Where("user_id = ? AND quiz_id = ?", attempt.UserID, a.QuizID).Limit(1).Find(&card)
+
+		if a.IsCorrect && (card.ID != 0 || !includeCorrect) {
+			continue
+		}
+
+		if card.ID == 0 {
+			card = models.ReviewCard{
+				UserID:    attempt.UserID,
+				QuizID:    a.QuizID,
+				CreatedAt: now,
+			}
+		}
+
+		quality := QualityWrong
+		if a.IsCorrect {
+			quality = QualityDefault
+		}
+		Schedule(&card, quality, now)
+
+		if err := db.Save(&card).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
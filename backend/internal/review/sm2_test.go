@@ -0,0 +1,67 @@
+package review
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+)
+
+func TestScheduleIntervals(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		qualities []int
+		interval  int
+		reps      int
+		lapses    int
+		ease      float64
+	}{
+		{"first review", []int{QualityDefault}, 1, 1, 0, 2.5},
+		{"second review", []int{QualityDefault, QualityDefault}, 6, 2, 0, 2.5},
+		{"third review multiplies by ease", []int{QualityDefault, QualityDefault, QualityDefault}, 15, 3, 0, 2.5},
+		{"easy raises ease", []int{QualityEasy, QualityEasy, QualityEasy}, 16, 3, 0, 2.8},
+		{"hard lowers ease", []int{QualityHard, QualityHard, QualityHard}, 13, 3, 0, 2.08},
+		{"lapse starts over", []int{QualityDefault, QualityDefault, QualityWrong}, 1, 0, 1, 1.96},
+		{"relearning after a lapse", []int{QualityDefault, QualityDefault, QualityWrong, QualityDefault, QualityDefault}, 6, 2, 1, 1.96},
+		{"ease stops at its floor", []int{0, 0, 0, 0, 0}, 1, 0, 5, minEase},
+		{"qualities out of range are clamped", []int{9, -3}, 1, 0, 1, 1.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var card models.ReviewCard
+			for _, q := range tt.qualities {
+				Schedule(&card, q, now)
+			}
+			if card.IntervalDays != tt.interval {
+				t.Errorf("IntervalDays = %d, want %d", card.IntervalDays, tt.interval)
+			}
+			if card.Repetitions != tt.reps {
+				t.Errorf("Repetitions = %d, want %d", card.Repetitions, tt.reps)
+			}
+			if card.Lapses != tt.lapses {
+				t.Errorf("Lapses = %d, want %d", card.Lapses, tt.lapses)
+			}
+			if math.Abs(card.EaseFactor-tt.ease) > 1e-9 {
+				t.Errorf("EaseFactor = %v, want %v", card.EaseFactor, tt.ease)
+			}
+		})
+	}
+}
+
+func TestScheduleSetsDueDate(t *testing.T) {
+	now := time.Date(2025, 3, 30, 8, 0, 0, 0, time.UTC)
+	card := models.ReviewCard{Repetitions: 1, IntervalDays: 1, EaseFactor: 2.5, OptionOrder: []byte("[1,0]")}
+	Schedule(&card, QualityDefault, now)
+
+	if want := now.AddDate(0, 0, 6); !card.DueAt.Equal(want) {
+		t.Errorf("DueAt = %v, want %v", card.DueAt, want)
+	}
+	if card.LastReviewedAt == nil || !card.LastReviewedAt.Equal(now) {
+		t.Errorf("LastReviewedAt = %v, want %v", card.LastReviewedAt, now)
+	}
+	if card.OptionOrder != nil {
+		t.Errorf("OptionOrder = %s, want a fresh order", card.OptionOrder)
+	}
+}
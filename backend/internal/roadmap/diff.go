@@ -0,0 +1,77 @@
+package roadmap
+
+// Change types
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// StepChange describes how a single step differs between two revisions.
+// Steps are matched by their step ID, so a reordered step shows up as
+// modified with "step" in Fields rather than as removed and added.
+type StepChange struct {
+	Type   string        `json:"type"`
+	StepID uint          `json:"step_id"`
+	Fields []string      `json:"fields,omitempty"` // step, title, description
+	From   *StepSnapshot `json:"from,omitempty"`
+	To     *StepSnapshot `json:"to,omitempty"`
+}
+
+// Diff returns the changes needed to go from one snapshot to another,
+// ordered by the step position in the "to" snapshot followed by removals.
+func Diff(from, to []StepSnapshot) []StepChange {
+	before := make(map[uint]StepSnapshot)
+	for _, s := range from {
+		before[s.StepID] = s
+	}
+
+	changes := []StepChange{}
+	seen := make(map[uint]bool)
+	for i := range to {
+		after := to[i]
+		seen[after.StepID] = true
+
+		prev, ok := before[after.StepID]
+		if !ok {
+			changes = append(changes, StepChange{
+				Type:   ChangeAdded,
+				StepID: after.StepID,
+				To:     &after,
+			})
+			continue
+		}
+
+		var fields []string
+		if prev.Step != after.Step {
+			fields = append(fields, "step")
+		}
+		if prev.Title != after.Title {
+			fields = append(fields, "title")
+		}
+		if prev.Description != after.Description {
+			fields = append(fields, "description")
+		}
+		if len(fields) > 0 {
+			changes = append(changes, StepChange{
+				Type:   ChangeModified,
+				StepID: after.StepID,
+				Fields: fields,
+				From:   &prev,
+				To:     &after,
+			})
+		}
+	}
+
+	for i := range from {
+		if !seen[from[i].StepID] {
+			prev := from[i]
+			changes = append(changes, StepChange{
+				Type:   ChangeRemoved,
+				StepID: prev.StepID,
+				From:   &prev,
+			})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,53 @@
+package roadmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	from := []StepSnapshot{
+		{StepID: 1, Step: 1, Title: "Basics", Description: "Syntax"},
+		{StepID: 2, Step: 2, Title: "Types", Description: "Structs"},
+		{StepID: 3, Step: 3, Title: "Errors", Description: "Wrapping"},
+	}
+	to := []StepSnapshot{
+		{StepID: 2, Step: 1, Title: "Types", Description: "Structs and interfaces"},
+		{StepID: 4, Step: 2, Title: "Concurrency", Description: "Goroutines"},
+		{StepID: 1, Step: 3, Title: "Basics", Description: "Syntax"},
+	}
+
+	got := Diff(from, to)
+	want := []struct {
+		typ    string
+		stepID uint
+		fields []string
+	}{
+		{ChangeModified, 2, []string{"step", "description"}},
+		{ChangeAdded, 4, nil},
+		{ChangeModified, 1, []string{"step"}},
+		{ChangeRemoved, 3, nil},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Diff returned %d changes, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		c := got[i]
+		if c.Type != w.typ || c.StepID != w.stepID || !reflect.DeepEqual(c.Fields, w.fields) {
+			t.Errorf("change %d = %s %d %v, want %s %d %v", i, c.Type, c.StepID, c.Fields, w.typ, w.stepID, w.fields)
+		}
+	}
+	if got[1].From != nil || got[1].To == nil || got[1].To.Title != "Concurrency" {
+		t.Errorf("added change = %+v, want only the new step", got[1])
+	}
+	if got[3].To != nil || got[3].From == nil || got[3].From.Title != "Errors" {
+		t.Errorf("removed change = %+v, want only the old step", got[3])
+	}
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	steps := []StepSnapshot{{StepID: 1, Step: 1, Title: "Basics"}}
+	if got := Diff(steps, steps); len(got) != 0 {
+		t.Errorf("Diff of identical snapshots = %+v, want no changes", got)
+	}
+}
@@ -0,0 +1,244 @@
+package roadmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/scoring"
+
+	"gorm.io/gorm"
+)
+
+// Revision sources
+const (
+	SourceGenerate   = "generate"   // initial roadmap generation (or the state before history existed)
+	SourceUserEdit   = "user_edit"  // step added, edited, reordered or deleted by the user
+	SourceRegenerate = "regenerate" // single step rewritten by a regenerate_step job
+	SourceImport     = "import"     // roadmap created from an external source
+	SourceTemplate   = "template"   // roadmap cloned from a curated template
+	SourceRevert     = "revert"     // roadmap restored to an earlier revision
+)
+
+// StepSnapshot is the stored state of one step inside a revision.
+type StepSnapshot struct {
+	StepID      uint   `json:"step_id"`
+	Step        int    `json:"step"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	UserCreated bool   `json:"user_created,omitempty"`
+}
+
+// Snapshot returns the current steps of the project in roadmap order.
+func Snapshot(db *gorm.DB, projectID uint) ([]StepSnapshot, error) {
+	var steps []models.Step
+	if err := db.Where("project_id = ?", projectID).Order("step_number").Find(&steps).Error; err != nil {
+		return nil, err
+	}
+
+	snapshot := make([]StepSnapshot, 0, len(steps))
+	for _, s := range steps {
+		snapshot = append(snapshot, StepSnapshot{
+			StepID:      s.ID,
+			Step:        s.StepNumber,
+			Title:       s.Title,
+			Description: s.Description,
+			UserCreated: s.UserCreated,
+		})
+	}
+	return snapshot, nil
+}
+
+// Steps decodes the snapshot stored in a revision.
+func Steps(rev models.RoadmapRevision) ([]StepSnapshot, error) {
+	var steps []StepSnapshot
+	if len(rev.Steps) == 0 {
+		return steps, nil
+	}
+	err := json.Unmarshal(rev.Steps, &steps)
+	return steps, err
+}
+
+// EnsureBaseline records the current roadmap as the first revision if the
+// project has no history yet. Call it before mutating steps so that projects
+// created before revisions existed keep their original state.
+func EnsureBaseline(db *gorm.DB, projectID, userID uint) error {
+	var count int64
+	if err := db.Model(&models.RoadmapRevision{}).Where("project_id = ?", projectID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err := Record(db, projectID, SourceGenerate, userID, nil)
+	return err
+}
+
+// Record stores the current roadmap as a new immutable revision.
+// Nothing is stored (and nil is returned) if the steps are unchanged since
+// the latest revision.
+func Record(db *gorm.DB, projectID uint, source string, userID uint, jobID *uint) (*models.RoadmapRevision, error) {
+	snapshot, err := Snapshot(db, projectID)
+	if err != nil {
+		return nil, err
+	}
+	stepsBytes, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest models.RoadmapRevision
+	db.Where("project_id = ?", projectID).Order("number desc").Limit(1).Find(&latest)
+	if latest.ID != 0 && bytes.Equal(latest.Steps, stepsBytes) {
+		return nil, nil
+	}
+
+	rev := models.RoadmapRevision{
+		ProjectID: projectID,
+		Number:    latest.Number + 1,
+		Source:    source,
+		UserID:    userID,
+		JobID:     jobID,
+		Steps:     stepsBytes,
+		CreatedAt: time.Now(),
+	}
+	if err := db.Create(&rev).Error; err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// DeleteQuizzes removes the quizzes of a step and the review cards, mistake
+// explanations and reports built on them.
+func DeleteQuizzes(db *gorm.DB, stepID uint) error {
+	quizIDs := db.Model(&models.Quiz{}).Select("id").Where("step_id = ?", stepID)
+	if err := db.Where("quiz_id IN (?)", quizIDs).Delete(&models.ReviewCard{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("quiz_id IN (?)", quizIDs).Delete(&models.MistakeExplanation{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("quiz_id IN (?)", quizIDs).Delete(&models.QuizReport{}).Error; err != nil {
+		return err
+	}
+	return db.Where("step_id = ?", stepID).Delete(&models.Quiz{}).Error
+}
+
+// DeleteQuiz removes a single quiz and the rows built on it. Its answers
+// (and their grade appeals) are removed from the attempts that served it,
+// which are rescored. Reports are kept for the moderator resolving them.
+func DeleteQuiz(db *gorm.DB, quizID uint) error {
+	if err := db.Where("quiz_id = ?", quizID).Delete(&models.ReviewCard{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("quiz_id = ?", quizID).Delete(&models.MistakeExplanation{}).Error; err != nil {
+		return err
+	}
+
+	var attemptIDs []uint
+	if err := db.Model(&models.AttemptAnswer{}).Where("quiz_id = ?", quizID).Distinct().Pluck("attempt_id", &attemptIDs).Error; err != nil {
+		return err
+	}
+	if err := db.Where("attempt_answer_id IN (?)", db.Model(&models.AttemptAnswer{}).Select("id").Where("quiz_id = ?", quizID)).Delete(&models.GradeAppeal{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("quiz_id = ?", quizID).Delete(&models.AttemptAnswer{}).Error; err != nil {
+		return err
+	}
+	for _, id := range attemptIDs {
+		if err := scoring.RefreshAttempt(db, id); err != nil {
+			return err
+		}
+	}
+
+	return db.Delete(&models.Quiz{}, quizID).Error
+}
+
+// DeleteStep removes a step together with the rows that belong to it.
+func DeleteStep(db *gorm.DB, stepID uint) error {
+	if err := DeleteQuizzes(db, stepID); err != nil {
+		return err
+	}
+	if err := db.Where("step_id = ?", stepID).Delete(&models.Score{}).Error; err != nil {
+		return err
+	}
+	attemptIDs := db.Model(&models.QuizAttempt{}).Select("id").Where("step_id = ?", stepID)
+	answerIDs := db.Model(&models.AttemptAnswer{}).Select("id").Where("attempt_id IN (?)", attemptIDs)
+	if err := db.Where("attempt_answer_id IN (?)", answerIDs).Delete(&models.GradeAppeal{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("attempt_id IN (?)", attemptIDs).Delete(&models.AttemptAnswer{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("step_id = ?", stepID).Delete(&models.QuizAttempt{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("conversation_id IN (?)", db.Model(&models.Conversation{}).Select("id").Where("step_id = ?", stepID)).Delete(&models.Message{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("step_id = ?", stepID).Delete(&models.Conversation{}).Error; err != nil {
+		return err
+	}
+	// Study time of a deleted step no longer counts toward the totals
+	if err := db.Where("step_id = ?", stepID).Delete(&models.StudySession{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("step_id = ?", stepID).Delete(&models.Note{}).Error; err != nil {
+		return err
+	}
+	return db.Delete(&models.Step{}, stepID).Error
+}
+
+// Revert restores the project's steps to the given snapshot. Steps that still
+// exist keep their quizzes and scores; steps deleted since the snapshot are
+// recreated under their original ID (without quizzes), and steps added since
+// are deleted.
+func Revert(db *gorm.DB, projectID uint, target []StepSnapshot) error {
+	var current []models.Step
+	if err := db.Where("project_id = ?", projectID).Find(&current).Error; err != nil {
+		return err
+	}
+
+	keep := make(map[uint]bool)
+	for _, s := range target {
+		keep[s.StepID] = true
+	}
+	existing := make(map[uint]bool)
+	for _, s := range current {
+		if !keep[s.ID] {
+			if err := DeleteStep(db, s.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		existing[s.ID] = true
+	}
+
+	for _, s := range target {
+		if existing[s.StepID] {
+			err := db.Model(&models.Step{}).Where("id = ?", s.StepID).Updates(map[string]interface{}{
+				"step_number": s.Step,
+				"title":       s.Title,
+				"description": s.Description,
+			}).Error
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		step := models.Step{
+			ID:          s.StepID,
+			ProjectID:   projectID,
+			StepNumber:  s.Step,
+			Title:       s.Title,
+			Description: s.Description,
+			UserCreated: s.UserCreated,
+		}
+		if err := db.Create(&step).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
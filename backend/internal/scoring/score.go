@@ -0,0 +1,139 @@
+package scoring
+
+import (
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/leaderboard"
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/questions"
+
+	"gorm.io/gorm"
+)
+
+// Percentage returns score/total as a rounded percentage.
+func Percentage(score, total int) int {
+	if total <= 0 {
+		return 0
+	}
+	return (score*100 + total/2) / total
+}
+
+// RefreshStepScore recomputes the derived Score row of a step from its
+// completed attempts. Remedial attempts are not counted. The row is removed
+// when the step has none left.
+func RefreshStepScore(db *gorm.DB, stepID uint) (*models.Score, error) {
+	var attempts []models.QuizAttempt
+	if err := db.Where("step_id = ? AND status = ? AND remedial = ?", stepID, "completed", false).Order("COALESCE(completed_at, created_at), id").Find(&attempts).Error; err != nil {
+		return nil, err
+	}
+
+	var score models.Score
+	db.Where("step_id = ?", stepID).Limit(1).Find(&score)
+
+	if len(attempts) == 0 {
+		if score.ID != 0 {
+			return nil, db.Delete(&score).Error
+		}
+		return nil, nil
+	}
+
+	latest := attempts[len(attempts)-1]
+	best := attempts[0]
+	for _, a := range attempts[1:] {
+		if a.Percentage > best.Percentage || (a.Percentage == best.Percentage && a.Score > best.Score) {
+			best = a
+		}
+	}
+
+	score.StepID = stepID
+	score.Score = latest.Score
+	score.Total = latest.Total
+	score.Percentage = latest.Percentage
+	score.BestScore = best.Score
+	score.BestTotal = best.Total
+	score.BestPercentage = best.Percentage
+	score.AttemptCount = len(attempts)
+	score.UpdatedAt = time.Now()
+
+	if err := db.Save(&score).Error; err != nil {
+		return nil, err
+	}
+	return &score, nil
+}
+
+// RefreshAttempt recomputes an attempt's total and, once it is completed,
+// its score from its answers, e.g. after a free response was graded or a
+// question was fixed or removed, and then the step score.
+func RefreshAttempt(db *gorm.DB, attemptID uint) error {
+	var attempt models.QuizAttempt
+	if err := db.Preload("Answers").First(&attempt, attemptID).Error; err != nil {
+		return err
+	}
+
+	attempt.Total = len(attempt.Answers)
+	if attempt.Status != "completed" {
+		return db.Model(&attempt).Update("total", attempt.Total).Error
+	}
+
+	correct := 0
+	for _, a := range attempt.Answers {
+		if a.IsCorrect {
+			correct++
+		}
+	}
+	previous := attempt.Percentage
+	attempt.Score = correct
+	attempt.Percentage = Percentage(correct, attempt.Total)
+	if err := db.Model(&attempt).Updates(map[string]interface{}{
+		"score":      attempt.Score,
+		"total":      attempt.Total,
+		"percentage": attempt.Percentage,
+	}).Error; err != nil {
+		return err
+	}
+	if !attempt.Remedial {
+		completedAt := attempt.CreatedAt
+		if attempt.CompletedAt != nil {
+			completedAt = *attempt.CompletedAt
+		}
+		if err := leaderboard.AdjustAttempt(db, attempt.UserID, attempt.Percentage-previous, completedAt); err != nil {
+			return err
+		}
+	}
+
+	_, err := RefreshStepScore(db, attempt.StepID)
+	return err
+}
+
+// RegradeQuiz grades every recorded answer to a quiz again, e.g. after its
+// answer was corrected, and refreshes the attempts whose result changed.
+// Free responses keep their grade. It returns the number of answers whose
+// result changed.
+func RegradeQuiz(db *gorm.DB, q models.Quiz) (int, error) {
+	var answers []models.AttemptAnswer
+	if err := db.Where("quiz_id = ? AND answered_at IS NOT NULL AND (grading_status = ? OR grading_status IS NULL)", q.ID, "").Find(&answers).Error; err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	attempts := make(map[uint]bool)
+	for _, a := range answers {
+		resp := questions.ResponseOf(a)
+		isCorrect := questions.Grade(q, resp)
+		if isCorrect == a.IsCorrect {
+			continue
+		}
+		if err := db.Model(&a).Update("is_correct", isCorrect).Error; err != nil {
+			return changed, err
+		}
+		changed++
+		attempts[a.AttemptID] = true
+	}
+
+	for id := range attempts {
+		if err := RefreshAttempt(db, id); err != nil {
+			return changed, err
+		}
+	}
+	return changed, nil
+}
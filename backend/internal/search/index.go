@@ -0,0 +1,170 @@
+package search
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MinTrigramTerm is the shortest term the SQLite trigram indexes can match.
+const MinTrigramTerm = 3
+
+// Index is a full-text index over text columns of a table with an integer
+// id primary key.
+type Index struct {
+	Table   string
+	Columns []string // most important first
+}
+
+// FTS is the name of the SQLite FTS5 table of the index.
+func (ix Index) FTS() string {
+	return ix.Table + "_fts"
+}
+
+// Create builds the index and keeps it in sync with the table from inside
+// the database, so every code path writing the table is covered. On Postgres
+// it is a generated tsvector column named search with a GIN index, earlier
+// columns weighted higher. On SQLite it is an external-content FTS5 table
+// maintained by triggers; FTS5 needs the sqlite_fts5 build tag, and without
+// it the index is skipped and searches fall back to LIKE.
+func Create(db *gorm.DB, ix Index) error {
+	if db.Dialector.Name() == "postgres" {
+		parts := make([]string, len(ix.Columns))
+		for i, col := range ix.Columns {
+			parts[i] = fmt.Sprintf("to_tsvector('simple', coalesce(%s, ''))", col)
+			if len(ix.Columns) > 1 {
+				parts[i] = fmt.Sprintf("setweight(%s, '%c')", parts[i], 'A'+min(i, 3))
+			}
+		}
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS search tsvector GENERATED ALWAYS AS (%s) STORED",
+			ix.Table, strings.Join(parts, " || "))
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+		return db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_search ON %s USING GIN (search)", ix.Table, ix.Table)).Error
+	}
+
+	if HasFTS(db, ix) {
+		return nil
+	}
+	cols := strings.Join(ix.Columns, ", ")
+	// The trigram tokenizer matches substrings, which also works for Japanese
+	create := fmt.Sprintf("CREATE VIRTUAL TABLE %s USING fts5(%s, content='%s', content_rowid='id', tokenize='trigram')",
+		ix.FTS(), cols, ix.Table)
+	if err := db.Exec(create).Error; err != nil {
+		log.Printf("search: full-text index on %s unavailable, using LIKE: %v", ix.Table, err)
+		return nil
+	}
+
+	newValues := "new." + strings.Join(ix.Columns, ", new.")
+	oldValues := "old." + strings.Join(ix.Columns, ", old.")
+	insert := fmt.Sprintf("INSERT INTO %s(rowid, %s) VALUES (new.id, %s);", ix.FTS(), cols, newValues)
+	remove := fmt.Sprintf("INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.id, %s);", ix.FTS(), ix.FTS(), cols, oldValues)
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range []string{
+			fmt.Sprintf("CREATE TRIGGER %s_insert AFTER INSERT ON %s BEGIN %s END", ix.FTS(), ix.Table, insert),
+			fmt.Sprintf("CREATE TRIGGER %s_delete AFTER DELETE ON %s BEGIN %s END", ix.FTS(), ix.Table, remove),
+			fmt.Sprintf("CREATE TRIGGER %s_update AFTER UPDATE OF %s ON %s BEGIN %s %s END", ix.FTS(), cols, ix.Table, remove, insert),
+			fmt.Sprintf("INSERT INTO %s(%s) VALUES ('rebuild')", ix.FTS(), ix.FTS()),
+		} {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// HasFTS reports whether the SQLite FTS5 table of the index exists.
+func HasFTS(db *gorm.DB, ix Index) bool {
+	var n int64
+	db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", ix.FTS()).Scan(&n)
+	return n > 0
+}
+
+// UseFTS reports whether terms can be searched with the SQLite FTS5 table
+// of the index.
+func UseFTS(db *gorm.DB, ix Index, terms []string) bool {
+	return HasFTS(db, ix) && shortest(terms) >= MinTrigramTerm
+}
+
+// shortest returns the length in characters of the shortest term.
+func shortest(terms []string) int {
+	n := -1
+	for _, t := range terms {
+		if l := len([]rune(t)); n < 0 || l < n {
+			n = l
+		}
+	}
+	return n
+}
+
+// MatchExpr quotes each term so FTS5 query syntax in the input is taken
+// literally, and requires all of them.
+func MatchExpr(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(t, `"`, `""`) + `"`
+	}
+	return strings.Join(quoted, " AND ")
+}
+
+// LikeAny adds a condition to q for each term requiring it to appear, case
+// insensitively, in at least one of the columns.
+func LikeAny(q *gorm.DB, columns []string, terms []string) *gorm.DB {
+	for _, t := range terms {
+		pattern := "%" + escapeLike(strings.ToLower(t)) + "%"
+		conds := make([]string, len(columns))
+		args := make([]interface{}, len(columns))
+		for i, col := range columns {
+			conds[i] = "LOWER(" + col + ") LIKE ? ESCAPE '\\'"
+			args[i] = pattern
+		}
+		q = q.Where("("+strings.Join(conds, " OR ")+")", args...)
+	}
+	return q
+}
+
+// escapeLike escapes the LIKE wildcards in s.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Excerpt returns the text around the first occurrence of any of the terms
+// with the match wrapped in [ ], like the full-text snippets.
+func Excerpt(text string, terms []string) string {
+	const context = 40
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		return string(runes[:min(len(runes), 2*context)])
+	}
+
+	at, length := -1, 0
+	for _, term := range terms {
+		needle := []rune(strings.ToLower(term))
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) == string(needle) {
+				if at < 0 || i < at {
+					at, length = i, len(needle)
+				}
+				break
+			}
+		}
+	}
+	if at < 0 {
+		return string(runes[:min(len(runes), 2*context)])
+	}
+
+	start, end := max(0, at-context), min(len(runes), at+length+context)
+	s := string(runes[start:at]) + "[" + string(runes[at:at+length]) + "]" + string(runes[at+length:end])
+	if start > 0 {
+		s = "…" + s
+	}
+	if end < len(runes) {
+		s += "…"
+	}
+	return s
+}
@@ -0,0 +1,268 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github/meso1007/reverse-learn/backend/internal/gating"
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of search results.
+const (
+	KindProject = "project"
+	KindStep    = "step"
+	KindQuiz    = "quiz"
+)
+
+const (
+	// DefaultLimit and MaxLimit bound the number of results.
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+const (
+	// stepDone is true for a step with a score, i.e. a completed quiz.
+	stepDone = "EXISTS (SELECT 1 FROM scores WHERE scores.step_id = steps.id)"
+	// projectDone is true for a project whose steps are all completed.
+	projectDone = "EXISTS (SELECT 1 FROM steps WHERE steps.project_id = projects.id) AND NOT EXISTS (SELECT 1 FROM steps WHERE steps.project_id = projects.id AND NOT " + stepDone + ")"
+)
+
+// source is a kind of content that can be searched.
+type source struct {
+	kind  string
+	index Index
+	// weight ranks the kinds against each other; the relevance of a match
+	// adds less than the weight, so it mostly orders matches of one kind
+	weight float64
+	// fields are the result columns other than the snippet and rank
+	fields string
+	joins  []string
+	where  string // restricts the rows that are searched
+	done   string // true when the matched item is completed
+}
+
+var sources = []source{
+	{
+		kind:   KindProject,
+		index:  Index{Table: "projects", Columns: []string{"goal", "stack"}},
+		weight: 3,
+		fields: "projects.id AS project_id, projects.goal AS project_goal, projects.locale, 0 AS step_number, 0 AS quiz_id, projects.goal AS title",
+		done:   projectDone,
+	},
+	{
+		kind:   KindStep,
+		index:  Index{Table: "steps", Columns: []string{"title", "description"}},
+		weight: 2,
+		fields: "projects.id AS project_id, projects.goal AS project_goal, projects.locale, steps.step_number, 0 AS quiz_id, steps.title",
+		joins:  []string{"JOIN projects ON projects.id = steps.project_id"},
+		done:   stepDone,
+	},
+	{
+		kind:   KindQuiz,
+		index:  Index{Table: "quizzes", Columns: []string{"question"}},
+		weight: 1,
+		fields: "projects.id AS project_id, projects.goal AS project_goal, projects.locale, steps.step_number, quizzes.id AS quiz_id, steps.title",
+		joins: []string{
+			"JOIN steps ON steps.id = quizzes.step_id",
+			"JOIN projects ON projects.id = steps.project_id",
+		},
+		// Hidden questions are not served, so they are not found either
+		where: "NOT quizzes.hidden",
+		done:  stepDone,
+	},
+}
+
+// Setup creates the full-text indexes of all searchable content.
+func Setup(db *gorm.DB) error {
+	for _, src := range sources {
+		if err := Create(db, src.index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidKind reports whether kind names a kind of result.
+func ValidKind(kind string) bool {
+	for _, src := range sources {
+		if src.kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Query is a search of a learner's content.
+type Query struct {
+	UserID    uint
+	Text      string // all words must match
+	Kind      string // only results of this kind when set
+	Locale    string // only projects in this locale when set
+	Completed *bool  // only completed (or uncompleted) projects and steps when set
+	Limit     int
+}
+
+// Result is a matching project, step or quiz question. Steps and quizzes
+// carry the step they belong to; the snippet wraps the matches in [ ].
+type Result struct {
+	Kind        string  `json:"kind"`
+	ProjectID   uint    `json:"project_id"`
+	ProjectGoal string  `json:"project_goal"`
+	Locale      string  `json:"locale"`
+	StepNumber  int     `json:"step_number,omitempty"`
+	QuizID      uint    `json:"quiz_id,omitempty"`
+	Title       string  `json:"title"`
+	Snippet     string  `json:"snippet"`
+	Completed   bool    `json:"completed"`
+	Score       float64 `json:"score"` // relevance; higher is better
+	Rank        float64 `json:"-"`
+	Text        string  `json:"-"`
+}
+
+// Search finds the learner's projects, steps and quiz questions matching
+// all words of the query, best matches first. Locked steps are found by
+// their title only and their questions not at all.
+func Search(db *gorm.DB, q Query) ([]Result, error) {
+	terms := strings.Fields(q.Text)
+	results := []Result{}
+	if len(terms) == 0 {
+		return results, nil
+	}
+
+	for _, src := range sources {
+		if q.Kind != "" && q.Kind != src.kind {
+			continue
+		}
+		found, err := src.search(db, q, terms)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, found...)
+	}
+	results, err := redactLocked(db, results)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results, nil
+}
+
+// search runs the query against one kind of content.
+func (src source) search(db *gorm.DB, q Query, terms []string) ([]Result, error) {
+	table := src.index.Table
+	columns := qualify(table, src.index.Columns)
+	tx := db.Table(table)
+	for _, join := range src.joins {
+		tx = tx.Joins(join)
+	}
+	tx = tx.Where("projects.user_id = ?", q.UserID)
+	if src.where != "" {
+		tx = tx.Where(src.where)
+	}
+	if q.Locale != "" {
+		tx = tx.Where("projects.locale = ?", q.Locale)
+	}
+	if q.Completed != nil {
+		if *q.Completed {
+			tx = tx.Where(src.done)
+		} else {
+			tx = tx.Where("NOT (" + src.done + ")")
+		}
+	}
+	fields := fmt.Sprintf("'%s' AS kind, %s, CASE WHEN %s THEN 1 ELSE 0 END AS completed", src.kind, src.fields, src.done)
+
+	var results []Result
+	var err error
+	switch {
+	case db.Dialector.Name() == "postgres":
+		text := "concat_ws(' — ', " + strings.Join(columns, ", ") + ")"
+		tsq := "plainto_tsquery('simple', ?)"
+		err = tx.Select(fields+", ts_headline('simple', "+text+", "+tsq+
+			", 'StartSel=[, StopSel=], MaxFragments=1, MaxWords=20, MinWords=5') AS snippet, ts_rank("+table+".search, "+tsq+") AS rank",
+			q.Text, q.Text).
+			Where(table+".search @@ "+tsq, q.Text).
+			Order("rank DESC").Limit(q.Limit).Scan(&results).Error
+		for i := range results {
+			results[i].Score = src.score(results[i].Rank)
+		}
+	case UseFTS(db, src.index, terms):
+		fts := src.index.FTS()
+		// bm25 is lower for better matches; the first column counts double
+		weights := "2.0" + strings.Repeat(", 1.0", len(src.index.Columns)-1)
+		err = tx.Select(fields+", snippet("+fts+", -1, '[', ']', '…', 32) AS snippet, bm25("+fts+", "+weights+") AS rank").
+			Joins("JOIN "+fts+" ON "+fts+".rowid = "+table+".id").
+			Where(fts+" MATCH ?", MatchExpr(terms)).
+			Order("rank").Limit(q.Limit).Scan(&results).Error
+		for i := range results {
+			results[i].Score = src.score(-results[i].Rank)
+		}
+	default:
+		text := "COALESCE(" + strings.Join(columns, ", '') || ' — ' || COALESCE(") + ", '')"
+		tx = LikeAny(tx.Select(fields+", "+text+" AS text"), columns, terms)
+		err = tx.Order(table + ".id DESC").Limit(q.Limit).Scan(&results).Error
+		for i := range results {
+			results[i].Snippet = Excerpt(results[i].Text, terms)
+			results[i].Score = src.score(0)
+		}
+	}
+	return results, err
+}
+
+// redactLocked drops the questions of locked steps and replaces the snippet
+// of locked steps with their title, since their content is not shown
+// anywhere else either.
+func redactLocked(db *gorm.DB, results []Result) ([]Result, error) {
+	locks := make(map[uint]map[int]*gating.Lock) // by project ID, then step number
+	kept := results[:0]
+	for _, r := range results {
+		if r.Kind == KindProject {
+			kept = append(kept, r)
+			continue
+		}
+		projectLocks, ok := locks[r.ProjectID]
+		if !ok {
+			var project models.Project
+			if err := db.First(&project, r.ProjectID).Error; err != nil {
+				return nil, err
+			}
+			var err error
+			if projectLocks, err = gating.Locks(db, project); err != nil {
+				return nil, err
+			}
+			locks[r.ProjectID] = projectLocks
+		}
+		if projectLocks[r.StepNumber] != nil {
+			if r.Kind == KindQuiz {
+				continue
+			}
+			r.Snippet = r.Title
+		}
+		kept = append(kept, r)
+	}
+	return kept, nil
+}
+
+// score combines the weight of the kind with the relevance of a match,
+// which is at least 0 and higher for better matches.
+func (src source) score(relevance float64) float64 {
+	relevance = max(relevance, 0)
+	return math.Round(src.weight*(1+relevance/(1+relevance))*1000) / 1000
+}
+
+// qualify prefixes the columns with their table.
+func qualify(table string, columns []string) []string {
+	qualified := make([]string, len(columns))
+	for i, col := range columns {
+		qualified[i] = table + "." + col
+	}
+	return qualified
+}
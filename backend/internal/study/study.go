@@ -0,0 +1,255 @@
+package study
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// HeartbeatInterval is how often clients send a heartbeat while the
+	// learner is on a step.
+	HeartbeatInterval = 30 * time.Second
+	// IdleTimeout is the longest gap between heartbeats that is counted;
+	// after a longer one the learner is taken to have been away.
+	IdleTimeout = 2 * time.Minute
+)
+
+// ErrEnded is returned for a heartbeat on a session that was stopped.
+var ErrEnded = errors.New("study session has ended")
+
+// credit adds the time since the last heartbeat to the session.
+func credit(s *models.StudySession, now time.Time) {
+	gap := now.Sub(s.LastHeartbeatAt)
+	if gap <= 0 {
+		return
+	}
+	if gap <= IdleTimeout {
+		s.Seconds += int(gap.Seconds())
+	}
+	s.LastHeartbeatAt = now
+}
+
+// end credits and stops the session.
+func end(db *gorm.DB, s *models.StudySession, now time.Time) error {
+	credit(s, now)
+	s.EndedAt = &now
+	return db.Save(s).Error
+}
+
+// Start begins a session on the step. A learner studies one step at a time,
+// so their other open sessions are stopped.
+func Start(db *gorm.DB, userID uint, step models.Step, now time.Time) (*models.StudySession, error) {
+	session := &models.StudySession{
+		UserID:          userID,
+		ProjectID:       step.ProjectID,
+		StepID:          step.ID,
+		StartedAt:       now,
+		LastHeartbeatAt: now,
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var open []models.StudySession
+		if err := tx.Where("user_id = ? AND ended_at IS NULL", userID).Find(&open).Error; err != nil {
+			return err
+		}
+		for i := range open {
+			if err := end(tx, &open[i], now); err != nil {
+				return err
+			}
+		}
+		return tx.Create(session).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Heartbeat counts the time since the previous heartbeat.
+func Heartbeat(db *gorm.DB, s *models.StudySession, now time.Time) error {
+	if s.EndedAt != nil {
+		return ErrEnded
+	}
+	credit(s, now)
+	return db.Save(s).Error
+}
+
+// Stop ends the session. Stopping an ended session changes nothing.
+func Stop(db *gorm.DB, s *models.StudySession, now time.Time) error {
+	if s.EndedAt != nil {
+		return nil
+	}
+	return end(db, s, now)
+}
+
+// sums returns the learner's study seconds grouped by a column.
+func sums(db *gorm.DB, userID uint, column string) (map[uint]int, error) {
+	var rows []struct {
+		ID      uint
+		Seconds int
+	}
+	err := db.Model(&models.StudySession{}).
+		Select(column+" AS id, SUM(seconds) AS seconds").
+		Where("user_id = ?", userID).
+		Group(column).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[uint]int, len(rows))
+	for _, r := range rows {
+		result[r.ID] = r.Seconds
+	}
+	return result, nil
+}
+
+// StepSeconds returns the learner's study time per step ID.
+func StepSeconds(db *gorm.DB, userID uint) (map[uint]int, error) {
+	return sums(db, userID, "step_id")
+}
+
+// ProjectSeconds returns the learner's study time per project ID.
+func ProjectSeconds(db *gorm.DB, userID uint) (map[uint]int, error) {
+	return sums(db, userID, "project_id")
+}
+
+// difficultyScores rank the difficulty tags of questions.
+var difficultyScores = map[string]float64{"easy": 1, "medium": 2, "hard": 3}
+
+// impliedDifficulty returns the difficulty the AI implied for a step: the
+// average tag of its questions, rounded to the nearest tag.
+func impliedDifficulty(counts map[string]int) string {
+	total, n := 0.0, 0
+	for d, c := range counts {
+		total += difficultyScores[d] * float64(c)
+		n += c
+	}
+	if n == 0 {
+		return ""
+	}
+	switch math.Round(total / float64(n)) {
+	case 1:
+		return "easy"
+	case 2:
+		return "medium"
+	}
+	return "hard"
+}
+
+// StepTime is the time a learner spent on a step next to its implied difficulty.
+type StepTime struct {
+	StepID     uint    `json:"step_id"`
+	ProjectID  uint    `json:"project_id"`
+	Title      string  `json:"title"`
+	Level      string  `json:"level"`      // the project's learner level
+	Difficulty string  `json:"difficulty"` // implied by the step's questions
+	Seconds    int     `json:"seconds"`
+	Ratio      float64 `json:"ratio"` // seconds over the median of the group
+}
+
+// DifficultyTime is the time spent on the steps of one level and implied
+// difficulty.
+type DifficultyTime struct {
+	Level          string `json:"level"`
+	Difficulty     string `json:"difficulty"`
+	Steps          int    `json:"steps"`
+	AverageSeconds int    `json:"average_seconds"`
+	MedianSeconds  int    `json:"median_seconds"`
+}
+
+// Calibration compares the time learners actually spent on steps with the
+// difficulty the AI implied through the questions it generated. It returns
+// the time per level and difficulty, and the steps whose time is at least
+// outlierRatio times off the median of their group.
+func Calibration(db *gorm.DB, outlierRatio float64) ([]DifficultyTime, []StepTime, error) {
+	var steps []StepTime
+	err := db.Table("study_sessions").
+		Select("study_sessions.step_id, steps.project_id, steps.title, projects.level, SUM(study_sessions.seconds) AS seconds").
+		Joins("JOIN steps ON steps.id = study_sessions.step_id").
+		Joins("JOIN projects ON projects.id = steps.project_id").
+		Group("study_sessions.step_id, steps.project_id, steps.title, projects.level").
+		Having("SUM(study_sessions.seconds) > 0").
+		Scan(&steps).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tags []struct {
+		StepID     uint
+		Difficulty string
+		Count      int
+	}
+	err = db.Model(&models.Quiz{}).
+		Select("step_id, difficulty, COUNT(*) AS count").
+		Where("remedial = ? AND hidden = ? AND difficulty <> ?", false, false, "").
+		Group("step_id, difficulty").
+		Scan(&tags).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	counts := make(map[uint]map[string]int)
+	for _, t := range tags {
+		if counts[t.StepID] == nil {
+			counts[t.StepID] = make(map[string]int)
+		}
+		counts[t.StepID][t.Difficulty] = t.Count
+	}
+
+	// Group the steps with a known difficulty by level and difficulty
+	groups := make(map[[2]string][]int)
+	for i := range steps {
+		steps[i].Difficulty = impliedDifficulty(counts[steps[i].StepID])
+		if steps[i].Difficulty == "" {
+			continue
+		}
+		key := [2]string{steps[i].Level, steps[i].Difficulty}
+		groups[key] = append(groups[key], i)
+	}
+
+	result := make([]DifficultyTime, 0, len(groups))
+	outliers := []StepTime{}
+	for key, idx := range groups {
+		seconds := make([]int, len(idx))
+		sum := 0
+		for j, i := range idx {
+			seconds[j] = steps[i].Seconds
+			sum += steps[i].Seconds
+		}
+		sort.Ints(seconds)
+		median := seconds[len(seconds)/2]
+		if len(seconds)%2 == 0 {
+			median = (seconds[len(seconds)/2-1] + seconds[len(seconds)/2]) / 2
+		}
+		result = append(result, DifficultyTime{
+			Level:          key[0],
+			Difficulty:     key[1],
+			Steps:          len(idx),
+			AverageSeconds: sum / len(idx),
+			MedianSeconds:  median,
+		})
+
+		if median == 0 {
+			continue
+		}
+		for _, i := range idx {
+			steps[i].Ratio = math.Round(float64(steps[i].Seconds)/float64(median)*100) / 100
+			if steps[i].Ratio >= outlierRatio || steps[i].Ratio <= 1/outlierRatio {
+				outliers = append(outliers, steps[i])
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Level != result[j].Level {
+			return result[i].Level < result[j].Level
+		}
+		return difficultyScores[result[i].Difficulty] < difficultyScores[result[j].Difficulty]
+	})
+	sort.Slice(outliers, func(i, j int) bool { return outliers[i].Ratio > outliers[j].Ratio })
+	return result, outliers, nil
+}
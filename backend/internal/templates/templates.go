@@ -0,0 +1,240 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github/meso1007/reverse-learn/backend/internal/adaptive"
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/pool"
+	"github/meso1007/reverse-learn/backend/internal/questions"
+	"github/meso1007/reverse-learn/backend/internal/roadmap"
+	"github/meso1007/reverse-learn/backend/internal/search"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxSteps is the most steps a template can have.
+	MaxSteps = 30
+	// MaxTags is the most tags a template can have.
+	MaxTags = 10
+)
+
+// Step is a step of a template with its quiz pool, in the form the model
+// returns questions in.
+type Step struct {
+	Step        int                   `json:"step"`
+	Title       string                `json:"title"`
+	Description string                `json:"description"`
+	Quizzes     []questions.Generated `json:"quizzes"`
+}
+
+// StepSummary is a step as shown to learners browsing templates; the
+// questions are only revealed in their clone.
+type StepSummary struct {
+	Step        int    `json:"step"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Quizzes     int    `json:"quizzes"`
+}
+
+// Summary is a template as listed to learners.
+type Summary struct {
+	models.RoadmapTemplate
+	Tags    []string      `json:"tags"`
+	Steps   []StepSummary `json:"steps"`
+	Quizzes int           `json:"quizzes"`
+}
+
+// Detail is a template with its quiz pools, for admins.
+type Detail struct {
+	models.RoadmapTemplate
+	Tags  []string `json:"tags"`
+	Steps []Step   `json:"steps"`
+}
+
+// StepsOf decodes the steps stored in a template.
+func StepsOf(t models.RoadmapTemplate) []Step {
+	var steps []Step
+	if len(t.Steps) > 0 {
+		json.Unmarshal(t.Steps, &steps)
+	}
+	return steps
+}
+
+// TagsOf splits the stored tags of a template.
+func TagsOf(t models.RoadmapTemplate) []string {
+	if t.Tags == "" {
+		return []string{}
+	}
+	return strings.Split(t.Tags, ",")
+}
+
+// JoinTags normalizes tags for storage: trimmed, lowercase, unique and sorted.
+func JoinTags(tags []string) (string, error) {
+	seen := make(map[string]bool)
+	var clean []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if strings.Contains(tag, ",") || len([]rune(tag)) > 30 {
+			return "", fmt.Errorf("tags must be at most 30 characters without commas")
+		}
+		seen[tag] = true
+		clean = append(clean, tag)
+	}
+	if len(clean) > MaxTags {
+		return "", fmt.Errorf("a template can have at most %d tags", MaxTags)
+	}
+	sort.Strings(clean)
+	return strings.Join(clean, ","), nil
+}
+
+// Validate checks the steps of a template and numbers them in order. Every
+// question must convert into a quiz, so that clones never fail.
+func Validate(steps []Step) error {
+	if len(steps) == 0 || len(steps) > MaxSteps {
+		return fmt.Errorf("a template needs 1 to %d steps", MaxSteps)
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].Step < steps[j].Step })
+	for i := range steps {
+		steps[i].Step = i + 1
+		if strings.TrimSpace(steps[i].Title) == "" {
+			return fmt.Errorf("step %d has no title", i+1)
+		}
+		if len(steps[i].Quizzes) > pool.Size {
+			return fmt.Errorf("step %d has more than %d questions", i+1, pool.Size)
+		}
+		for j, q := range steps[i].Quizzes {
+			if _, err := q.ToQuiz(0); err != nil {
+				return fmt.Errorf("step %d question %d: %v", i+1, j+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// FromProject returns the steps of a project with their quiz pools, to
+// publish a roadmap that was generated and reviewed as a template. Hidden and
+// remedial questions are left out.
+func FromProject(db *gorm.DB, projectID uint) ([]Step, error) {
+	var steps []models.Step
+	if err := db.Where("project_id = ?", projectID).Order("step_number").Find(&steps).Error; err != nil {
+		return nil, err
+	}
+	result := make([]Step, 0, len(steps))
+	for _, s := range steps {
+		quizzes, err := pool.Quizzes(db, s.ID)
+		if err != nil {
+			return nil, err
+		}
+		step := Step{Step: s.StepNumber, Title: s.Title, Description: s.Description, Quizzes: []questions.Generated{}}
+		for _, q := range quizzes {
+			if !q.Hidden {
+				step.Quizzes = append(step.Quizzes, questions.FromQuiz(q))
+			}
+		}
+		result = append(result, step)
+	}
+	return result, nil
+}
+
+// Summarize returns the template as listed to learners.
+func Summarize(t models.RoadmapTemplate) Summary {
+	s := Summary{RoadmapTemplate: t, Tags: TagsOf(t), Steps: []StepSummary{}}
+	for _, step := range StepsOf(t) {
+		s.Steps = append(s.Steps, StepSummary{
+			Step:        step.Step,
+			Title:       step.Title,
+			Description: step.Description,
+			Quizzes:     len(step.Quizzes),
+		})
+		s.Quizzes += len(step.Quizzes)
+	}
+	return s
+}
+
+// Describe returns the template with its quiz pools.
+func Describe(t models.RoadmapTemplate) Detail {
+	return Detail{RoadmapTemplate: t, Tags: TagsOf(t), Steps: StepsOf(t)}
+}
+
+// Filter narrows a template listing. Empty fields match everything.
+type Filter struct {
+	Query         string // words that must all appear in the title, goal, stack, description or tags
+	Tag           string
+	Level         string
+	Locale        string
+	PublishedOnly bool
+}
+
+// List returns the templates matching the filter, most cloned first.
+func List(db *gorm.DB, f Filter) ([]models.RoadmapTemplate, error) {
+	q := db.Model(&models.RoadmapTemplate{})
+	if f.PublishedOnly {
+		q = q.Where("published = ?", true)
+	}
+	if f.Level != "" {
+		q = q.Where("level = ?", f.Level)
+	}
+	if f.Locale != "" {
+		q = q.Where("locale = ?", f.Locale)
+	}
+	if tag := strings.ToLower(strings.TrimSpace(f.Tag)); tag != "" {
+		q = q.Where("',' || tags || ',' LIKE ?", "%,"+tag+",%")
+	}
+	if terms := strings.Fields(f.Query); len(terms) > 0 {
+		q = search.LikeAny(q, []string{"title", "goal", "stack", "description", "tags"}, terms)
+	}
+
+	var list []models.RoadmapTemplate
+	err := q.Order("clone_count DESC, title").Find(&list).Error
+	return list, err
+}
+
+// Clone creates a project for the user from the template, with its steps and
+// quiz pools, without calling the model.
+func Clone(db *gorm.DB, t models.RoadmapTemplate, userID uint) (models.Project, error) {
+	project := models.Project{
+		UserID:     userID,
+		Goal:       t.Goal,
+		Stack:      t.Stack,
+		Level:      t.Level,
+		Locale:     t.Locale,
+		TemplateID: &t.ID,
+		CreatedAt:  time.Now(),
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&project).Error; err != nil {
+			return err
+		}
+		for _, s := range StepsOf(t) {
+			step := models.Step{ProjectID: project.ID, StepNumber: s.Step, Title: s.Title, Description: s.Description}
+			if err := tx.Create(&step).Error; err != nil {
+				return err
+			}
+			for _, g := range s.Quizzes {
+				quiz, err := g.ToQuiz(step.ID)
+				if err != nil {
+					return fmt.Errorf("step %d: %v", s.Step, err)
+				}
+				quiz.Difficulty = adaptive.NormalizeDifficulty(quiz.Difficulty)
+				if err := tx.Create(&quiz).Error; err != nil {
+					return err
+				}
+			}
+		}
+		if _, err := roadmap.Record(tx, project.ID, roadmap.SourceTemplate, userID, nil); err != nil {
+			return err
+		}
+		return tx.Model(&models.RoadmapTemplate{}).Where("id = ?", t.ID).
+			UpdateColumn("clone_count", gorm.Expr("clone_count + 1")).Error
+	})
+	return project, err
+}
@@ -0,0 +1,72 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+
+	"github/meso1007/reverse-learn/backend/internal/questions"
+)
+
+func TestJoinTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		want    string
+		wantErr bool
+	}{
+		{"normalized", []string{" Go ", "backend", "go", ""}, "backend,go", false},
+		{"none", nil, "", false},
+		{"comma", []string{"a,b"}, "", true},
+		{"too long", []string{strings.Repeat("x", 31)}, "", true},
+		{"japanese counts runes", []string{strings.Repeat("語", 30)}, strings.Repeat("語", 30), false},
+		{"too many", []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}, "", true},
+		{"duplicates do not count", []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "J"}, "a,b,c,d,e,f,g,h,i,j", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JoinTags(tt.tags)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("JoinTags error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("JoinTags = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	good := questions.Generated{Question: "Q", Options: []string{"a", "b"}, AnswerIndex: 0}
+	bad := questions.Generated{Type: questions.TypeMultipleSelect, Question: "Q", Options: []string{"a", "b", "c"}, AnswerIndexes: []int{0}}
+
+	tests := []struct {
+		name    string
+		steps   []Step
+		wantErr bool
+	}{
+		{"valid", []Step{{Step: 1, Title: "A", Quizzes: []questions.Generated{good}}}, false},
+		{"no steps", nil, true},
+		{"too many steps", make([]Step, MaxSteps+1), true},
+		{"missing title", []Step{{Step: 1, Title: " "}}, true},
+		{"invalid question", []Step{{Step: 1, Title: "A", Quizzes: []questions.Generated{good, bad}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.steps); (err != nil) != tt.wantErr {
+				t.Errorf("Validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateNumbersSteps(t *testing.T) {
+	steps := []Step{{Step: 7, Title: "C"}, {Step: 2, Title: "A"}, {Step: 5, Title: "B"}}
+	if err := Validate(steps); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"A", "B", "C"} {
+		if steps[i].Step != i+1 || steps[i].Title != want {
+			t.Errorf("step %d = %d %q, want %d %q", i, steps[i].Step, steps[i].Title, i+1, want)
+		}
+	}
+}
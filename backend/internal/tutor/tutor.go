@@ -0,0 +1,116 @@
+package tutor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
+)
+
+// HistoryLimit is the number of earlier messages sent to the model with
+// each question.
+const HistoryLimit = 20
+
+// Tutor answers a learner's questions about a step of their roadmap.
+type Tutor struct {
+	Model *genai.GenerativeModel
+}
+
+func New(model *genai.GenerativeModel) *Tutor {
+	return &Tutor{Model: model}
+}
+
+// Stream answers message given the conversation so far, calling onChunk with
+// each piece of the answer as it arrives, and returns the whole answer.
+func (t *Tutor) Stream(ctx context.Context, project models.Project, step models.Step, history []models.Message, message string, onChunk func(string) error) (string, error) {
+	// The model is shared, so the instruction is set on a copy
+	model := *t.Model
+	model.SystemInstruction = genai.NewUserContent(genai.Text(instruction(project, step)))
+
+	cs := model.StartChat()
+	if len(history) > HistoryLimit {
+		history = history[len(history)-HistoryLimit:]
+	}
+	for _, m := range history {
+		role := "user"
+		if m.Role == "assistant" {
+			role = "model"
+		}
+		cs.History = append(cs.History, &genai.Content{Role: role, Parts: []genai.Part{genai.Text(m.Content)}})
+	}
+
+	var answer strings.Builder
+	iter := cs.SendMessageStream(ctx, genai.Text(message))
+	for {
+		resp, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return answer.String(), err
+		}
+		if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+			continue
+		}
+		for _, part := range resp.Candidates[0].Content.Parts {
+			txt, ok := part.(genai.Text)
+			if !ok || txt == "" {
+				continue
+			}
+			answer.WriteString(string(txt))
+			if err := onChunk(string(txt)); err != nil {
+				return answer.String(), err
+			}
+		}
+	}
+
+	if answer.Len() == 0 {
+		return "", fmt.Errorf("empty response")
+	}
+	return answer.String(), nil
+}
+
+// instruction is the system prompt giving the tutor the project and step.
+func instruction(project models.Project, step models.Step) string {
+	if project.Locale == "en" {
+		return fmt.Sprintf(`You are an expert engineering mentor tutoring a learner through one step of their learning roadmap.
+
+# Project Info
+- Goal: %s
+- Tech Stack: %s
+- Level: %s
+
+# Current Step
+- Step %d: %s
+- Content: %s
+
+# Rules
+1. Answer questions about this step, and about how it fits the project goal.
+2. Explain at the learner's level, with short code examples in the project's tech stack where they help.
+3. Guide the learner to understand rather than handing over complete solutions.
+4. If a question is unrelated to the project, answer briefly and steer back to the step.
+5. Answer in English, in Markdown.`, project.Goal, project.Stack, project.Level, step.StepNumber, step.Title, step.Description)
+	}
+
+	return fmt.Sprintf(`あなたは熟練のエンジニアメンターとして、学習ロードマップの1つのステップについて学習者を指導します。
+
+# プロジェクト情報
+- 目標: %s
+- 技術スタック: %s
+- レベル: %s
+
+# 現在のステップ
+- Step %d: %s
+- 内容: %s
+
+# ルール
+1. このステップについての質問、およびステップがプロジェクトの目標にどう関わるかについて答えてください。
+2. 学習者のレベルに合わせ、役立つ場合はプロジェクトの技術スタックで短いコード例を示してください。
+3. 完成した答えを渡すのではなく、学習者が理解できるように導いてください。
+4. プロジェクトに関係のない質問には簡潔に答え、ステップの内容に話を戻してください。
+5. 日本語で、Markdown形式で回答してください。`, project.Goal, project.Stack, project.Level, step.StepNumber, step.Title, step.Description)
+}
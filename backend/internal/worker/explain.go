@@ -0,0 +1,178 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/questions"
+)
+
+// explainMistake writes a targeted explanation of why the learner's wrong
+// response is wrong and caches it for the question and response.
+func (w *Worker) explainMistake(ctx context.Context, job *models.Job) ([]byte, error) {
+	var req models.ExplainMistakeRequest
+	if err := json.Unmarshal(job.Input, &req); err != nil {
+		return nil, fmt.Errorf("invalid job input: %v", err)
+	}
+
+	var answer models.AttemptAnswer
+	if err := w.DB.First(&answer, req.AttemptAnswerID).Error; err != nil {
+		return nil, fmt.Errorf("answer not found")
+	}
+	var attempt models.QuizAttempt
+	if err := w.DB.Where("id = ? AND user_id = ?", answer.AttemptID, job.UserID).First(&attempt).Error; err != nil {
+		return nil, fmt.Errorf("attempt not found")
+	}
+	var quiz models.Quiz
+	if err := w.DB.First(&quiz, answer.QuizID).Error; err != nil {
+		return nil, fmt.Errorf("question not found")
+	}
+	var step models.Step
+	w.DB.First(&step, quiz.StepID)
+	var project models.Project
+	w.DB.First(&project, step.ProjectID)
+
+	resp := questions.ResponseOf(answer)
+	key := questions.ResponseKey(quiz, resp)
+
+	// Another learner's job may have written it while this one was queued
+	var cached models.MistakeExplanation
+	w.DB.Where("quiz_id = ? AND response_key = ? AND locale = ?", quiz.ID, key, project.Locale).Limit(1).Find(&cached)
+	if cached.ID != 0 {
+		return mistakeResult(cached)
+	}
+
+	optionsText := ""
+	for i, o := range questions.Options(quiz) {
+		optionsText += fmt.Sprintf("  %d. %s\n", i+1, o)
+	}
+	if optionsText == "" {
+		optionsText = "  -\n"
+	}
+	code := quiz.Code
+	if code == "" {
+		code = "-"
+	}
+
+	var prompt string
+	if project.Locale == "en" {
+		prompt = fmt.Sprintf(`
+You are an expert engineering mentor.
+A learner answered the question below incorrectly. Explain why their answer is wrong.
+
+# Step
+- %s
+
+# Question
+%s
+
+# Code
+%s
+
+# Options
+%s
+# Correct Answer
+%s
+
+# General Explanation
+%s
+
+# Learner's Answer
+%s
+
+# Rules
+1. Address this specific answer: explain the misunderstanding that likely led to it and why it does not work.
+2. Then explain briefly why the correct answer is right.
+3. Name the one concept the learner should revisit, in a few words.
+4. Keep it short (3-6 sentences) and encouraging.
+5. **IMPORTANT: The output MUST be in English.**
+
+# Output JSON Format
+{
+  "explanation": "Why the answer is wrong...",
+  "concept": "Concept to revisit..."
+}
+`, step.Title, quiz.Question, code, optionsText, questions.AnswerText(quiz), quiz.Explanation, questions.ResponseText(quiz, resp))
+	} else {
+		prompt = fmt.Sprintf(`
+あなたは熟練のエンジニアメンターです。
+学習者が以下の問題に不正解でした。その回答がなぜ間違っているのかを説明してください。
+
+# ステップ
+- %s
+
+# 問題
+%s
+
+# コード
+%s
+
+# 選択肢
+%s
+# 正解
+%s
+
+# 一般的な解説
+%s
+
+# 学習者の回答
+%s
+
+# ルール
+1. この回答に即して、間違いにつながったと考えられる誤解と、なぜそれが成り立たないのかを説明してください。
+2. その後、正解がなぜ正しいのかを簡潔に説明してください。
+3. 復習すべき概念を1つ、短く挙げてください。
+4. 短く（3〜6文）、前向きな表現にしてください。
+5. **重要: 出力は必ず日本語で行ってください。**
+
+# 出力JSONフォーマット
+{
+  "explanation": "回答が間違っている理由...",
+  "concept": "復習すべき概念..."
+}
+`, step.Title, quiz.Question, code, optionsText, questions.AnswerText(quiz), quiz.Explanation, questions.ResponseText(quiz, resp))
+	}
+
+	jsonBytes, err := w.generateJSON(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	var explainResp struct {
+		Explanation string `json:"explanation"`
+		Concept     string `json:"concept"`
+	}
+	if err := json.Unmarshal(jsonBytes, &explainResp); err != nil {
+		return nil, fmt.Errorf("failed to parse explanation json: %v", err)
+	}
+	if explainResp.Explanation == "" {
+		return nil, fmt.Errorf("empty explanation generated")
+	}
+
+	explanation := models.MistakeExplanation{
+		QuizID:      quiz.ID,
+		ResponseKey: key,
+		Locale:      project.Locale,
+		Explanation: explainResp.Explanation,
+		Concept:     explainResp.Concept,
+	}
+	if err := w.DB.Create(&explanation).Error; err != nil {
+		// Lost a race with another job for the same response; use its explanation
+		if w.DB.Where("quiz_id = ? AND response_key = ? AND locale = ?", quiz.ID, key, project.Locale).First(&cached).Error == nil {
+			return mistakeResult(cached)
+		}
+		return nil, fmt.Errorf("failed to save explanation: %v", err)
+	}
+
+	return mistakeResult(explanation)
+}
+
+func mistakeResult(e models.MistakeExplanation) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"quiz_id":     e.QuizID,
+		"explanation": e.Explanation,
+		"concept":     e.Concept,
+	})
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github/meso1007/reverse-learn/backend/internal/models"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// generateJSON sends the prompt to the model and returns the response body
+// with any surrounding markdown code fence removed.
+func (w *Worker) generateJSON(ctx context.Context, prompt string) ([]byte, error) {
+	resp, err := w.GenModel.GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return nil, fmt.Errorf("empty response")
+	}
+	txt, ok := resp.Candidates[0].Content.Parts[0].(genai.Text)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response format")
+	}
+
+	jsonStr := strings.TrimSpace(string(txt))
+	jsonStr = strings.TrimPrefix(jsonStr, "```json")
+	jsonStr = strings.TrimSuffix(jsonStr, "```")
+	return []byte(jsonStr), nil
+}
+
+// existingQuestionLength limits how much of each existing question is quoted in a prompt.
+const existingQuestionLength = 150
+
+// existingSection lists the questions already in a step's pool for the quiz
+// generation prompt, so that new questions do not repeat them.
+func existingSection(locale string, existing []models.Quiz) string {
+	if len(existing) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	if locale == "en" {
+		b.WriteString("\n# Existing Questions\n")
+	} else {
+		b.WriteString("\n# 既存の問題\n")
+	}
+	for _, q := range existing {
+		text := strings.Join(strings.Fields(q.Question), " ")
+		if runes := []rune(text); len(runes) > existingQuestionLength {
+			text = string(runes[:existingQuestionLength]) + "…"
+		}
+		fmt.Fprintf(&b, "- %s\n", text)
+	}
+	return b.String()
+}
@@ -0,0 +1,164 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github/meso1007/reverse-learn/backend/internal/gating"
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/roadmap"
+)
+
+// regenerateStep rewrites the description of a single step, using the other
+// steps of the project as context so the roadmap stays consistent.
+func (w *Worker) regenerateStep(ctx context.Context, job *models.Job) ([]byte, error) {
+	var req models.RegenerateStepRequest
+	if err := json.Unmarshal(job.Input, &req); err != nil {
+		return nil, fmt.Errorf("invalid job input: %v", err)
+	}
+
+	var project models.Project
+	if err := w.DB.Where("id = ? AND user_id = ?", req.ProjectID, job.UserID).First(&project).Error; err != nil {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	var steps []models.Step
+	w.DB.Where("project_id = ?", project.ID).Order("step_number").Find(&steps)
+
+	var target *models.Step
+	stepsText := ""
+	for i, s := range steps {
+		marker := ""
+		if s.StepNumber == req.StepNumber {
+			target = &steps[i]
+			marker = " <<<"
+		}
+		stepsText += fmt.Sprintf("  - Step %d: %s%s\n    %s\n", s.StepNumber, s.Title, marker, s.Description)
+	}
+	if target == nil {
+		return nil, fmt.Errorf("step not found")
+	}
+
+	feedback := req.Feedback
+	var prompt string
+	if project.Locale == "en" {
+		if feedback == "" {
+			feedback = "(none)"
+		}
+		prompt = fmt.Sprintf(`
+You are an expert engineering mentor.
+The user is following the learning roadmap below and is not satisfied with one step.
+Rewrite only the description of the target step.
+
+# Project Info
+- Goal: %s
+- Tech Stack: %s
+- Level: %s
+
+# Current Roadmap (target step is marked with <<<)
+%s
+
+# Target Step
+- Step %d: %s
+
+# User Feedback
+%s
+
+# Rules
+1. Keep the step title and its position in the roadmap. Do not repeat what the previous and next steps cover.
+2. Address the user feedback if provided.
+3. Adjust the depth of the explanation to the user level (%s).
+4. **IMPORTANT: The output MUST be in English.**
+
+# Output JSON Format
+{
+  "description": "Rewritten description..."
+}
+`, project.Goal, project.Stack, project.Level, stepsText, target.StepNumber, target.Title, feedback, project.Level)
+	} else {
+		if feedback == "" {
+			feedback = "（なし）"
+		}
+		prompt = fmt.Sprintf(`
+あなたは熟練のエンジニアメンターです。
+ユーザーは以下の学習ロードマップに取り組んでいますが、1つのステップの内容に満足していません。
+対象ステップの説明のみを書き直してください。
+
+# プロジェクト情報
+- 目標: %s
+- 技術スタック: %s
+- レベル: %s
+
+# 現在のロードマップ（対象ステップは <<< で示しています）
+%s
+
+# 対象ステップ
+- Step %d: %s
+
+# ユーザーからのフィードバック
+%s
+
+# ルール
+1. ステップのタイトルとロードマップ上の位置は変えないでください。前後のステップの内容と重複させないでください。
+2. フィードバックがある場合はそれに対応してください。
+3. ユーザーのレベル（%s）に合わせて説明の深さを調整してください。
+4. **重要: 出力は必ず日本語で行ってください。**
+
+# 出力JSONフォーマット
+{
+  "description": "書き直した説明..."
+}
+`, project.Goal, project.Stack, project.Level, stepsText, target.StepNumber, target.Title, feedback, project.Level)
+	}
+
+	jsonBytes, err := w.generateJSON(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	var stepResp struct {
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(jsonBytes, &stepResp); err != nil {
+		return nil, fmt.Errorf("failed to parse step json: %v", err)
+	}
+	if stepResp.Description == "" {
+		return nil, fmt.Errorf("empty description")
+	}
+
+	if err := roadmap.EnsureBaseline(w.DB, project.ID, job.UserID); err != nil {
+		return nil, fmt.Errorf("failed to record revision: %v", err)
+	}
+
+	target.Description = stepResp.Description
+	if err := w.DB.Save(target).Error; err != nil {
+		return nil, fmt.Errorf("failed to update step: %v", err)
+	}
+
+	if _, err := roadmap.Record(w.DB, project.ID, roadmap.SourceRegenerate, job.UserID, &job.ID); err != nil {
+		log.Printf("Worker: Failed to record revision for project %d: %v", project.ID, err)
+	}
+
+	// Old quizzes may no longer match the new description
+	if req.InvalidateQuizzes {
+		if err := roadmap.DeleteQuizzes(w.DB, target.ID); err != nil {
+			log.Printf("Worker: Failed to invalidate quizzes for step %d: %v", target.ID, err)
+		}
+	}
+
+	// The step may have been locked while the job was queued
+	description := target.Description
+	if lock, err := gating.StepLock(w.DB, project, target.StepNumber); err != nil || lock != nil {
+		description = ""
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"project_id":          project.ID,
+		"step":                target.StepNumber,
+		"title":               target.Title,
+		"description":         description,
+		"quizzes_invalidated": req.InvalidateQuizzes,
+	})
+}
@@ -0,0 +1,195 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github/meso1007/reverse-learn/backend/internal/adaptive"
+	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/questions"
+
+	"gorm.io/gorm"
+)
+
+// remedialQuizCount is the number of questions in a remedial quiz.
+const remedialQuizCount = 5
+
+// generateRemedialQuiz creates a short, easier quiz for a step that targets
+// the questions the learner missed in a low-scoring attempt. It replaces any
+// previous remedial quiz of the step.
+func (w *Worker) generateRemedialQuiz(ctx context.Context, job *models.Job) ([]byte, error) {
+	var req models.RemedialQuizRequest
+	if err := json.Unmarshal(job.Input, &req); err != nil {
+		return nil, fmt.Errorf("invalid job input: %v", err)
+	}
+
+	var project models.Project
+	if err := w.DB.Where("id = ? AND user_id = ?", req.ProjectID, job.UserID).First(&project).Error; err != nil {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	var step models.Step
+	if err := w.DB.Where("project_id = ? AND step_number = ?", project.ID, req.StepNumber).First(&step).Error; err != nil {
+		return nil, fmt.Errorf("step not found")
+	}
+
+	var missed []models.Quiz
+	w.DB.Joins("JOIN attempt_answers ON attempt_answers.quiz_id = quizzes.id").
+		Where("attempt_answers.attempt_id = ? AND attempt_answers.is_correct = ?", req.AttemptID, false).
+		Order("attempt_answers.position").
+		Find(&missed)
+	if len(missed) == 0 {
+		return nil, fmt.Errorf("no missed questions in attempt %d", req.AttemptID)
+	}
+
+	missedText := ""
+	for _, q := range missed {
+		topic := q.Topic
+		if topic == "" {
+			topic = "-"
+		}
+		missedText += fmt.Sprintf("  - [%s] %s\n    Answer: %s\n    Explanation: %s\n", topic, q.Question, questions.AnswerText(q), q.Explanation)
+	}
+
+	// Remedial quizzes stick to the simpler question types
+	typesInfo := questions.PromptSection(project.Locale, questions.SimpleTypes)
+
+	var prompt string
+	if project.Locale == "en" {
+		prompt = fmt.Sprintf(`
+You are an expert engineering mentor.
+The learner scored poorly on the quiz for the step below. Create a short remedial quiz that helps them master the concepts they missed.
+
+# Project Info
+- Goal: %s
+- Tech Stack: %s
+- Level: %s
+
+# Target Step
+- Step %d: %s
+- Content: %s
+
+# Questions the learner missed
+%s
+# Rules
+1. Create %d questions that each revisit one of the missed concepts from a different angle. Do not repeat the questions above.
+2. Make them easier than the original questions: mostly "easy", at most one "medium".
+3. Tag each quiz with its difficulty and the concept it tests in a few words.
+4. Provide detailed, step-by-step explanations that address the likely misunderstanding.
+5. **IMPORTANT: The output MUST be in English.**
+
+%s
+# Output JSON Format
+{
+  "quizzes": [
+    {
+      "type": "multiple_choice",
+      "question": "Question text...",
+      "options": ["Option A", "Option B", "Option C", "Option D"],
+      "answer_index": 0,
+      "explanation": "Explanation...",
+      "difficulty": "easy",
+      "topic": "Concept tested..."
+    }
+  ]
+}
+`, project.Goal, project.Stack, project.Level, step.StepNumber, step.Title, step.Description, missedText, remedialQuizCount, typesInfo)
+	} else {
+		prompt = fmt.Sprintf(`
+あなたは熟練のエンジニアメンターです。
+学習者は以下のステップのクイズで低い点数を取りました。間違えた概念を身につけるための短い復習クイズを作成してください。
+
+# プロジェクト情報
+- 目標: %s
+- 技術スタック: %s
+- レベル: %s
+
+# 対象ステップ
+- Step %d: %s
+- 内容: %s
+
+# 学習者が間違えた問題
+%s
+# ルール
+1. 間違えた概念をそれぞれ別の角度から問い直す問題を%d問作成してください。上記の問題をそのまま繰り返さないでください。
+2. 元の問題より易しくしてください（基本は "easy"、"medium" は1問まで）。
+3. 各クイズに難易度と、問う概念を短く付けてください。
+4. つまずきやすいポイントに触れながら、段階的で詳しい解説を付けてください。
+5. **重要: 出力は必ず日本語で行ってください。**
+
+%s
+# 出力JSONフォーマット
+{
+  "quizzes": [
+    {
+      "type": "multiple_choice",
+      "question": "問題文...",
+      "options": ["選択肢A", "選択肢B", "選択肢C", "選択肢D"],
+      "answer_index": 0,
+      "explanation": "解説...",
+      "difficulty": "easy",
+      "topic": "問う概念..."
+    }
+  ]
+}
+`, project.Goal, project.Stack, project.Level, step.StepNumber, step.Title, step.Description, missedText, remedialQuizCount, typesInfo)
+	}
+
+	jsonBytes, err := w.generateJSON(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	var quizResp struct {
+		Quizzes []questions.Generated `json:"quizzes"`
+	}
+	if err := json.Unmarshal(jsonBytes, &quizResp); err != nil {
+		return nil, fmt.Errorf("failed to parse quiz json: %v", err)
+	}
+	if len(quizResp.Quizzes) == 0 {
+		return nil, fmt.Errorf("no quizzes generated")
+	}
+
+	var quizzesResult []questions.Preview
+	err = w.DB.Transaction(func(tx *gorm.DB) error {
+		old := tx.Model(&models.Quiz{}).Select("id").Where("step_id = ? AND remedial = ?", step.ID, true)
+		if err := tx.Where("quiz_id IN (?)", old).Delete(&models.ReviewCard{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("quiz_id IN (?)", old).Delete(&models.MistakeExplanation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("step_id = ? AND remedial = ?", step.ID, true).Delete(&models.Quiz{}).Error; err != nil {
+			return err
+		}
+
+		for _, q := range quizResp.Quizzes {
+			quiz, err := q.ToQuiz(step.ID)
+			if err != nil {
+				log.Printf("Worker: Skipping invalid remedial quiz for step %d: %v", step.ID, err)
+				continue
+			}
+			quiz.Difficulty = adaptive.NormalizeDifficulty(quiz.Difficulty)
+			quiz.Remedial = true
+			if err := tx.Create(&quiz).Error; err != nil {
+				return err
+			}
+			quizzesResult = append(quizzesResult, questions.PreviewOf(quiz))
+		}
+		if len(quizzesResult) == 0 {
+			return fmt.Errorf("no valid quizzes generated")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save remedial quiz: %v", err)
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"project_id": project.ID,
+		"step":       step.StepNumber,
+		"quizzes":    quizzesResult,
+	})
+}
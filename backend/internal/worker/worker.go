@@ -7,23 +7,30 @@ import (
 	"log"
 	"time"
 
+	"github/meso1007/reverse-learn/backend/internal/adaptive"
+	"github/meso1007/reverse-learn/backend/internal/certificate"
 	"github/meso1007/reverse-learn/backend/internal/models"
+	"github/meso1007/reverse-learn/backend/internal/pool"
+	"github/meso1007/reverse-learn/backend/internal/questions"
+	"github/meso1007/reverse-learn/backend/internal/roadmap"
 
 	"github.com/google/generative-ai-go/genai"
 	"gorm.io/gorm"
 )
 
 type Worker struct {
-	DB       *gorm.DB
-	GenModel *genai.GenerativeModel
-	JobQueue chan uint
+	DB           *gorm.DB
+	GenModel     *genai.GenerativeModel
+	JobQueue     chan uint
+	Certificates *certificate.Signer // signs certificates earned once answers are graded
 }
 
-func NewWorker(db *gorm.DB, model *genai.GenerativeModel, queueSize int) *Worker {
+func NewWorker(db *gorm.DB, model *genai.GenerativeModel, certificates *certificate.Signer, queueSize int) *Worker {
 	return &Worker{
-		DB:       db,
-		GenModel: model,
-		JobQueue: make(chan uint, queueSize),
+		DB:           db,
+		GenModel:     model,
+		JobQueue:     make(chan uint, queueSize),
+		Certificates: certificates,
 	}
 }
 
@@ -318,6 +325,10 @@ Based on the user's request below, create a learning roadmap.
 									})
 								}
 
+								if _, revErr := roadmap.Record(w.DB, project.ID, roadmap.SourceGenerate, job.UserID, &job.ID); revErr != nil {
+									log.Printf("Worker: Failed to record revision for project %d: %v", project.ID, revErr)
+								}
+
 								// Return result with Project ID
 								resultMap := map[string]interface{}{
 									"id":      project.ID,
@@ -338,11 +349,56 @@ Based on the user's request below, create a learning roadmap.
 				var req models.GenerateStepQuizRequest
 				json.Unmarshal(job.Input, &req)
 
+				// Find Project
+				var project models.Project
+				w.DB.Where("user_id = ? AND goal = ?", job.UserID, req.Goal).First(&project)
+				if project.ID == 0 {
+					w.DB.Where("user_id = ?", job.UserID).Order("created_at desc").First(&project)
+				}
+
+				// Tailor the questions to how the learner has done so far
+				profile := &adaptive.Profile{Level: req.Level}
+				if project.ID != 0 {
+					if built, buildErr := adaptive.Build(w.DB, project); buildErr == nil {
+						profile = built
+					} else {
+						log.Printf("Worker: Failed to build learner profile for project %d: %v", project.ID, buildErr)
+					}
+				}
+				if profile.Mix == nil {
+					profile.Mix = adaptive.DefaultMix(req.Level)
+				}
+
+				// Add a batch to the step's question pool, avoiding the questions already in it
+				var poolStep models.Step
+				var existing []models.Quiz
+				if project.ID != 0 {
+					w.DB.Where("project_id = ? AND step_number = ?", project.ID, req.StepNumber).First(&poolStep)
+				}
+				if poolStep.ID != 0 {
+					existing, _ = pool.Quizzes(w.DB, poolStep.ID)
+				}
+				count := pool.BatchSize
+				if room := pool.Room(existing); room < count {
+					count = room
+				}
+				if count == 0 {
+					err = fmt.Errorf("question pool of step %d is full", req.StepNumber)
+					break
+				}
+				existingTexts := make([]string, len(existing))
+				for i, q := range existing {
+					existingTexts[i] = pool.Text(q)
+				}
+				profile.Mix = adaptive.ScaleMix(profile.Mix, count)
+				learnerInfo := profile.PromptSection(req.Locale) + existingSection(req.Locale, existing)
+				typesInfo := questions.PromptSection(req.Locale, questions.TypesForLevel(req.Level))
+
 				var prompt string
 				if req.Locale == "en" {
 					prompt = fmt.Sprintf(`
 You are an expert engineering mentor.
-Create 10 multiple-choice quizzes to check understanding for the following learning step.
+Create %d quizzes to check understanding for the following learning step.
 
 # Project Info
 - Goal: %s
@@ -353,29 +409,45 @@ Create 10 multiple-choice quizzes to check understanding for the following learn
 - Step %d: %s
 - Content: %s
 
+%s
 # Rules
-1. Create 10 questions testing knowledge required for implementing this step or related concepts.
-2. Adjust difficulty according to user level (%s).
-3. Balance basic and advanced questions.
-4. Provide detailed explanations for each quiz.
-5. **IMPORTANT: The output MUST be in English, even if the provided project info or step content is in another language.**
+1. Create %d questions testing knowledge required for implementing this step or related concepts. Do not repeat or reword any of the existing questions above.
+2. Follow the difficulty mix above, starting from the user level (%s) and adjusting to the learner's results.
+3. If there are concepts the learner got wrong, include questions that revisit them from a different angle when they relate to this step.
+4. Tag each quiz with its difficulty ("easy", "medium" or "hard") and the concept it tests in a few words.
+5. Provide detailed explanations for each quiz.
+6. **IMPORTANT: The output MUST be in English, even if the provided project info or step content is in another language.**
 
+%s
 # Output JSON Format
 {
   "quizzes": [
     {
+      "type": "multiple_choice",
       "question": "Question text...",
       "options": ["Option A", "Option B", "Option C", "Option D"],
       "answer_index": 0,
-      "explanation": "Explanation..."
+      "explanation": "Explanation...",
+      "difficulty": "medium",
+      "topic": "Concept tested..."
+    },
+    {
+      "type": "code_output",
+      "question": "What does this code print?",
+      "code": "fmt.Println(len(\"go\"))",
+      "options": [],
+      "accepted_answers": ["2"],
+      "explanation": "Explanation...",
+      "difficulty": "easy",
+      "topic": "Concept tested..."
     }
   ]
 }
-`, req.Goal, req.Stack, req.Level, req.StepNumber, req.StepTitle, req.StepDesc, req.Level)
+`, count, req.Goal, req.Stack, req.Level, req.StepNumber, req.StepTitle, req.StepDesc, learnerInfo, count, req.Level, typesInfo)
 				} else {
 					prompt = fmt.Sprintf(`
 あなたは熟練のエンジニアメンターです。
-ユーザーの以下の学習ステップに対して、理解度を確認する4択クイズを10問作成してください。
+ユーザーの以下の学習ステップに対して、理解度を確認するクイズを%d問作成してください。
 
 # プロジェクト情報
 - 目標: %s
@@ -386,25 +458,41 @@ Create 10 multiple-choice quizzes to check understanding for the following learn
 - Step %d: %s
 - 内容: %s
 
+%s
 # ルール
-1. このステップの実装に必要な知識や、関連する概念を問う問題を10問作成してください。
-2. ユーザーのレベル（%s）に合わせて難易度を調整してください。
-3. 基礎的な問題から応用的な問題までバランスよく含めてください。
-4. 各クイズには詳しい解説を付けてください。
-5. **重要: 出力は必ず日本語で行ってください。**
+1. このステップの実装に必要な知識や、関連する概念を問う問題を%d問作成してください。上記の既存の問題と同じ問題や言い換えただけの問題は作らないでください。
+2. ユーザーのレベル（%s）を基準に、学習者の成績に合わせて上記の難易度の配分に従ってください。
+3. 学習者が間違えた概念がこのステップに関係する場合は、別の角度から問い直す問題を含めてください。
+4. 各クイズに難易度（"easy"、"medium"、"hard" のいずれか）と、問う概念を短く付けてください。
+5. 各クイズには詳しい解説を付けてください。
+6. **重要: 出力は必ず日本語で行ってください。**
 
+%s
 # 出力JSONフォーマット
 {
   "quizzes": [
     {
+      "type": "multiple_choice",
       "question": "問題文...",
       "options": ["選択肢A", "選択肢B", "選択肢C", "選択肢D"],
       "answer_index": 0,
-      "explanation": "解説..."
+      "explanation": "解説...",
+      "difficulty": "medium",
+      "topic": "問う概念..."
+    },
+    {
+      "type": "code_output",
+      "question": "このコードの出力は何ですか？",
+      "code": "fmt.Println(len(\"go\"))",
+      "options": [],
+      "accepted_answers": ["2"],
+      "explanation": "解説...",
+      "difficulty": "easy",
+      "topic": "問う概念..."
     }
   ]
 }
-`, req.Goal, req.Stack, req.Level, req.StepNumber, req.StepTitle, req.StepDesc, req.Level)
+`, count, req.Goal, req.Stack, req.Level, req.StepNumber, req.StepTitle, req.StepDesc, learnerInfo, count, req.Level, typesInfo)
 				}
 
 				resp, genErr := w.GenModel.GenerateContent(ctx, genai.Text(prompt))
@@ -427,24 +515,12 @@ Create 10 multiple-choice quizzes to check understanding for the following learn
 
 						// Parse Quizzes
 						type StepQuizResponse struct {
-							Quizzes []struct {
-								Question    string   `json:"question"`
-								Options     []string `json:"options"`
-								AnswerIndex int      `json:"answer_index"`
-								Explanation string   `json:"explanation"`
-							} `json:"quizzes"`
+							Quizzes []questions.Generated `json:"quizzes"`
 						}
 						var quizResp StepQuizResponse
 						if parseErr := json.Unmarshal([]byte(jsonStr), &quizResp); parseErr != nil {
 							err = fmt.Errorf("failed to parse quiz json: %v", parseErr)
 						} else {
-							// Find Project
-							var project models.Project
-							w.DB.Where("user_id = ? AND goal = ?", job.UserID, req.Goal).First(&project)
-							if project.ID == 0 {
-								w.DB.Where("user_id = ?", job.UserID).Order("created_at desc").First(&project)
-							}
-
 							if project.ID != 0 {
 								var step models.Step
 								w.DB.Where("project_id = ? AND step_number = ?", project.ID, req.StepNumber).First(&step)
@@ -458,21 +534,43 @@ Create 10 multiple-choice quizzes to check understanding for the following learn
 									w.DB.Create(&step)
 								}
 
-								// Save quizzes
+								// Save quizzes, skipping any that do not match their type's schema
+								// or repeat a question of the pool
+								added := 0
 								for _, q := range quizResp.Quizzes {
-									optionsBytes, _ := json.Marshal(q.Options)
-									quiz := models.Quiz{
-										StepID:      step.ID,
-										Question:    q.Question,
-										Options:     optionsBytes,
-										AnswerIndex: q.AnswerIndex,
-										Explanation: q.Explanation,
+									if added == count {
+										break
+									}
+									quiz, quizErr := q.ToQuiz(step.ID)
+									if quizErr != nil {
+										log.Printf("Worker: Skipping invalid quiz for step %d: %v", step.ID, quizErr)
+										continue
 									}
+									if pool.Duplicates(pool.Text(quiz), existingTexts) {
+										log.Printf("Worker: Skipping duplicate quiz for step %d: %s", step.ID, quiz.Question)
+										continue
+									}
+									quiz.Difficulty = adaptive.NormalizeDifficulty(quiz.Difficulty)
 									w.DB.Create(&quiz)
+									existing = append(existing, quiz)
+									existingTexts = append(existingTexts, pool.Text(quiz))
+									added++
+								}
+								if added == 0 {
+									err = fmt.Errorf("no valid quizzes generated")
 								}
 
-								// Return result
-								result, _ = json.Marshal(quizResp)
+								// Return the whole pool without answers; they are revealed through attempts
+								var quizzesResult []questions.Preview
+								for _, q := range existing {
+									if !q.Hidden {
+										quizzesResult = append(quizzesResult, questions.PreviewOf(q))
+									}
+								}
+								result, _ = json.Marshal(map[string]interface{}{
+									"quizzes": quizzesResult,
+									"added":   added,
+								})
 							} else {
 								err = fmt.Errorf("project not found")
 							}
@@ -481,6 +579,18 @@ Create 10 multiple-choice quizzes to check understanding for the following learn
 						err = fmt.Errorf("unexpected response format")
 					}
 				}
+
+			case "regenerate_step":
+				result, err = w.regenerateStep(ctx, &job)
+
+			case "generate_remedial_quiz":
+				result, err = w.generateRemedialQuiz(ctx, &job)
+
+			case "grade_free_response":
+				result, err = w.gradeFreeResponse(ctx, &job)
+
+			case "explain_mistake":
+				result, err = w.explainMistake(ctx, &job)
 			}
 
 			if err != nil {
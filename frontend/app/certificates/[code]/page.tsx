@@ -0,0 +1,121 @@
+"use client";
+
+import { useEffect, useState } from "react";
+import { useParams } from "next/navigation";
+import { useTranslations } from "@/hooks/useTranslations";
+import { Button } from "@/components/ui/button";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Award, CheckCircle2, XCircle, FileDown } from "lucide-react";
+import { API_BASE_URL } from "@/config/api";
+
+interface CertificateScore {
+    step: number;
+    title: string;
+    percentage: number;
+}
+
+interface Certificate {
+    code: string;
+    learner_name: string;
+    goal: string;
+    stack: string;
+    average_percentage: number;
+    issued_at: string;
+    revoked_at?: string;
+    scores: CertificateScore[];
+    valid: boolean;
+    revoked: boolean;
+    pdf_sha256: string;
+}
+
+// 修了証の公開検証ページ（ログイン不要）
+export default function CertificatePage() {
+    const params = useParams();
+    const code = params.code as string;
+    const { t } = useTranslations("Certificate");
+    const [certificate, setCertificate] = useState<Certificate | null>(null);
+    const [notFound, setNotFound] = useState(false);
+
+    useEffect(() => {
+        const load = async () => {
+            try {
+                const res = await fetch(`${API_BASE_URL}/api/certificates/${code}`);
+                if (!res.ok) {
+                    setNotFound(true);
+                    return;
+                }
+                setCertificate(await res.json());
+            } catch (err) {
+                console.error("Failed to load certificate:", err);
+                setNotFound(true);
+            }
+        };
+        load();
+    }, [code]);
+
+    if (notFound) {
+        return (
+            <div className="min-h-screen bg-gradient-to-br from-slate-50 to-slate-100 p-8 flex items-center justify-center">
+                <p className="text-lg text-slate-600">{t("notFound")}</p>
+            </div>
+        );
+    }
+    if (!certificate) return null;
+
+    return (
+        <div className="min-h-screen bg-gradient-to-br from-slate-50 to-slate-100 p-4 md:p-8">
+            <div className="max-w-2xl mx-auto space-y-6">
+                <Card>
+                    <CardHeader className="text-center">
+                        <Award className="h-12 w-12 mx-auto text-emerald-600" />
+                        <CardTitle className="text-2xl">{t("title")}</CardTitle>
+                        <CardDescription>{t("code", { code: certificate.code })}</CardDescription>
+                    </CardHeader>
+                    <CardContent className="space-y-6">
+                        {certificate.valid ? (
+                            <div className="flex items-center gap-2 rounded-lg bg-green-50 border border-green-200 p-3 text-green-800">
+                                <CheckCircle2 className="h-5 w-5" />
+                                {t("valid")}
+                            </div>
+                        ) : (
+                            <div className="flex items-center gap-2 rounded-lg bg-red-50 border border-red-200 p-3 text-red-800">
+                                <XCircle className="h-5 w-5" />
+                                {certificate.revoked ? t("revoked") : t("invalid")}
+                            </div>
+                        )}
+
+                        <div className="text-center space-y-1">
+                            <p className="text-2xl font-bold text-slate-900">{certificate.learner_name}</p>
+                            <p className="text-lg text-slate-700">{certificate.goal}</p>
+                            <p className="text-sm text-slate-500">{certificate.stack}</p>
+                        </div>
+
+                        <div className="divide-y rounded-lg border">
+                            {certificate.scores.map((s) => (
+                                <div key={s.step} className="flex justify-between px-4 py-2 text-sm">
+                                    <span className="text-slate-700">{s.step}. {s.title}</span>
+                                    <span className="font-semibold text-slate-900">{s.percentage}%</span>
+                                </div>
+                            ))}
+                        </div>
+
+                        <div className="flex justify-between text-sm text-slate-600">
+                            <span>{t("average", { percentage: certificate.average_percentage })}</span>
+                            <span>{t("issued", { date: new Date(certificate.issued_at).toLocaleDateString() })}</span>
+                        </div>
+
+                        {!certificate.revoked && (
+                            <Button asChild className="w-full bg-slate-900 hover:bg-slate-800 gap-2">
+                                <a href={`${API_BASE_URL}/api/certificates/${certificate.code}/pdf`} target="_blank" rel="noopener noreferrer">
+                                    <FileDown className="h-4 w-4" />
+                                    {t("downloadPdf")}
+                                </a>
+                            </Button>
+                        )}
+                        <p className="text-xs text-slate-400 break-all">SHA-256: {certificate.pdf_sha256}</p>
+                    </CardContent>
+                </Card>
+            </div>
+        </div>
+    );
+}
@@ -0,0 +1,277 @@
+"use client";
+
+import { useCallback, useEffect, useState } from "react";
+import { useAuth } from "@/context/AuthContext";
+import { useTranslations } from "@/hooks/useTranslations";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Switch } from "@/components/ui/switch";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Trophy, Users, LogOut } from "lucide-react";
+import { API_BASE_URL } from "@/config/api";
+import { cn } from "@/lib/utils";
+
+type Period = "week" | "month" | "all";
+type Metric = "xp" | "score";
+
+interface LeaderboardEntry {
+    rank?: number;
+    user_id: number;
+    username: string;
+    profile_image: string;
+    xp: number;
+    attempts: number;
+    average_percentage: number;
+}
+
+interface Leaderboard {
+    period: Period;
+    period_start: string;
+    metric: Metric;
+    cohort_id?: number;
+    entries: LeaderboardEntry[];
+    me: LeaderboardEntry | null;
+    hidden: boolean;
+}
+
+interface Cohort {
+    id: number;
+    name: string;
+    code: string;
+    members: number;
+    is_owner: boolean;
+}
+
+// 期間・指標・コホートごとのランキング
+export default function LeaderboardPage() {
+    const { token } = useAuth();
+    const { t } = useTranslations("Leaderboard");
+    const [period, setPeriod] = useState<Period>("week");
+    const [metric, setMetric] = useState<Metric>("xp");
+    const [cohortId, setCohortId] = useState<number | null>(null);
+    const [board, setBoard] = useState<Leaderboard | null>(null);
+    const [cohorts, setCohorts] = useState<Cohort[]>([]);
+    const [cohortName, setCohortName] = useState("");
+    const [joinCode, setJoinCode] = useState("");
+    const [error, setError] = useState<string | null>(null);
+
+    const fetchBoard = useCallback(async () => {
+        if (!token) return;
+        const params = new URLSearchParams({ period, metric });
+        if (cohortId) params.set("cohort", String(cohortId));
+        try {
+            const res = await fetch(`${API_BASE_URL}/api/leaderboards?${params}`, {
+                headers: { Authorization: `Bearer ${token}` },
+            });
+            if (res.ok) setBoard(await res.json());
+        } catch (err) {
+            console.error("Failed to fetch leaderboard:", err);
+        }
+    }, [token, period, metric, cohortId]);
+
+    const fetchCohorts = useCallback(async () => {
+        if (!token) return;
+        try {
+            const res = await fetch(`${API_BASE_URL}/api/cohorts`, {
+                headers: { Authorization: `Bearer ${token}` },
+            });
+            if (res.ok) setCohorts(await res.json());
+        } catch (err) {
+            console.error("Failed to fetch cohorts:", err);
+        }
+    }, [token]);
+
+    useEffect(() => {
+        fetchBoard();
+    }, [fetchBoard]);
+
+    useEffect(() => {
+        fetchCohorts();
+    }, [fetchCohorts]);
+
+    const cohortRequest = async (path: string, method: string, body?: object) => {
+        setError(null);
+        const res = await fetch(`${API_BASE_URL}/api${path}`, {
+            method,
+            headers: { "Content-Type": "application/json", Authorization: `Bearer ${token}` },
+            body: body ? JSON.stringify(body) : undefined,
+        });
+        if (!res.ok) {
+            const data = await res.json().catch(() => ({}));
+            setError(data.error || t("cohortError"));
+            return null;
+        }
+        return res.json();
+    };
+
+    const handleCreateCohort = async () => {
+        const cohort = await cohortRequest("/cohorts", "POST", { name: cohortName });
+        if (cohort) {
+            setCohortName("");
+            await fetchCohorts();
+            setCohortId(cohort.id);
+        }
+    };
+
+    const handleJoinCohort = async () => {
+        const cohort = await cohortRequest("/cohorts/join", "POST", { code: joinCode });
+        if (cohort) {
+            setJoinCode("");
+            await fetchCohorts();
+            setCohortId(cohort.id);
+        }
+    };
+
+    const handleLeaveCohort = async (id: number) => {
+        if (await cohortRequest(`/cohorts/${id}/membership`, "DELETE")) {
+            if (cohortId === id) setCohortId(null);
+            fetchCohorts();
+        }
+    };
+
+    // ランキングに表示しない設定（プロフィールに保存）
+    const handleHiddenChange = async (hidden: boolean) => {
+        if (await cohortRequest("/profile", "PUT", { hide_from_leaderboards: hidden })) {
+            fetchBoard();
+        }
+    };
+
+    const valueOf = (entry: LeaderboardEntry) =>
+        metric === "xp" ? `${entry.xp} XP` : t("averageScore", { score: entry.average_percentage, attempts: entry.attempts });
+
+    const tab = (active: boolean) =>
+        cn("px-3 py-1.5 text-sm rounded-md transition-colors", active ? "bg-slate-900 text-white" : "text-slate-600 hover:bg-slate-100");
+
+    return (
+        <div className="min-h-screen bg-gradient-to-br from-slate-50 to-slate-100 p-8">
+            <div className="max-w-4xl mx-auto space-y-6">
+                <div className="space-y-2">
+                    <h1 className="text-3xl font-bold text-slate-900 flex items-center gap-2">
+                        <Trophy className="h-7 w-7 text-yellow-500" />
+                        {t("title")}
+                    </h1>
+                    <p className="text-slate-600">{t("subtitle")}</p>
+                </div>
+
+                <Card>
+                    <CardContent className="pt-6 space-y-4">
+                        <div className="flex flex-wrap items-center gap-4 justify-between">
+                            <div className="flex gap-1">
+                                {(["week", "month", "all"] as Period[]).map((p) => (
+                                    <button key={p} className={tab(period === p)} onClick={() => setPeriod(p)}>
+                                        {t(`periods.${p}`)}
+                                    </button>
+                                ))}
+                            </div>
+                            <div className="flex gap-1">
+                                {(["xp", "score"] as Metric[]).map((m) => (
+                                    <button key={m} className={tab(metric === m)} onClick={() => setMetric(m)}>
+                                        {t(`metrics.${m}`)}
+                                    </button>
+                                ))}
+                            </div>
+                            <select
+                                className="border rounded-md px-2 py-1.5 text-sm bg-white"
+                                value={cohortId ?? ""}
+                                onChange={(e) => setCohortId(e.target.value ? Number(e.target.value) : null)}
+                            >
+                                <option value="">{t("global")}</option>
+                                {cohorts.map((c) => (
+                                    <option key={c.id} value={c.id}>
+                                        {c.name}
+                                    </option>
+                                ))}
+                            </select>
+                        </div>
+
+                        {metric === "score" && <p className="text-xs text-slate-500">{t("scoreNote")}</p>}
+
+                        {board && board.entries.length === 0 ? (
+                            <p className="text-sm text-slate-500 py-6 text-center">{t("empty")}</p>
+                        ) : (
+                            <ol className="divide-y">
+                                {board?.entries.map((entry) => (
+                                    <li
+                                        key={entry.user_id}
+                                        className={cn(
+                                            "flex items-center gap-3 py-2 px-2 rounded",
+                                            entry.user_id === board.me?.user_id && "bg-yellow-50"
+                                        )}
+                                    >
+                                        <span className="w-8 text-right font-bold text-slate-700">{entry.rank}</span>
+                                        {entry.profile_image ? (
+                                            <img src={entry.profile_image} alt="" className="h-8 w-8 rounded-full object-cover" />
+                                        ) : (
+                                            <div className="h-8 w-8 rounded-full bg-slate-200" />
+                                        )}
+                                        <span className="flex-1 text-slate-900">{entry.username || t("anonymous")}</span>
+                                        <span className="text-sm font-semibold text-slate-700">{valueOf(entry)}</span>
+                                    </li>
+                                ))}
+                            </ol>
+                        )}
+
+                        {board?.me && (
+                            <div className="border-t pt-3 text-sm text-slate-700 flex items-center justify-between">
+                                <span>
+                                    {board.hidden
+                                        ? t("youAreHidden")
+                                        : board.me.rank
+                                            ? t("yourRank", { rank: board.me.rank })
+                                            : t("notRanked")}
+                                </span>
+                                <span className="font-semibold">{valueOf(board.me)}</span>
+                            </div>
+                        )}
+
+                        {board && (
+                            <div className="flex items-center gap-2 text-sm text-slate-600">
+                                <Switch checked={board.hidden} onCheckedChange={handleHiddenChange} />
+                                <span>{t("hideMe")}</span>
+                            </div>
+                        )}
+                    </CardContent>
+                </Card>
+
+                <Card>
+                    <CardHeader>
+                        <CardTitle className="text-lg flex items-center gap-2">
+                            <Users className="h-5 w-5" />
+                            {t("cohorts")}
+                        </CardTitle>
+                        <CardDescription>{t("cohortsDesc")}</CardDescription>
+                    </CardHeader>
+                    <CardContent className="space-y-4">
+                        {cohorts.map((c) => (
+                            <div key={c.id} className="flex items-center justify-between rounded-lg border p-3">
+                                <div>
+                                    <p className="font-medium text-slate-900">{c.name}</p>
+                                    <p className="text-xs text-slate-500">
+                                        {t("members", { count: c.members })} · {t("joinCode")}: <span className="font-mono">{c.code}</span>
+                                    </p>
+                                </div>
+                                <Button variant="ghost" size="sm" onClick={() => handleLeaveCohort(c.id)} className="gap-1 text-slate-500">
+                                    <LogOut className="h-4 w-4" />
+                                    {t("leave")}
+                                </Button>
+                            </div>
+                        ))}
+                        <div className="flex gap-2">
+                            <Input value={cohortName} onChange={(e) => setCohortName(e.target.value)} placeholder={t("cohortName")} />
+                            <Button onClick={handleCreateCohort} disabled={!cohortName.trim()}>
+                                {t("create")}
+                            </Button>
+                        </div>
+                        <div className="flex gap-2">
+                            <Input value={joinCode} onChange={(e) => setJoinCode(e.target.value)} placeholder={t("joinCode")} />
+                            <Button variant="outline" onClick={handleJoinCohort} disabled={!joinCode.trim()}>
+                                {t("join")}
+                            </Button>
+                        </div>
+                        {error && <p className="text-sm text-red-600">{error}</p>}
+                    </CardContent>
+                </Card>
+            </div>
+        </div>
+    );
+}
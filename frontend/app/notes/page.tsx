@@ -0,0 +1,91 @@
+"use client";
+
+import { useState } from "react";
+import Link from "next/link";
+import { useAuth } from "@/context/AuthContext";
+import { useTranslations } from "@/hooks/useTranslations";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Card, CardContent } from "@/components/ui/card";
+import { HighlightedSnippet } from "@/components/HighlightedSnippet";
+import { NotebookPen, Search } from "lucide-react";
+import { API_BASE_URL } from "@/config/api";
+
+interface NoteResult {
+    id: number;
+    project_id: number;
+    project_goal: string;
+    step_number: number;
+    step_title: string;
+    snippet: string;
+    updated_at: string;
+}
+
+// すべてのプロジェクトのメモを全文検索する
+export default function NotesPage() {
+    const { token } = useAuth();
+    const { t } = useTranslations("Notes");
+    const [query, setQuery] = useState("");
+    const [results, setResults] = useState<NoteResult[] | null>(null);
+    const [loading, setLoading] = useState(false);
+
+    const handleSearch = async (e: React.FormEvent) => {
+        e.preventDefault();
+        if (!token || !query.trim()) return;
+        setLoading(true);
+        try {
+            const res = await fetch(`${API_BASE_URL}/api/notes/search?q=${encodeURIComponent(query)}`, {
+                headers: { Authorization: `Bearer ${token}` },
+            });
+            if (res.ok) setResults(await res.json());
+        } catch (err) {
+            console.error("Failed to search notes:", err);
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    return (
+        <div className="min-h-screen bg-gradient-to-br from-slate-50 to-slate-100 p-8">
+            <div className="max-w-4xl mx-auto space-y-6">
+                <div className="space-y-2">
+                    <h1 className="text-3xl font-bold text-slate-900 flex items-center gap-2">
+                        <NotebookPen className="h-7 w-7 text-emerald-600" />
+                        {t("title")}
+                    </h1>
+                    <p className="text-slate-600">{t("subtitle")}</p>
+                </div>
+
+                <form onSubmit={handleSearch} className="flex gap-2">
+                    <Input value={query} onChange={(e) => setQuery(e.target.value)} placeholder={t("placeholder")} />
+                    <Button type="submit" disabled={loading || !query.trim()} className="gap-1">
+                        <Search className="h-4 w-4" />
+                        {t("search")}
+                    </Button>
+                </form>
+
+                {results === null ? (
+                    <p className="text-sm text-slate-500">{t("hint")}</p>
+                ) : results.length === 0 ? (
+                    <p className="text-sm text-slate-500 py-6 text-center">{t("noResults")}</p>
+                ) : (
+                    <div className="space-y-3">
+                        {results.map((note) => (
+                            <Link key={note.id} href={`/quiz/${note.step_number}?projectId=${note.project_id}`}>
+                                <Card className="hover:border-slate-400 transition-colors">
+                                    <CardContent className="pt-4 space-y-1">
+                                        <p className="text-xs text-slate-500">
+                                            {note.project_goal} · {t("step", { step: note.step_number, title: note.step_title })}
+                                        </p>
+                                        <HighlightedSnippet text={note.snippet} />
+                                        <p className="text-xs text-slate-400">{new Date(note.updated_at).toLocaleString()}</p>
+                                    </CardContent>
+                                </Card>
+                            </Link>
+                        ))}
+                    </div>
+                )}
+            </div>
+        </div>
+    );
+}
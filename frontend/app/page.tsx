@@ -5,6 +5,8 @@ import { useRouter, useSearchParams } from "next/navigation";
 import { useTranslations } from "@/hooks/useTranslations";
 import { useAuth } from "@/context/AuthContext";
 import { useProjects } from "@/context/ProjectContext";
+import { ProgressOverview } from "@/components/ProgressOverview";
+import { BadgeList, BadgeStatus } from "@/components/BadgeList";
 import { RoadmapResponse, Step } from "@/src/roadmap";
 import { Button } from "@/components/ui/button";
 import { Input } from "@/components/ui/input";
@@ -41,13 +43,23 @@ export default function Home() {
   const [roadmap, setRoadmap] = useState<RoadmapResponse | null>(null);
   const [editingPlan, setEditingPlan] = useState<ProposeResponse | null>(null);
   const [error, setError] = useState<string | null>(null);
+  const [badges, setBadges] = useState<{ earned: BadgeStatus[]; available: BadgeStatus[] } | null>(null);
 
   const router = useRouter();
   const searchParams = useSearchParams();
   const { token, logout } = useAuth();
-  const { fetchProjects } = useProjects();
+  const { fetchProjects, progress, xp, streak } = useProjects();
   const { t, locale } = useTranslations();
 
+  // 獲得したバッジと獲得できるバッジ
+  useEffect(() => {
+    if (!token) return;
+    fetch(`${API_BASE_URL}/api/me/badges`, { headers: { Authorization: `Bearer ${token}` } })
+      .then((res) => (res.ok ? res.json() : null))
+      .then((data) => data && setBadges(data))
+      .catch((err) => console.error("Failed to fetch badges:", err));
+  }, [token]);
+
   useEffect(() => {
     // Check for pending project from localStorage after login
     const pendingProjectStr = localStorage.getItem("pendingProject");
@@ -327,6 +339,51 @@ export default function Home() {
           <p className="text-slate-600">{t('Home.subtitle')}</p>
         </motion.div>
 
+        {/* Progress across all projects */}
+        {progress && progress.attempts_completed > 0 && (
+          <ProgressOverview
+            progress={progress}
+            xp={xp}
+            streak={streak}
+            labels={{
+              title: t('Home.progress.title'),
+              stepsCompleted: t('Home.progress.stepsCompleted'),
+              averageScore: t('Home.progress.averageScore'),
+              trend: t('Home.progress.trend'),
+              lastActivity: t('Home.progress.lastActivity'),
+              daysAgo: t('Home.progress.daysAgo'),
+              today: t('Home.progress.today'),
+              weakTopics: t('Home.progress.weakTopics'),
+              correctRate: t('Home.progress.correctRate'),
+              level: t('Home.progress.level'),
+              xpToNext: t('Home.progress.xpToNext'),
+              todayXP: t('Home.progress.todayXP'),
+              streakDays: t('Home.progress.streakDays'),
+              longestStreak: t('Home.progress.longestStreak'),
+              freezes: t('Home.progress.freezes'),
+              streakAtRisk: t('Home.progress.streakAtRisk'),
+              studyTime: t('Home.progress.studyTime'),
+              durationHoursMinutes: t('common.durationHoursMinutes'),
+              durationMinutes: t('common.durationMinutes'),
+            }}
+          />
+        )}
+
+        {/* Badges */}
+        {badges && progress && progress.attempts_completed > 0 && (
+          <BadgeList
+            earned={badges.earned}
+            available={badges.available}
+            locale={locale}
+            labels={{
+              title: t('Home.badges.title'),
+              earned: t('Home.badges.earned'),
+              available: t('Home.badges.available'),
+              none: t('Home.badges.none'),
+            }}
+          />
+        )}
+
         {/* Input Form */}
         <motion.div
           variants={cardVariants}
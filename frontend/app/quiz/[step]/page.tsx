@@ -4,12 +4,18 @@ import { useState, useEffect } from "react";
 import { useParams, useRouter, useSearchParams } from "next/navigation";
 import { useAuth } from "@/context/AuthContext";
 import { useTranslations } from "@/hooks/useTranslations";
-import { Quiz } from "@/src/roadmap";
+import { useStudySession } from "@/hooks/useStudySession";
+import { Quiz, QuizResponse } from "@/src/roadmap";
+import { QuizAnswerInput, initialOrder, isResponseReady } from "@/components/QuizAnswerInput";
+import { StepTutorChat } from "@/components/StepTutorChat";
+import { StepNotes } from "@/components/StepNotes";
+import { ReportQuizButton, ReportCategory } from "@/components/ReportQuizButton";
+import { BadgeStatus, badgeText } from "@/components/BadgeList";
 import { Button } from "@/components/ui/button";
 import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
 import { RadioGroup, RadioGroupItem } from "@/components/ui/radio-group";
 import { Label } from "@/components/ui/label";
-import { CheckCircle2, XCircle, ArrowRight, Home, ArrowLeft, Lock as LockIcon } from "lucide-react";
+import { CheckCircle2, XCircle, ArrowRight, Home, ArrowLeft, RefreshCw, Award, Lock as LockIcon } from "lucide-react";
 import { pollJob } from "@/lib/api";
 
 import { API_BASE_URL } from "@/config/api";
@@ -23,7 +29,7 @@ export default function QuizPage() {
 
     const [quizzes, setQuizzes] = useState<Quiz[]>([]);
     const [currentQuizIndex, setCurrentQuizIndex] = useState(0);
-    const [selectedAnswer, setSelectedAnswer] = useState<number | null>(null);
+    const [selectedAnswer, setSelectedAnswer] = useState<QuizResponse | null>(null);
     const [showResult, setShowResult] = useState(false);
     const [score, setScore] = useState(0);
     const [answeredQuizzes, setAnsweredQuizzes] = useState<boolean[]>([]);
@@ -40,6 +46,16 @@ export default function QuizPage() {
 
     const { token, user, logout } = useAuth();
     const [projectId, setProjectId] = useState<number | null>(null);
+    const [attemptId, setAttemptId] = useState<number | null>(null);
+    const [choices, setChoices] = useState<(QuizResponse | null)[]>([]);
+    const [explaining, setExplaining] = useState(false);
+    const [projectInfo, setProjectInfo] = useState<any>(null);
+    const [certificateCode, setCertificateCode] = useState<string | null>(null);
+    const [xpAwarded, setXPAwarded] = useState(0);
+    const [earnedBadges, setEarnedBadges] = useState<BadgeStatus[]>([]);
+
+    // このステップの学習時間を計測する
+    useStudySession(projectId, stepNumber, token);
 
     useEffect(() => {
         const fetchProjectAndStep = async () => {
@@ -61,6 +77,7 @@ export default function QuizPage() {
                 if (!projectRes.ok) throw new Error("Project not found");
                 const projectData = await projectRes.json();
                 setProjectId(projectData.id);
+                setProjectInfo(projectData);
 
                 const roadmapData = projectData.roadmap || [];
                 setTotalSteps(roadmapData.length);
@@ -86,8 +103,7 @@ export default function QuizPage() {
                     setStepDescription(stepData.description);
 
                     if (stepData.quizzes && stepData.quizzes.length > 0) {
-                        setQuizzes(stepData.quizzes);
-                        setAnsweredQuizzes(new Array(stepData.quizzes.length).fill(false));
+                        await startAttempt(projectData.id);
                     } else {
                         // If no quizzes, generate them (fallback or new logic)
                         generateQuizzes(projectData, stepData);
@@ -101,6 +117,23 @@ export default function QuizPage() {
             }
         };
 
+        // 採点はサーバー側で行うため、問題は回答なしで受け取る
+        const startAttempt = async (id: number) => {
+            const response = await fetch(`${API_BASE_URL}/api/projects/${id}/steps/${stepNumber}/attempts`, {
+                method: "POST",
+                headers: { Authorization: `Bearer ${token}` },
+            });
+            if (!response.ok) {
+                throw new Error("Failed to start quiz");
+            }
+
+            const attempt = await response.json();
+            setAttemptId(attempt.attempt_id);
+            setQuizzes(attempt.questions);
+            setAnsweredQuizzes(new Array(attempt.questions.length).fill(false));
+            setChoices(new Array(attempt.questions.length).fill(null));
+        };
+
         const generateQuizzes = async (project: any, stepData: any) => {
             setLoading(true);
             setError(null);
@@ -128,16 +161,11 @@ export default function QuizPage() {
 
                 const data = await response.json();
 
-                let quizzesData;
                 if (response.status === 202) {
-                    const result = await pollJob(data.job_id, API_BASE_URL, token || "", logout);
-                    quizzesData = result.quizzes;
-                } else {
-                    quizzesData = data.quizzes;
+                    await pollJob(data.job_id, API_BASE_URL, token || "", logout);
                 }
 
-                setQuizzes(quizzesData);
-                setAnsweredQuizzes(new Array(quizzesData.length).fill(false));
+                await startAttempt(project.id);
             } catch (err) {
                 console.error("Error generating quiz:", err);
                 setError(t('errorGeneration'));
@@ -153,56 +181,140 @@ export default function QuizPage() {
 
     const currentQuiz = quizzes[currentQuizIndex];
 
+    const isSingleChoice = !currentQuiz?.type || currentQuiz.type === "multiple_choice" || currentQuiz.type === "true_false";
+
     const handleAnswerSelect = (answerIndex: number) => {
         if (!showResult) {
-            setSelectedAnswer(answerIndex);
+            setSelectedAnswer({ choice: answerIndex });
         }
     };
 
-    const handleSubmit = () => {
-        if (selectedAnswer === null) return;
+    const handleSubmit = async () => {
+        if (!attemptId || !isResponseReady(currentQuiz, selectedAnswer)) return;
+
+        // 並べ替え問題は未操作なら表示順のまま回答する
+        const response: QuizResponse =
+            currentQuiz.type === "ordering" && !selectedAnswer
+                ? { choices: initialOrder(currentQuiz) }
+                : selectedAnswer ?? {};
+
+        try {
+            const response = await fetch(`${API_BASE_URL}/api/attempts/${attemptId}/answers`, {
+                method: "POST",
+                headers: {
+                    "Content-Type": "application/json",
+                    Authorization: `Bearer ${token}`,
+                },
+                body: JSON.stringify({
+                    quiz_id: currentQuiz.quiz_id,
+                    ...response,
+                }),
+            });
+            if (!response.ok) throw new Error("Failed to submit answer");
+            const result = await response.json();
+
+            // 正解と解説は回答後にのみ返される
+            const newQuizzes = [...quizzes];
+            newQuizzes[currentQuizIndex] = {
+                ...currentQuiz,
+                answer_index: result.answer_index,
+                answer_indexes: result.answer_indexes,
+                answer_text: result.answer_text,
+                is_correct: result.is_correct,
+                explanation: result.explanation,
+                grading_status: result.grading_status,
+                max_points: result.max_points,
+            };
+            setQuizzes(newQuizzes);
+
+            const newChoices = [...choices];
+            newChoices[currentQuizIndex] = response;
+            setChoices(newChoices);
+            setSelectedAnswer(response);
+
+            setShowResult(true);
+            const newAnsweredQuizzes = [...answeredQuizzes];
+            newAnsweredQuizzes[currentQuizIndex] = true;
+            setAnsweredQuizzes(newAnsweredQuizzes);
+
+            if (result.is_correct) {
+                setScore(score + 1);
+            }
+        } catch (error) {
+            console.error("Failed to submit answer:", error);
+        }
+    };
 
-        setShowResult(true);
-        const newAnsweredQuizzes = [...answeredQuizzes];
-        newAnsweredQuizzes[currentQuizIndex] = true;
-        setAnsweredQuizzes(newAnsweredQuizzes);
+    // 不正解の理由を取得（キャッシュがなければジョブの完了を待つ）
+    const handleExplainMistake = async () => {
+        if (!attemptId || !currentQuiz?.quiz_id) return;
+        const index = currentQuizIndex;
+        setExplaining(true);
+        try {
+            const response = await fetch(`${API_BASE_URL}/api/attempts/${attemptId}/answers/${currentQuiz.quiz_id}/explain`, {
+                method: "POST",
+                headers: { Authorization: `Bearer ${token}` },
+            });
+            if (!response.ok) throw new Error("Failed to explain mistake");
+            let result = await response.json();
+            if (response.status === 202) {
+                result = await pollJob(result.job_id, API_BASE_URL, token || "", logout);
+            }
 
-        if (selectedAnswer === currentQuiz.answer_index) {
-            setScore(score + 1);
+            setQuizzes((prev) => {
+                const next = [...prev];
+                next[index] = {
+                    ...next[index],
+                    mistake_explanation: result.explanation,
+                    mistake_concept: result.concept,
+                };
+                return next;
+            });
+        } catch (error) {
+            console.error("Failed to explain mistake:", error);
+        } finally {
+            setExplaining(false);
         }
     };
 
     const handleNext = async () => {
         if (currentQuizIndex < quizzes.length - 1) {
             setCurrentQuizIndex(currentQuizIndex + 1);
-            setSelectedAnswer(null);
-            setShowResult(false);
+            setSelectedAnswer(choices[currentQuizIndex + 1] ?? null);
+            setShowResult(answeredQuizzes[currentQuizIndex + 1] ?? false);
         } else {
             // 最後の問題の場合、結果画面を表示
             setShowFinalResult(true);
 
-            // スコアをDBに保存
-            if (token && projectId) {
+            // 回答を提出し、サーバーで採点されたスコアを保存
+            if (token && attemptId) {
                 try {
-                    await fetch(`${API_BASE_URL}/api/projects/${projectId}/steps/${stepNumber}/score`, {
+                    const response = await fetch(`${API_BASE_URL}/api/attempts/${attemptId}/submit`, {
                         method: "POST",
                         headers: {
                             "Content-Type": "application/json",
                             Authorization: `Bearer ${token}`,
                         },
-                        body: JSON.stringify({
-                            score: score,
-                            total: quizzes.length,
-                            percentage: Math.round((score / quizzes.length) * 100)
-                        }),
+                        body: JSON.stringify({}),
                     });
+                    if (!response.ok) throw new Error("Failed to submit attempt");
+                    const result = await response.json();
+                    setScore(result.score);
+                    // 全ステップに合格すると修了証が発行される
+                    if (result.certificate_code) {
+                        setCertificateCode(result.certificate_code);
+                    }
+                    // 獲得した経験値と新しく獲得したバッジ
+                    setXPAwarded(result.xp_awarded || 0);
+                    setEarnedBadges(result.badges_earned || []);
+
                     // Update local state for sidebar
                     setStepScores((prev: any) => ({
                         ...prev,
                         [stepNumber]: {
-                            score: score,
-                            total: quizzes.length,
-                            percentage: Math.round((score / quizzes.length) * 100)
+                            score: result.score,
+                            total: result.total,
+                            percentage: result.percentage
                         }
                     }));
                 } catch (error) {
@@ -215,8 +327,8 @@ export default function QuizPage() {
     const handlePrevious = () => {
         if (currentQuizIndex > 0) {
             setCurrentQuizIndex(currentQuizIndex - 1);
-            setSelectedAnswer(null);
-            setShowResult(false);
+            setSelectedAnswer(choices[currentQuizIndex - 1] ?? null);
+            setShowResult(answeredQuizzes[currentQuizIndex - 1] ?? false);
         }
     };
 
@@ -224,6 +336,60 @@ export default function QuizPage() {
         router.push("/");
     };
 
+    // 問題プールに新しい問題を追加してから再挑戦する（プールが満杯なら既存の問題から出題）
+    const handleRetakeWithNewQuestions = async () => {
+        if (!projectInfo) return;
+        setLoading(true);
+        setError(null);
+        try {
+            const genRes = await fetch(`${API_BASE_URL}/api/generate-step-quiz`, {
+                method: "POST",
+                headers: {
+                    "Content-Type": "application/json",
+                    Authorization: `Bearer ${token}`,
+                },
+                body: JSON.stringify({
+                    goal: projectInfo.goal,
+                    stack: projectInfo.stack,
+                    level: projectInfo.level,
+                    step_number: stepNumber,
+                    step_title: stepTitle,
+                    step_desc: stepDescription,
+                    locale,
+                    force: true,
+                }),
+            });
+            if (genRes.status === 202) {
+                const data = await genRes.json();
+                await pollJob(data.job_id, API_BASE_URL, token || "", logout);
+            } else if (!genRes.ok && genRes.status !== 409) {
+                throw new Error("Failed to generate quiz");
+            }
+
+            const response = await fetch(`${API_BASE_URL}/api/projects/${projectInfo.id}/steps/${stepNumber}/attempts`, {
+                method: "POST",
+                headers: { Authorization: `Bearer ${token}` },
+            });
+            if (!response.ok) throw new Error("Failed to start quiz");
+            const attempt = await response.json();
+
+            setAttemptId(attempt.attempt_id);
+            setQuizzes(attempt.questions);
+            setAnsweredQuizzes(new Array(attempt.questions.length).fill(false));
+            setChoices(new Array(attempt.questions.length).fill(null));
+            setCurrentQuizIndex(0);
+            setSelectedAnswer(null);
+            setShowResult(false);
+            setScore(0);
+            setShowFinalResult(false);
+        } catch (err) {
+            console.error("Error generating quiz:", err);
+            setError(t('errorGeneration'));
+        } finally {
+            setLoading(false);
+        }
+    };
+
     const handleNextStep = () => {
         if (stepNumber < totalSteps) {
             const query = projectId ? `?projectId=${projectId}` : "";
@@ -233,7 +399,8 @@ export default function QuizPage() {
         }
     };
 
-    const isCorrect = selectedAnswer === currentQuiz?.answer_index;
+    const isCorrect = currentQuiz?.is_correct ?? false;
+    const selectedIndex = selectedAnswer?.choice ?? null;
     const allQuizzesCompleted = answeredQuizzes.every((answered) => answered);
 
     if (loading) {
@@ -388,43 +555,94 @@ export default function QuizPage() {
                                 {/* Quiz Card */}
                                 <Card>
                                     <CardHeader>
-                                        <CardTitle className="text-xl">{t("question")} {currentQuizIndex + 1}</CardTitle>
+                                        <div className="flex items-center justify-between">
+                                            <CardTitle className="text-xl">{t("question")} {currentQuizIndex + 1}</CardTitle>
+                                            {currentQuiz.quiz_id && token && (
+                                                <ReportQuizButton
+                                                    key={currentQuiz.quiz_id}
+                                                    quizId={currentQuiz.quiz_id}
+                                                    token={token}
+                                                    labels={{
+                                                        button: t("report.button"),
+                                                        title: t("report.title"),
+                                                        description: t("report.description"),
+                                                        comment: t("report.comment"),
+                                                        submit: t("report.submit"),
+                                                        cancel: t("report.cancel"),
+                                                        done: t("report.done"),
+                                                        alreadyReported: t("report.alreadyReported"),
+                                                        error: t("report.error"),
+                                                        categories: t<Record<ReportCategory, string>>("report.categories", { returnObjects: true }),
+                                                    }}
+                                                />
+                                            )}
+                                        </div>
                                         <CardDescription className="text-base mt-2">{currentQuiz.question}</CardDescription>
+                                        {currentQuiz.code && (
+                                            <pre className="mt-3 p-4 rounded-lg bg-slate-900 text-slate-100 text-sm overflow-x-auto">
+                                                <code>{currentQuiz.code}</code>
+                                            </pre>
+                                        )}
                                     </CardHeader>
                                     <CardContent className="space-y-6">
-                                        <RadioGroup value={selectedAnswer !== null ? selectedAnswer.toString() : ""} onValueChange={(value) => handleAnswerSelect(parseInt(value))}>
-                                            {currentQuiz.options.map((option, index) => (
-                                                <div
-                                                    key={index}
-                                                    className={`flex items-start space-x-3 p-4 rounded-lg border-2 transition-all ${showResult
-                                                        ? index === currentQuiz.answer_index
-                                                            ? "border-green-500 bg-green-50"
-                                                            : index === selectedAnswer
-                                                                ? "border-red-500 bg-red-50"
-                                                                : "border-slate-200"
-                                                        : selectedAnswer === index
-                                                            ? "border-slate-900 bg-slate-50"
-                                                            : "border-slate-200 hover:border-slate-300"
-                                                        }`}
-                                                >
-                                                    <RadioGroupItem value={index.toString()} id={`option-${index}`} disabled={showResult} />
-                                                    <Label
-                                                        htmlFor={`option-${index}`}
-                                                        className="flex-1 cursor-pointer font-normal leading-relaxed"
+                                        {isSingleChoice ? (
+                                            <RadioGroup value={selectedIndex !== null ? selectedIndex.toString() : ""} onValueChange={(value) => handleAnswerSelect(parseInt(value))}>
+                                                {currentQuiz.options.map((option, index) => (
+                                                    <div
+                                                        key={index}
+                                                        className={`flex items-start space-x-3 p-4 rounded-lg border-2 transition-all ${showResult
+                                                            ? index === currentQuiz.answer_index
+                                                                ? "border-green-500 bg-green-50"
+                                                                : index === selectedIndex
+                                                                    ? "border-red-500 bg-red-50"
+                                                                    : "border-slate-200"
+                                                            : selectedIndex === index
+                                                                ? "border-slate-900 bg-slate-50"
+                                                                : "border-slate-200 hover:border-slate-300"
+                                                            }`}
                                                     >
-                                                        {option}
-                                                    </Label>
-                                                    {showResult && index === currentQuiz.answer_index && (
-                                                        <CheckCircle2 className="h-5 w-5 text-green-600 flex-shrink-0" />
-                                                    )}
-                                                    {showResult && index === selectedAnswer && index !== currentQuiz.answer_index && (
-                                                        <XCircle className="h-5 w-5 text-red-600 flex-shrink-0" />
-                                                    )}
-                                                </div>
-                                            ))}
-                                        </RadioGroup>
+                                                        <RadioGroupItem value={index.toString()} id={`option-${index}`} disabled={showResult} />
+                                                        <Label
+                                                            htmlFor={`option-${index}`}
+                                                            className="flex-1 cursor-pointer font-normal leading-relaxed"
+                                                        >
+                                                            {option}
+                                                        </Label>
+                                                        {showResult && index === currentQuiz.answer_index && (
+                                                            <CheckCircle2 className="h-5 w-5 text-green-600 flex-shrink-0" />
+                                                        )}
+                                                        {showResult && index === selectedIndex && index !== currentQuiz.answer_index && (
+                                                            <XCircle className="h-5 w-5 text-red-600 flex-shrink-0" />
+                                                        )}
+                                                    </div>
+                                                ))}
+                                            </RadioGroup>
+                                        ) : (
+                                            <QuizAnswerInput
+                                                quiz={currentQuiz}
+                                                value={selectedAnswer}
+                                                onChange={setSelectedAnswer}
+                                                showResult={showResult}
+                                                labels={{
+                                                    selectAll: t("selectAll"),
+                                                    orderHint: t("orderHint"),
+                                                    textPlaceholder: t("textPlaceholder"),
+                                                    correctAnswer: t("correctAnswer"),
+                                                    modelAnswer: t("modelAnswer"),
+                                                }}
+                                            />
+                                        )}
 
-                                        {showResult && (
+                                        {showResult && (currentQuiz.grading_status === "pending" || currentQuiz.grading_status === "failed") && (
+                                            <div className="p-4 rounded-lg bg-slate-50 border border-slate-200">
+                                                <p className="font-semibold mb-2 text-slate-900">
+                                                    {currentQuiz.grading_status === "pending" ? t("gradingPending") : t("gradingFailed")}
+                                                </p>
+                                                <p className="text-sm text-slate-700">{currentQuiz.explanation}</p>
+                                            </div>
+                                        )}
+
+                                        {showResult && currentQuiz.grading_status !== "pending" && currentQuiz.grading_status !== "failed" && (
                                             <div className={`p-4 rounded-lg ${isCorrect ? "bg-green-50 border border-green-200" : "bg-red-50 border border-red-200"}`}>
                                                 <p className={`font-semibold mb-2 ${isCorrect ? "text-green-900" : "text-red-900"}`}>
                                                     {isCorrect ? t("correct") : t("incorrect")}
@@ -432,6 +650,29 @@ export default function QuizPage() {
                                                 <p className={`text-sm ${isCorrect ? "text-green-800" : "text-red-800"}`}>
                                                     {currentQuiz.explanation}
                                                 </p>
+                                                {!isCorrect && (
+                                                    currentQuiz.mistake_explanation ? (
+                                                        <div className="mt-3 pt-3 border-t border-red-200 space-y-1">
+                                                            <p className="text-sm font-semibold text-red-900">{t("whyWrong")}</p>
+                                                            <p className="text-sm text-red-800">{currentQuiz.mistake_explanation}</p>
+                                                            {currentQuiz.mistake_concept && (
+                                                                <p className="text-sm text-red-800">
+                                                                    {t("conceptToRevisit")}: <span className="font-semibold">{currentQuiz.mistake_concept}</span>
+                                                                </p>
+                                                            )}
+                                                        </div>
+                                                    ) : (
+                                                        <Button
+                                                            variant="outline"
+                                                            size="sm"
+                                                            onClick={handleExplainMistake}
+                                                            disabled={explaining}
+                                                            className="mt-3"
+                                                        >
+                                                            {explaining ? t("explaining") : t("explainMistake")}
+                                                        </Button>
+                                                    )
+                                                )}
                                             </div>
                                         )}
 
@@ -449,7 +690,7 @@ export default function QuizPage() {
                                             {!showResult ? (
                                                 <Button
                                                     onClick={handleSubmit}
-                                                    disabled={selectedAnswer === null}
+                                                    disabled={!isResponseReady(currentQuiz, selectedAnswer)}
                                                     className="bg-slate-900 hover:bg-slate-800"
                                                 >
                                                     {t("answer")}
@@ -490,9 +731,48 @@ export default function QuizPage() {
                                             <p className="text-2xl text-slate-300">
                                                 {t("accuracy")}: {Math.round((score / quizzes.length) * 100)}%
                                             </p>
+                                            {xpAwarded > 0 && (
+                                                <p className="mt-2 text-lg font-semibold text-yellow-300">{t("xpAwarded", { xp: xpAwarded })}</p>
+                                            )}
                                         </div>
 
+                                        {earnedBadges.length > 0 && (
+                                            <div className="space-y-2">
+                                                <p className="text-slate-300">{t("badgesEarned")}</p>
+                                                <div className="flex flex-wrap justify-center gap-2">
+                                                    {earnedBadges.map((badge) => (
+                                                        <span
+                                                            key={badge.key}
+                                                            className="flex items-center gap-1 rounded-full bg-amber-500/20 border border-amber-400/40 px-3 py-1 text-sm text-amber-200"
+                                                        >
+                                                            <Award className="h-4 w-4" />
+                                                            {badgeText(badge, locale).name}
+                                                        </span>
+                                                    ))}
+                                                </div>
+                                            </div>
+                                        )}
+
                                         <div className="pt-4 space-y-3">
+                                            {certificateCode && (
+                                                <Button
+                                                    onClick={() => router.push(`/certificates/${certificateCode}`)}
+                                                    size="lg"
+                                                    className="w-full bg-amber-500 hover:bg-amber-600 text-white gap-2"
+                                                >
+                                                    <Award className="h-5 w-5" />
+                                                    {t("viewCertificate")}
+                                                </Button>
+                                            )}
+                                            <Button
+                                                onClick={handleRetakeWithNewQuestions}
+                                                variant="outline"
+                                                size="lg"
+                                                className="w-full bg-white/10 hover:bg-white/20 text-white border-white/20 gap-2"
+                                            >
+                                                <RefreshCw className="h-5 w-5" />
+                                                {t("retakeWithNewQuestions")}
+                                            </Button>
                                             {stepNumber < totalSteps ? (
                                                 <>
                                                     <Button
@@ -527,6 +807,46 @@ export default function QuizPage() {
                                 </CardContent>
                             </Card>
                         )}
+
+                        {/* Tutor Chat */}
+                        {projectId && token && (
+                            <StepTutorChat
+                                projectId={projectId}
+                                stepNumber={stepNumber}
+                                token={token}
+                                labels={{
+                                    title: t("tutor.title"),
+                                    description: t("tutor.description"),
+                                    placeholder: t("tutor.placeholder"),
+                                    send: t("tutor.send"),
+                                    newConversation: t("tutor.newConversation"),
+                                    quota: t("tutor.quota"),
+                                    quotaExceeded: t("tutor.quotaExceeded"),
+                                    error: t("tutor.error"),
+                                }}
+                            />
+                        )}
+
+                        {/* Notes */}
+                        {projectId && token && (
+                            <StepNotes
+                                projectId={projectId}
+                                stepNumber={stepNumber}
+                                token={token}
+                                labels={{
+                                    title: t("notes.title"),
+                                    description: t("notes.description"),
+                                    placeholder: t("notes.placeholder"),
+                                    add: t("notes.add"),
+                                    save: t("notes.save"),
+                                    cancel: t("notes.cancel"),
+                                    edit: t("notes.edit"),
+                                    delete: t("notes.delete"),
+                                    empty: t("notes.empty"),
+                                    error: t("notes.error"),
+                                }}
+                            />
+                        )}
                     </div>
                 </div>
             </div>
@@ -7,13 +7,18 @@ import { useAuth } from "@/context/AuthContext";
 import { useTranslations } from "@/hooks/useTranslations";
 import { Button } from "@/components/ui/button";
 import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
-import { CheckCircle2, Circle, ArrowLeft } from "lucide-react";
+import { CheckCircle2, Circle, ArrowLeft, Clock, Download } from "lucide-react";
+import { formatDuration } from "@/lib/utils";
+import { ShareRoadmap, ShareSettings } from "@/components/ShareRoadmap";
 
 import { API_BASE_URL } from "@/config/api";
 
 export default function RoadmapPage() {
     const [roadmap, setRoadmap] = useState<any[]>([]);
     const [projectTitle, setProjectTitle] = useState<string>("");
+    const [studySeconds, setStudySeconds] = useState(0);
+    const [exportId, setExportId] = useState<number | null>(null);
+    const [share, setShare] = useState<ShareSettings | null>(null);
     const [stepScores, setStepScores] = useState<Record<number, any>>({});
     const [loading, setLoading] = useState(true);
     const router = useRouter();
@@ -57,6 +62,9 @@ export default function RoadmapPage() {
                     const roadmapData = data.roadmap || [];
                     setRoadmap(roadmapData);
                     setProjectTitle(data.goal || "");
+                    setStudySeconds(data.study_seconds || 0);
+                    setExportId(data.id ?? null);
+                    setShare(data.share ?? null);
 
                     // Map scores
                     const scores: any = {};
@@ -86,6 +94,28 @@ export default function RoadmapPage() {
         }
     }, [token, projectId]);
 
+    // メモを含むプロジェクトをファイルとしてダウンロードする
+    const handleExport = async (format: "markdown" | "json") => {
+        if (!token || !exportId) return;
+        try {
+            const res = await fetch(`${API_BASE_URL}/api/projects/${exportId}/export?format=${format}`, {
+                headers: { Authorization: `Bearer ${token}` },
+            });
+            if (!res.ok) return;
+            const url = URL.createObjectURL(await res.blob());
+            const a = document.createElement("a");
+            a.href = url;
+            a.download = `project-${exportId}.${format === "markdown" ? "md" : "json"}`;
+            a.click();
+            URL.revokeObjectURL(url);
+        } catch (error) {
+            console.error("Failed to export project:", error);
+        }
+    };
+
+    const duration = (seconds: number) =>
+        formatDuration(seconds, { hoursMinutes: t('common.durationHoursMinutes'), minutes: t('common.durationMinutes') });
+
     if (loading) {
         return (
             <div className="flex items-center justify-center min-h-screen">
@@ -106,20 +136,65 @@ export default function RoadmapPage() {
         <div className="min-h-screen bg-gradient-to-br from-slate-50 to-slate-100 p-4 md:p-8">
             <Card className="max-w-4xl mx-auto">
                 <CardHeader className="flex flex-row items-center justify-between">
-                    <CardTitle className="text-2xl">{projectTitle || t('roadmap.title')}</CardTitle>
-                    <Button
-                        variant="outline"
-                        onClick={() => router.push(`/?edit_id=${projectId || ""}`)}
-                    >
-                        {t('roadmap.editSettings')}
-                    </Button>
+                    <div className="space-y-1">
+                        <CardTitle className="text-2xl">{projectTitle || t('roadmap.title')}</CardTitle>
+                        {studySeconds > 0 && (
+                            <p className="text-sm text-slate-500 flex items-center gap-1">
+                                <Clock className="h-4 w-4" />
+                                {t('roadmap.studyTime')}: {duration(studySeconds)}
+                            </p>
+                        )}
+                    </div>
+                    <div className="flex flex-wrap items-center gap-2">
+                        {exportId && (
+                            <div className="flex items-center gap-1 text-sm text-slate-500">
+                                <Download className="h-4 w-4" />
+                                {t('roadmap.export')}:
+                                <Button variant="ghost" size="sm" onClick={() => handleExport("markdown")}>
+                                    {t('roadmap.exportMarkdown')}
+                                </Button>
+                                <Button variant="ghost" size="sm" onClick={() => handleExport("json")}>
+                                    {t('roadmap.exportJson')}
+                                </Button>
+                            </div>
+                        )}
+                        <Button
+                            variant="outline"
+                            onClick={() => router.push(`/?edit_id=${projectId || ""}`)}
+                        >
+                            {t('roadmap.editSettings')}
+                        </Button>
+                    </div>
                 </CardHeader>
                 <CardContent className="space-y-4">
+                    {exportId && share && token && (
+                        <ShareRoadmap
+                            key={exportId}
+                            projectId={exportId}
+                            token={token}
+                            initial={share}
+                            labels={{
+                                share: t('roadmap.share.share'),
+                                description: t('roadmap.share.description'),
+                                showProgress: t('roadmap.share.showProgress'),
+                                copy: t('roadmap.share.copy'),
+                                copied: t('roadmap.share.copied'),
+                                revoke: t('roadmap.share.revoke'),
+                                error: t('roadmap.share.error'),
+                            }}
+                        />
+                    )}
                     {roadmap.map((step: any) => (
                         <div key={step.step} className="flex items-center justify-between p-3 border rounded-lg">
                             <Link href={`/quiz/${step.step}`} className="flex-1 font-medium">
                                 Step {step.step}: {step.title}
                             </Link>
+                            {step.study_seconds > 0 && (
+                                <div className="flex items-center gap-1 text-sm text-slate-500 mr-4">
+                                    <Clock className="h-4 w-4" />
+                                    {duration(step.study_seconds)}
+                                </div>
+                            )}
                             {stepScores[step.step] && (
                                 <div className="flex items-center gap-1 text-sm text-green-600">
                                     <CheckCircle2 className="h-4 w-4" />
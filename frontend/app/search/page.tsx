@@ -0,0 +1,145 @@
+"use client";
+
+import { useCallback, useEffect, useState } from "react";
+import Link from "next/link";
+import { useRouter, useSearchParams } from "next/navigation";
+import { useAuth } from "@/context/AuthContext";
+import { useTranslations } from "@/hooks/useTranslations";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Badge } from "@/components/ui/badge";
+import { Card, CardContent } from "@/components/ui/card";
+import { HighlightedSnippet } from "@/components/HighlightedSnippet";
+import { CheckCircle2, Search } from "lucide-react";
+import { API_BASE_URL } from "@/config/api";
+
+type Kind = "" | "project" | "step" | "quiz";
+type Completion = "" | "true" | "false";
+
+interface SearchResult {
+    kind: Exclude<Kind, "">;
+    project_id: number;
+    project_goal: string;
+    locale: string;
+    step_number?: number;
+    quiz_id?: number;
+    title: string;
+    snippet: string;
+    completed: boolean;
+}
+
+// プロジェクト・ステップ・問題をまとめて全文検索する
+export default function SearchPage() {
+    const { token } = useAuth();
+    const { t } = useTranslations("Search");
+    const router = useRouter();
+    const searchParams = useSearchParams();
+    const initialQuery = searchParams.get("q") ?? "";
+    const [query, setQuery] = useState(initialQuery);
+    const [kind, setKind] = useState<Kind>("");
+    const [locale, setLocale] = useState("");
+    const [completed, setCompleted] = useState<Completion>("");
+    const [results, setResults] = useState<SearchResult[] | null>(null);
+    const [loading, setLoading] = useState(false);
+
+    const runSearch = useCallback(async () => {
+        if (!token || !initialQuery.trim()) return;
+        const params = new URLSearchParams({ q: initialQuery });
+        if (kind) params.set("kind", kind);
+        if (locale) params.set("locale", locale);
+        if (completed) params.set("completed", completed);
+        setLoading(true);
+        try {
+            const res = await fetch(`${API_BASE_URL}/api/search?${params}`, {
+                headers: { Authorization: `Bearer ${token}` },
+            });
+            if (res.ok) setResults(await res.json());
+        } catch (err) {
+            console.error("Failed to search:", err);
+        } finally {
+            setLoading(false);
+        }
+    }, [token, initialQuery, kind, locale, completed]);
+
+    useEffect(() => {
+        runSearch();
+    }, [runSearch]);
+
+    // 検索語はURLに残す（サイドバーからも q で開く）
+    const handleSubmit = (e: React.FormEvent) => {
+        e.preventDefault();
+        if (query.trim()) router.push(`/search?q=${encodeURIComponent(query.trim())}`);
+    };
+
+    const hrefOf = (r: SearchResult) =>
+        r.kind === "project" ? `/roadmap?id=${r.project_id}` : `/quiz/${r.step_number}?projectId=${r.project_id}`;
+
+    const select = "border rounded-md px-2 py-1.5 text-sm bg-white";
+
+    return (
+        <div className="min-h-screen bg-gradient-to-br from-slate-50 to-slate-100 p-8">
+            <div className="max-w-4xl mx-auto space-y-6">
+                <div className="space-y-2">
+                    <h1 className="text-3xl font-bold text-slate-900 flex items-center gap-2">
+                        <Search className="h-7 w-7 text-emerald-600" />
+                        {t("title")}
+                    </h1>
+                    <p className="text-slate-600">{t("subtitle")}</p>
+                </div>
+
+                <form onSubmit={handleSubmit} className="flex gap-2">
+                    <Input value={query} onChange={(e) => setQuery(e.target.value)} placeholder={t("placeholder")} />
+                    <Button type="submit" disabled={loading || !query.trim()}>
+                        {t("search")}
+                    </Button>
+                </form>
+
+                <div className="flex flex-wrap gap-2">
+                    <select className={select} value={kind} onChange={(e) => setKind(e.target.value as Kind)}>
+                        <option value="">{t("kinds.all")}</option>
+                        <option value="project">{t("kinds.project")}</option>
+                        <option value="step">{t("kinds.step")}</option>
+                        <option value="quiz">{t("kinds.quiz")}</option>
+                    </select>
+                    <select className={select} value={locale} onChange={(e) => setLocale(e.target.value)}>
+                        <option value="">{t("locales.all")}</option>
+                        <option value="en">English</option>
+                        <option value="ja">日本語</option>
+                    </select>
+                    <select className={select} value={completed} onChange={(e) => setCompleted(e.target.value as Completion)}>
+                        <option value="">{t("completion.all")}</option>
+                        <option value="true">{t("completion.completed")}</option>
+                        <option value="false">{t("completion.inProgress")}</option>
+                    </select>
+                </div>
+
+                {results === null ? (
+                    <p className="text-sm text-slate-500">{t("hint")}</p>
+                ) : results.length === 0 ? (
+                    <p className="text-sm text-slate-500 py-6 text-center">{t("noResults")}</p>
+                ) : (
+                    <div className="space-y-3">
+                        {results.map((r) => (
+                            <Link key={`${r.kind}-${r.project_id}-${r.step_number ?? 0}-${r.quiz_id ?? 0}`} href={hrefOf(r)}>
+                                <Card className="hover:border-slate-400 transition-colors">
+                                    <CardContent className="pt-4 space-y-1">
+                                        <div className="flex items-center gap-2 text-xs text-slate-500">
+                                            <Badge variant="secondary">{t(`kinds.${r.kind}`)}</Badge>
+                                            <span className="truncate">
+                                                {r.kind === "project"
+                                                    ? r.project_goal
+                                                    : `${r.project_goal} · ${t("step", { step: r.step_number ?? 0, title: r.title })}`}
+                                            </span>
+                                            {r.completed && <CheckCircle2 className="h-4 w-4 text-green-600 shrink-0" />}
+                                        </div>
+                                        <HighlightedSnippet text={r.snippet} />
+                                    </CardContent>
+                                </Card>
+                            </Link>
+                        ))}
+                    </div>
+                )}
+            </div>
+        </div>
+    );
+}
@@ -0,0 +1,133 @@
+"use client";
+
+import { useEffect, useState } from "react";
+import { useParams } from "next/navigation";
+import { useTranslations } from "@/hooks/useTranslations";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Badge } from "@/components/ui/badge";
+import { CheckCircle2, Circle, Map as MapIcon } from "lucide-react";
+import { formatDuration } from "@/lib/utils";
+import { API_BASE_URL } from "@/config/api";
+
+interface SharedStep {
+    step: number;
+    title: string;
+    description: string;
+    is_completed?: boolean;
+    best_percentage?: number;
+}
+
+interface SharedRoadmap {
+    goal: string;
+    stack: string;
+    level: string;
+    created_at: string;
+    steps: SharedStep[];
+    progress?: {
+        steps_completed: number;
+        steps_total: number;
+        study_seconds: number;
+    };
+}
+
+// 共有されたロードマップの公開ページ（ログイン不要・閲覧専用）
+export default function SharedRoadmapPage() {
+    const params = useParams();
+    const slug = params.slug as string;
+    const { t } = useTranslations();
+    const [roadmap, setRoadmap] = useState<SharedRoadmap | null>(null);
+    const [notFound, setNotFound] = useState(false);
+
+    useEffect(() => {
+        const load = async () => {
+            try {
+                const res = await fetch(`${API_BASE_URL}/api/shared/${encodeURIComponent(slug)}`);
+                if (!res.ok) {
+                    setNotFound(true);
+                    return;
+                }
+                setRoadmap(await res.json());
+            } catch (err) {
+                console.error("Failed to load shared roadmap:", err);
+                setNotFound(true);
+            }
+        };
+        load();
+    }, [slug]);
+
+    if (notFound) {
+        return (
+            <div className="min-h-screen bg-gradient-to-br from-slate-50 to-slate-100 p-8 flex items-center justify-center">
+                <p className="text-lg text-slate-600">{t("Shared.notFound")}</p>
+            </div>
+        );
+    }
+    if (!roadmap) return null;
+
+    const progress = roadmap.progress;
+
+    return (
+        <div className="min-h-screen bg-gradient-to-br from-slate-50 to-slate-100 p-4 md:p-8">
+            <Card className="max-w-3xl mx-auto">
+                <CardHeader className="space-y-2">
+                    <p className="text-sm text-emerald-700 flex items-center gap-1">
+                        <MapIcon className="h-4 w-4" />
+                        {t("Shared.label")}
+                    </p>
+                    <CardTitle className="text-2xl">{roadmap.goal}</CardTitle>
+                    <CardDescription className="flex flex-wrap items-center gap-2">
+                        {roadmap.stack && <span>{roadmap.stack}</span>}
+                        {roadmap.level && <Badge variant="outline">{roadmap.level}</Badge>}
+                        <span>{t("Shared.created", { date: new Date(roadmap.created_at).toLocaleDateString() })}</span>
+                    </CardDescription>
+                    {progress && (
+                        <div className="space-y-1 pt-2">
+                            <div className="flex justify-between text-sm text-slate-600">
+                                <span>{t("Shared.progress", { completed: progress.steps_completed, total: progress.steps_total })}</span>
+                                {progress.study_seconds > 0 && (
+                                    <span>
+                                        {t("Shared.studyTime", {
+                                            time: formatDuration(progress.study_seconds, {
+                                                hoursMinutes: t("common.durationHoursMinutes"),
+                                                minutes: t("common.durationMinutes"),
+                                            }),
+                                        })}
+                                    </span>
+                                )}
+                            </div>
+                            <div className="h-2 rounded-full bg-slate-200 overflow-hidden">
+                                <div
+                                    className="h-full bg-emerald-500"
+                                    style={{ width: `${progress.steps_total ? (progress.steps_completed / progress.steps_total) * 100 : 0}%` }}
+                                />
+                            </div>
+                        </div>
+                    )}
+                </CardHeader>
+                <CardContent>
+                    <ol className="space-y-3">
+                        {roadmap.steps.map((s) => (
+                            <li key={s.step} className="flex gap-3 p-3 border rounded-lg">
+                                {progress &&
+                                    (s.is_completed ? (
+                                        <CheckCircle2 className="h-5 w-5 text-green-600 shrink-0" />
+                                    ) : (
+                                        <Circle className="h-5 w-5 text-slate-300 shrink-0" />
+                                    ))}
+                                <div className="flex-1 space-y-1">
+                                    <p className="font-medium text-slate-900">
+                                        {t("Shared.step", { step: s.step })}: {s.title}
+                                    </p>
+                                    {s.description && <p className="text-sm text-slate-500">{s.description}</p>}
+                                </div>
+                                {s.best_percentage !== undefined && (
+                                    <span className="text-sm font-semibold text-slate-700">{s.best_percentage}%</span>
+                                )}
+                            </li>
+                        ))}
+                    </ol>
+                </CardContent>
+            </Card>
+        </div>
+    );
+}
@@ -0,0 +1,185 @@
+"use client";
+
+import { useCallback, useEffect, useState } from "react";
+import { useRouter } from "next/navigation";
+import { useAuth } from "@/context/AuthContext";
+import { useProjects } from "@/context/ProjectContext";
+import { useTranslations } from "@/hooks/useTranslations";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Badge } from "@/components/ui/badge";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Accordion, AccordionContent, AccordionItem, AccordionTrigger } from "@/components/ui/accordion";
+import { LibraryBig, Copy } from "lucide-react";
+import { API_BASE_URL } from "@/config/api";
+
+interface TemplateStep {
+    step: number;
+    title: string;
+    description: string;
+    quizzes: number;
+}
+
+interface RoadmapTemplate {
+    id: number;
+    title: string;
+    description: string;
+    goal: string;
+    stack: string;
+    level: string;
+    locale: string;
+    tags: string[];
+    clone_count: number;
+    steps: TemplateStep[];
+    quizzes: number;
+}
+
+// 管理者が公開したロードマップのテンプレート一覧（AI生成なしでプロジェクトを作成）
+export default function TemplatesPage() {
+    const { token } = useAuth();
+    const { fetchProjects } = useProjects();
+    const { t, locale } = useTranslations("Templates");
+    const router = useRouter();
+    const [query, setQuery] = useState("");
+    const [tag, setTag] = useState("");
+    const [level, setLevel] = useState("");
+    const [allLocales, setAllLocales] = useState(false);
+    const [templates, setTemplates] = useState<RoadmapTemplate[]>([]);
+    const [cloningId, setCloningId] = useState<number | null>(null);
+    const [error, setError] = useState<string | null>(null);
+
+    const fetchTemplates = useCallback(async () => {
+        if (!token) return;
+        const params = new URLSearchParams();
+        if (query.trim()) params.set("q", query.trim());
+        if (tag) params.set("tag", tag);
+        if (level) params.set("level", level);
+        if (!allLocales) params.set("locale", locale);
+        try {
+            const res = await fetch(`${API_BASE_URL}/api/templates?${params}`, {
+                headers: { Authorization: `Bearer ${token}` },
+            });
+            if (res.ok) setTemplates(await res.json());
+        } catch (err) {
+            console.error("Failed to fetch templates:", err);
+        }
+    }, [token, query, tag, level, allLocales, locale]);
+
+    useEffect(() => {
+        const timer = setTimeout(fetchTemplates, 300);
+        return () => clearTimeout(timer);
+    }, [fetchTemplates]);
+
+    const handleClone = async (id: number) => {
+        setError(null);
+        setCloningId(id);
+        try {
+            const res = await fetch(`${API_BASE_URL}/api/templates/${id}/clone`, {
+                method: "POST",
+                headers: { Authorization: `Bearer ${token}` },
+            });
+            const data = await res.json().catch(() => ({}));
+            if (!res.ok) {
+                setError(data.error || t("cloneError"));
+                return;
+            }
+            await fetchProjects();
+            router.push(`/roadmap?id=${data.id}`);
+        } catch (err) {
+            console.error("Failed to clone template:", err);
+            setError(t("cloneError"));
+        } finally {
+            setCloningId(null);
+        }
+    };
+
+    const select = "border rounded-md px-2 py-1.5 text-sm bg-white";
+
+    return (
+        <div className="min-h-screen bg-gradient-to-br from-slate-50 to-slate-100 p-8">
+            <div className="max-w-4xl mx-auto space-y-6">
+                <div className="space-y-2">
+                    <h1 className="text-3xl font-bold text-slate-900 flex items-center gap-2">
+                        <LibraryBig className="h-7 w-7 text-emerald-600" />
+                        {t("title")}
+                    </h1>
+                    <p className="text-slate-600">{t("subtitle")}</p>
+                </div>
+
+                <div className="flex flex-wrap gap-2">
+                    <Input className="flex-1 min-w-[200px]" value={query} onChange={(e) => setQuery(e.target.value)} placeholder={t("placeholder")} />
+                    <select className={select} value={level} onChange={(e) => setLevel(e.target.value)}>
+                        <option value="">{t("allLevels")}</option>
+                        <option value="beginner">{t("levels.beginner")}</option>
+                        <option value="intermediate">{t("levels.intermediate")}</option>
+                        <option value="advanced">{t("levels.advanced")}</option>
+                    </select>
+                    <label className="flex items-center gap-1 text-sm text-slate-600">
+                        <input type="checkbox" checked={allLocales} onChange={(e) => setAllLocales(e.target.checked)} />
+                        {t("allLocales")}
+                    </label>
+                </div>
+
+                {tag && (
+                    <button className="text-sm text-slate-600" onClick={() => setTag("")}>
+                        {t("tagFilter", { tag })} ✕
+                    </button>
+                )}
+
+                {error && <p className="text-sm text-red-600">{error}</p>}
+
+                {templates.length === 0 ? (
+                    <p className="text-sm text-slate-500 py-6 text-center">{t("empty")}</p>
+                ) : (
+                    <div className="space-y-4">
+                        {templates.map((tmpl) => (
+                            <Card key={tmpl.id}>
+                                <CardHeader className="flex flex-row items-start justify-between gap-4 space-y-0">
+                                    <div className="space-y-1">
+                                        <CardTitle className="text-lg">{tmpl.title}</CardTitle>
+                                        <CardDescription>{tmpl.description || tmpl.goal}</CardDescription>
+                                        <div className="flex flex-wrap items-center gap-1 pt-1">
+                                            {tmpl.stack && <span className="text-xs text-slate-500 mr-1">{tmpl.stack}</span>}
+                                            {tmpl.level && <Badge variant="outline">{tmpl.level}</Badge>}
+                                            {tmpl.tags.map((tg) => (
+                                                <Badge key={tg} variant="secondary" className="cursor-pointer" onClick={() => setTag(tg)}>
+                                                    #{tg}
+                                                </Badge>
+                                            ))}
+                                        </div>
+                                    </div>
+                                    <Button onClick={() => handleClone(tmpl.id)} disabled={cloningId !== null} className="gap-1 shrink-0">
+                                        <Copy className="h-4 w-4" />
+                                        {cloningId === tmpl.id ? t("cloning") : t("clone")}
+                                    </Button>
+                                </CardHeader>
+                                <CardContent>
+                                    <p className="text-xs text-slate-500 mb-2">
+                                        {t("stats", { steps: tmpl.steps.length, quizzes: tmpl.quizzes, clones: tmpl.clone_count })}
+                                    </p>
+                                    <Accordion type="single" collapsible>
+                                        <AccordionItem value="steps" className="border-none">
+                                            <AccordionTrigger className="text-sm py-2">{t("showSteps")}</AccordionTrigger>
+                                            <AccordionContent>
+                                                <ol className="space-y-2">
+                                                    {tmpl.steps.map((s) => (
+                                                        <li key={s.step} className="text-sm">
+                                                            <span className="font-medium text-slate-900">
+                                                                {s.step}. {s.title}
+                                                            </span>
+                                                            {s.description && <p className="text-slate-500">{s.description}</p>}
+                                                        </li>
+                                                    ))}
+                                                </ol>
+                                            </AccordionContent>
+                                        </AccordionItem>
+                                    </Accordion>
+                                </CardContent>
+                            </Card>
+                        ))}
+                    </div>
+                )}
+            </div>
+        </div>
+    );
+}
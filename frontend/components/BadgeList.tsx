@@ -0,0 +1,105 @@
+"use client";
+
+import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
+import { Award, Lock } from "lucide-react";
+
+// バッジの定義と学習者の達成状況（GET /api/me/badges）
+export interface BadgeStatus {
+  key: string;
+  name: string;
+  name_ja: string;
+  description: string;
+  description_ja: string;
+  metric: string;
+  threshold: number;
+  earned?: boolean;
+  awarded_at?: string;
+  progress?: number;
+}
+
+// 表示言語に合わせたバッジ名と説明（未設定ならもう一方の言語）
+export function badgeText(badge: BadgeStatus, locale: string) {
+  const ja = locale === "ja";
+  return {
+    name: (ja ? badge.name_ja : badge.name) || badge.name || badge.name_ja,
+    description: (ja ? badge.description_ja : badge.description) || badge.description || badge.description_ja,
+  };
+}
+
+interface BadgeListProps {
+  earned: BadgeStatus[];
+  available: BadgeStatus[];
+  locale: string;
+  labels: {
+    title: string;
+    earned: string;
+    available: string;
+    none: string;
+  };
+}
+
+// 獲得したバッジと、まだ獲得していないバッジの進み具合
+export function BadgeList({ earned, available, locale, labels }: BadgeListProps) {
+  return (
+    <Card>
+      <CardHeader>
+        <CardTitle className="text-lg">{labels.title}</CardTitle>
+      </CardHeader>
+      <CardContent className="space-y-4">
+        <div>
+          <p className="text-sm font-medium text-slate-700 mb-2">{labels.earned}</p>
+          {earned.length === 0 ? (
+            <p className="text-sm text-slate-500">{labels.none}</p>
+          ) : (
+            <div className="grid grid-cols-1 sm:grid-cols-2 md:grid-cols-3 gap-2">
+              {earned.map((badge) => {
+                const text = badgeText(badge, locale);
+                return (
+                  <div key={badge.key} className="flex items-start gap-2 rounded-lg border border-amber-200 bg-amber-50 p-3">
+                    <Award className="h-5 w-5 shrink-0 text-amber-500" />
+                    <div>
+                      <p className="text-sm font-semibold text-slate-900">{text.name}</p>
+                      <p className="text-xs text-slate-600">{text.description}</p>
+                      {badge.awarded_at && (
+                        <p className="text-xs text-slate-400 mt-1">{new Date(badge.awarded_at).toLocaleDateString(locale)}</p>
+                      )}
+                    </div>
+                  </div>
+                );
+              })}
+            </div>
+          )}
+        </div>
+
+        {available.length > 0 && (
+          <div>
+            <p className="text-sm font-medium text-slate-700 mb-2">{labels.available}</p>
+            <div className="grid grid-cols-1 sm:grid-cols-2 md:grid-cols-3 gap-2">
+              {available.map((badge) => {
+                const text = badgeText(badge, locale);
+                const progress = badge.progress ?? 0;
+                return (
+                  <div key={badge.key} className="flex items-start gap-2 rounded-lg border border-slate-200 p-3">
+                    <Lock className="h-5 w-5 shrink-0 text-slate-400" />
+                    <div className="flex-1">
+                      <p className="text-sm font-semibold text-slate-700">{text.name}</p>
+                      <p className="text-xs text-slate-500">{text.description}</p>
+                      <div className="mt-2 flex items-center gap-2">
+                        <div className="h-1.5 flex-1 rounded-full bg-slate-100 overflow-hidden">
+                          <div className="h-full bg-slate-400" style={{ width: `${(progress * 100) / badge.threshold}%` }} />
+                        </div>
+                        <span className="text-xs text-slate-500">
+                          {progress} / {badge.threshold}
+                        </span>
+                      </div>
+                    </div>
+                  </div>
+                );
+              })}
+            </div>
+          </div>
+        )}
+      </CardContent>
+    </Card>
+  );
+}
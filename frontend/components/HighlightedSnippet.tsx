@@ -0,0 +1,21 @@
+"use client";
+
+import { cn } from "@/lib/utils";
+
+// 検索結果の抜粋で [一致箇所] を強調表示する
+export function HighlightedSnippet({ text, className }: { text: string; className?: string }) {
+  const parts = text.split(/(\[[^\]]*\])/);
+  return (
+    <p className={cn("text-sm text-slate-700 whitespace-pre-wrap", className)}>
+      {parts.map((part, i) =>
+        part.startsWith("[") && part.endsWith("]") ? (
+          <mark key={i} className="bg-yellow-200 rounded px-0.5">
+            {part.slice(1, -1)}
+          </mark>
+        ) : (
+          <span key={i}>{part}</span>
+        )
+      )}
+    </p>
+  );
+}
@@ -0,0 +1,137 @@
+"use client";
+
+import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
+import { TrendingUp, TrendingDown, Minus, Flame, Snowflake, Star } from "lucide-react";
+import type { Progress, XP, Streak } from "@/context/ProjectContext";
+import { formatDuration } from "@/lib/utils";
+
+interface ProgressOverviewProps {
+  progress: Progress;
+  xp?: XP | null;
+  streak?: Streak | null;
+  labels: {
+    title: string;
+    stepsCompleted: string;
+    averageScore: string;
+    trend: string;
+    lastActivity: string;
+    daysAgo: string; // {days} を含む
+    today: string;
+    weakTopics: string;
+    correctRate: string; // {rate} を含む
+    level: string; // {level} を含む
+    xpToNext: string; // {xp} を含む
+    todayXP: string; // {xp} を含む
+    streakDays: string; // {days} を含む
+    longestStreak: string; // {days} を含む
+    freezes: string; // {count} を含む
+    streakAtRisk: string;
+    studyTime: string;
+    durationHoursMinutes: string; // {h} と {m} を含む
+    durationMinutes: string; // {m} を含む
+  };
+}
+
+// 全プロジェクトの進捗の概要（完了ステップ数、平均点とその推移、苦手な概念）
+export function ProgressOverview({ progress, xp, streak, labels }: ProgressOverviewProps) {
+  const TrendIcon = progress.trend.direction === "up" ? TrendingUp : progress.trend.direction === "down" ? TrendingDown : Minus;
+  const trendColor =
+    progress.trend.direction === "up" ? "text-green-600" : progress.trend.direction === "down" ? "text-red-600" : "text-slate-500";
+  const levelProgress = xp ? ((xp.xp - xp.level_xp) * 100) / (xp.next_level_xp - xp.level_xp) : 0;
+  const days = progress.seconds_since_last_activity !== null ? Math.floor(progress.seconds_since_last_activity / 86400) : null;
+
+  return (
+    <Card>
+      <CardHeader>
+        <CardTitle className="text-lg">{labels.title}</CardTitle>
+      </CardHeader>
+      <CardContent className="space-y-4">
+        {/* レベルと連続学習日数 */}
+        {(xp || streak) && (
+          <div className="flex flex-wrap items-center gap-4">
+            {xp && (
+              <div className="flex-1 min-w-48">
+                <div className="flex items-center justify-between text-sm">
+                  <span className="flex items-center gap-1 font-semibold text-slate-900">
+                    <Star className="h-4 w-4 text-yellow-500" />
+                    {labels.level.replace("{level}", String(xp.level))}
+                  </span>
+                  <span className="text-xs text-slate-500">{labels.todayXP.replace("{xp}", String(xp.today_xp))}</span>
+                </div>
+                <div className="mt-1 h-2 rounded-full bg-slate-100 overflow-hidden">
+                  <div className="h-full bg-yellow-400" style={{ width: `${levelProgress}%` }} />
+                </div>
+                <p className="mt-1 text-xs text-slate-500">{labels.xpToNext.replace("{xp}", String(xp.next_level_xp - xp.xp))}</p>
+              </div>
+            )}
+            {streak && (
+              <div className="flex items-center gap-3">
+                <span
+                  className={`flex items-center gap-1 text-lg font-bold ${streak.active_today ? "text-orange-500" : "text-slate-400"}`}
+                  title={labels.longestStreak.replace("{days}", String(streak.longest))}
+                >
+                  <Flame className="h-5 w-5" />
+                  {labels.streakDays.replace("{days}", String(streak.current))}
+                </span>
+                {streak.freezes > 0 && (
+                  <span className="flex items-center gap-1 text-xs text-sky-600">
+                    <Snowflake className="h-4 w-4" />
+                    {labels.freezes.replace("{count}", String(streak.freezes))}
+                  </span>
+                )}
+                {streak.at_risk && streak.current > 0 && <span className="text-xs text-amber-600">{labels.streakAtRisk}</span>}
+              </div>
+            )}
+          </div>
+        )}
+
+        <div className="grid grid-cols-2 md:grid-cols-5 gap-4 text-center">
+          <div>
+            <p className="text-xs text-slate-500">{labels.stepsCompleted}</p>
+            <p className="text-2xl font-bold text-slate-900">
+              {progress.steps_completed} / {progress.steps_total}
+            </p>
+          </div>
+          <div>
+            <p className="text-xs text-slate-500">{labels.averageScore}</p>
+            <p className="text-2xl font-bold text-slate-900">
+              {progress.average_percentage !== null ? `${progress.average_percentage}%` : "-"}
+            </p>
+          </div>
+          <div>
+            <p className="text-xs text-slate-500">{labels.trend}</p>
+            <p className={`text-2xl font-bold flex items-center justify-center gap-1 ${trendColor}`}>
+              <TrendIcon className="h-5 w-5" />
+              {progress.trend.change > 0 ? `+${progress.trend.change}` : progress.trend.change}
+            </p>
+          </div>
+          <div>
+            <p className="text-xs text-slate-500">{labels.studyTime}</p>
+            <p className="text-2xl font-bold text-slate-900">
+              {formatDuration(progress.study_seconds, { hoursMinutes: labels.durationHoursMinutes, minutes: labels.durationMinutes })}
+            </p>
+          </div>
+          <div>
+            <p className="text-xs text-slate-500">{labels.lastActivity}</p>
+            <p className="text-2xl font-bold text-slate-900">
+              {days === null ? "-" : days === 0 ? labels.today : labels.daysAgo.replace("{days}", String(days))}
+            </p>
+          </div>
+        </div>
+
+        {progress.weak_topics.length > 0 && (
+          <div>
+            <p className="text-sm font-medium text-slate-700 mb-2">{labels.weakTopics}</p>
+            <div className="flex flex-wrap gap-2">
+              {progress.weak_topics.map((topic) => (
+                <span key={topic.topic} className="text-xs rounded-full bg-amber-50 border border-amber-200 text-amber-800 px-3 py-1">
+                  {topic.topic} · {labels.correctRate.replace("{rate}", String(topic.correct_rate))}
+                </span>
+              ))}
+            </div>
+          </div>
+        )}
+      </CardContent>
+    </Card>
+  );
+}
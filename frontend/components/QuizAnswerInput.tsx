@@ -0,0 +1,181 @@
+"use client";
+
+import { Quiz, QuizResponse } from "@/src/roadmap";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Textarea } from "@/components/ui/textarea";
+import { cn } from "@/lib/utils";
+import { CheckCircle2, XCircle, ArrowUp, ArrowDown } from "lucide-react";
+
+interface QuizAnswerInputProps {
+  quiz: Quiz;
+  value: QuizResponse | null;
+  onChange: (value: QuizResponse) => void;
+  showResult: boolean;
+  labels: {
+    selectAll: string;
+    orderHint: string;
+    textPlaceholder: string;
+    correctAnswer: string;
+    modelAnswer: string;
+  };
+}
+
+// 並べ替え問題の初期状態は表示順そのまま
+export function initialOrder(quiz: Quiz): number[] {
+  return quiz.options.map((_, index) => index);
+}
+
+// 回答ボタンを有効にできるか
+export function isResponseReady(quiz: Quiz, value: QuizResponse | null): boolean {
+  switch (quiz.type) {
+    case "multiple_select":
+      return (value?.choices?.length ?? 0) > 0;
+    case "ordering":
+      return true;
+    case "fill_blank":
+    case "code_output":
+    case "free_response":
+      return (value?.text?.trim() ?? "") !== "";
+    default:
+      return value?.choice !== undefined;
+  }
+}
+
+// 複数選択・並べ替え・入力式・記述式の回答欄（単一選択はページ側のRadioGroupで扱う）
+export function QuizAnswerInput({ quiz, value, onChange, showResult, labels }: QuizAnswerInputProps) {
+  if (quiz.type === "multiple_select") {
+    const selected = value?.choices ?? [];
+    const toggle = (index: number) => {
+      if (showResult) return;
+      const next = selected.includes(index)
+        ? selected.filter((i) => i !== index)
+        : [...selected, index];
+      onChange({ choices: next });
+    };
+
+    return (
+      <div className="space-y-2">
+        <p className="text-sm text-slate-500">{labels.selectAll}</p>
+        {quiz.options.map((option, index) => {
+          const isSelected = selected.includes(index);
+          const isAnswer = quiz.answer_indexes?.includes(index) ?? false;
+          return (
+            <button
+              key={index}
+              type="button"
+              onClick={() => toggle(index)}
+              disabled={showResult}
+              className={cn(
+                "w-full text-left flex items-center gap-3 p-4 rounded-lg border-2 transition-all",
+                showResult
+                  ? isAnswer
+                    ? "border-green-500 bg-green-50"
+                    : isSelected
+                      ? "border-red-500 bg-red-50"
+                      : "border-slate-200"
+                  : isSelected
+                    ? "border-slate-900 bg-slate-50"
+                    : "border-slate-200 hover:border-slate-300"
+              )}
+            >
+              <span
+                className={cn(
+                  "h-4 w-4 rounded border flex-shrink-0",
+                  isSelected ? "bg-slate-900 border-slate-900" : "border-slate-400"
+                )}
+              />
+              <span className="flex-1 leading-relaxed">{option}</span>
+              {showResult && isAnswer && <CheckCircle2 className="h-5 w-5 text-green-600 flex-shrink-0" />}
+              {showResult && isSelected && !isAnswer && <XCircle className="h-5 w-5 text-red-600 flex-shrink-0" />}
+            </button>
+          );
+        })}
+      </div>
+    );
+  }
+
+  if (quiz.type === "ordering") {
+    const order = value?.choices ?? initialOrder(quiz);
+    const move = (position: number, delta: number) => {
+      const target = position + delta;
+      if (showResult || target < 0 || target >= order.length) return;
+      const next = [...order];
+      [next[position], next[target]] = [next[target], next[position]];
+      onChange({ choices: next });
+    };
+
+    return (
+      <div className="space-y-2">
+        <p className="text-sm text-slate-500">{labels.orderHint}</p>
+        {order.map((optionIndex, position) => {
+          const isRight = quiz.answer_indexes?.[position] === optionIndex;
+          return (
+            <div
+              key={optionIndex}
+              className={cn(
+                "flex items-center gap-3 p-3 rounded-lg border-2",
+                showResult ? (isRight ? "border-green-500 bg-green-50" : "border-red-500 bg-red-50") : "border-slate-200"
+              )}
+            >
+              <span className="text-xs font-bold px-2 py-0.5 rounded bg-slate-100 text-slate-700">{position + 1}</span>
+              <span className="flex-1 leading-relaxed">{quiz.options[optionIndex]}</span>
+              {!showResult && (
+                <div className="flex gap-1">
+                  <Button type="button" variant="ghost" size="icon" onClick={() => move(position, -1)} disabled={position === 0}>
+                    <ArrowUp className="h-4 w-4" />
+                  </Button>
+                  <Button type="button" variant="ghost" size="icon" onClick={() => move(position, 1)} disabled={position === order.length - 1}>
+                    <ArrowDown className="h-4 w-4" />
+                  </Button>
+                </div>
+              )}
+            </div>
+          );
+        })}
+        {showResult && quiz.answer_indexes && (
+          <p className="text-sm text-slate-600">
+            {labels.correctAnswer}: {quiz.answer_indexes.map((i) => quiz.options[i]).join(" → ")}
+          </p>
+        )}
+      </div>
+    );
+  }
+
+  if (quiz.type === "free_response") {
+    return (
+      <div className="space-y-2">
+        <Textarea
+          value={value?.text ?? ""}
+          onChange={(e) => onChange({ text: e.target.value })}
+          placeholder={labels.textPlaceholder}
+          disabled={showResult}
+          rows={5}
+        />
+        {showResult && quiz.answer_text && (
+          <p className="text-sm text-slate-600 whitespace-pre-wrap">
+            {labels.modelAnswer}: {quiz.answer_text}
+          </p>
+        )}
+      </div>
+    );
+  }
+
+  // fill_blank / code_output
+  return (
+    <div className="space-y-2">
+      <Input
+        value={value?.text ?? ""}
+        onChange={(e) => onChange({ text: e.target.value })}
+        placeholder={labels.textPlaceholder}
+        disabled={showResult}
+        className={cn(quiz.type === "code_output" && "font-mono")}
+      />
+      {showResult && quiz.answer_text && (
+        <p className="text-sm text-slate-600">
+          {labels.correctAnswer}: <span className={cn("font-semibold", quiz.type === "code_output" && "font-mono")}>{quiz.answer_text}</span>
+        </p>
+      )}
+    </div>
+  );
+}
@@ -0,0 +1,134 @@
+"use client";
+
+import { useState } from "react";
+import { Button } from "@/components/ui/button";
+import { Textarea } from "@/components/ui/textarea";
+import {
+  AlertDialog,
+  AlertDialogCancel,
+  AlertDialogContent,
+  AlertDialogDescription,
+  AlertDialogFooter,
+  AlertDialogHeader,
+  AlertDialogTitle,
+  AlertDialogTrigger,
+} from "@/components/ui/alert-dialog";
+import { Flag } from "lucide-react";
+import { API_BASE_URL } from "@/config/api";
+
+export const REPORT_CATEGORIES = ["wrong_answer", "unclear", "typo", "off_topic", "other"] as const;
+export type ReportCategory = (typeof REPORT_CATEGORIES)[number];
+
+interface ReportQuizButtonProps {
+  quizId: number;
+  token: string;
+  labels: {
+    button: string;
+    title: string;
+    description: string;
+    comment: string;
+    submit: string;
+    cancel: string;
+    done: string;
+    alreadyReported: string;
+    error: string;
+    categories: Record<ReportCategory, string>;
+  };
+}
+
+// 問題の不備を報告するボタン（報告が多い問題はレビューまで非表示になる）
+export function ReportQuizButton({ quizId, token, labels }: ReportQuizButtonProps) {
+  const [open, setOpen] = useState(false);
+  const [category, setCategory] = useState<ReportCategory>("wrong_answer");
+  const [comment, setComment] = useState("");
+  const [submitting, setSubmitting] = useState(false);
+  const [message, setMessage] = useState<string | null>(null);
+  const [reported, setReported] = useState(false);
+
+  const handleSubmit = async () => {
+    setSubmitting(true);
+    setMessage(null);
+    try {
+      const res = await fetch(`${API_BASE_URL}/api/quizzes/${quizId}/report`, {
+        method: "POST",
+        headers: {
+          "Content-Type": "application/json",
+          Authorization: `Bearer ${token}`,
+        },
+        body: JSON.stringify({ category, comment }),
+      });
+      if (res.status === 409) {
+        setReported(true);
+        setMessage(labels.alreadyReported);
+        return;
+      }
+      if (!res.ok) throw new Error("Failed to report question");
+      setReported(true);
+      setMessage(labels.done);
+      setComment("");
+    } catch (err) {
+      console.error("Failed to report question:", err);
+      setMessage(labels.error);
+    } finally {
+      setSubmitting(false);
+    }
+  };
+
+  return (
+    <AlertDialog
+      open={open}
+      onOpenChange={(next) => {
+        setOpen(next);
+        if (next) setMessage(null);
+      }}
+    >
+      <AlertDialogTrigger asChild>
+        <Button variant="ghost" size="sm" className="gap-1 text-slate-500">
+          <Flag className="h-4 w-4" />
+          {labels.button}
+        </Button>
+      </AlertDialogTrigger>
+      <AlertDialogContent>
+        <AlertDialogHeader>
+          <AlertDialogTitle>{labels.title}</AlertDialogTitle>
+          <AlertDialogDescription>{labels.description}</AlertDialogDescription>
+        </AlertDialogHeader>
+
+        <div className="space-y-3">
+          <div className="flex flex-wrap gap-2">
+            {REPORT_CATEGORIES.map((c) => (
+              <Button
+                key={c}
+                type="button"
+                size="sm"
+                variant={category === c ? "default" : "outline"}
+                onClick={() => setCategory(c)}
+                disabled={reported}
+              >
+                {labels.categories[c]}
+              </Button>
+            ))}
+          </div>
+          <Textarea
+            value={comment}
+            onChange={(e) => setComment(e.target.value)}
+            placeholder={labels.comment}
+            rows={3}
+            maxLength={1000}
+            disabled={reported}
+          />
+          {message && <p className="text-sm text-slate-600">{message}</p>}
+        </div>
+
+        <AlertDialogFooter>
+          <AlertDialogCancel>{labels.cancel}</AlertDialogCancel>
+          {!reported && (
+            <Button onClick={handleSubmit} disabled={submitting} className="bg-slate-900 hover:bg-slate-800">
+              {labels.submit}
+            </Button>
+          )}
+        </AlertDialogFooter>
+      </AlertDialogContent>
+    </AlertDialog>
+  );
+}
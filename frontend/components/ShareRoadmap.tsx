@@ -0,0 +1,111 @@
+"use client";
+
+import { useState } from "react";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Switch } from "@/components/ui/switch";
+import { Share2, Copy, Link2Off } from "lucide-react";
+import { API_BASE_URL } from "@/config/api";
+
+export interface ShareSettings {
+  enabled: boolean;
+  slug?: string;
+  show_progress: boolean;
+}
+
+interface ShareRoadmapProps {
+  projectId: number;
+  token: string;
+  initial: ShareSettings;
+  labels: {
+    share: string;
+    description: string;
+    showProgress: string;
+    copy: string;
+    copied: string;
+    revoke: string;
+    error: string;
+  };
+}
+
+// ロードマップの公開リンク（ログイン不要・閲覧専用、解答は含まれない）
+export function ShareRoadmap({ projectId, token, initial, labels }: ShareRoadmapProps) {
+  const [share, setShare] = useState<ShareSettings>(initial);
+  const [saving, setSaving] = useState(false);
+  const [copied, setCopied] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  const url = `${API_BASE_URL}/api/projects/${projectId}/share`;
+  const link = share.slug ? `${window.location.origin}/shared/${share.slug}` : "";
+
+  const request = async (method: "PUT" | "DELETE", body?: object) => {
+    setError(null);
+    setSaving(true);
+    try {
+      const res = await fetch(url, {
+        method,
+        headers: { Authorization: `Bearer ${token}`, "Content-Type": "application/json" },
+        body: body ? JSON.stringify(body) : undefined,
+      });
+      const data = await res.json().catch(() => ({}));
+      if (!res.ok) {
+        setError(data.error || labels.error);
+        return;
+      }
+      setShare(data);
+    } catch (err) {
+      console.error("Failed to update share link:", err);
+      setError(labels.error);
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  const handleCopy = async () => {
+    await navigator.clipboard.writeText(link);
+    setCopied(true);
+    setTimeout(() => setCopied(false), 2000);
+  };
+
+  if (!share.enabled) {
+    return (
+      <div className="space-y-1">
+        <Button variant="outline" size="sm" className="gap-1" onClick={() => request("PUT", {})} disabled={saving}>
+          <Share2 className="h-4 w-4" />
+          {labels.share}
+        </Button>
+        {error && <p className="text-sm text-red-600">{error}</p>}
+      </div>
+    );
+  }
+
+  return (
+    <div className="space-y-2 rounded-lg border p-3">
+      <p className="text-sm text-slate-600">{labels.description}</p>
+      <div className="flex gap-2">
+        <Input readOnly value={link} onFocus={(e) => e.target.select()} className="text-sm" />
+        <Button variant="outline" size="sm" className="gap-1 shrink-0" onClick={handleCopy}>
+          <Copy className="h-4 w-4" />
+          {copied ? labels.copied : labels.copy}
+        </Button>
+      </div>
+      <div className="flex items-center justify-between gap-2">
+        <div className="flex items-center gap-2">
+          <Switch
+            id="share-progress"
+            checked={share.show_progress}
+            disabled={saving}
+            onCheckedChange={(checked) => request("PUT", { show_progress: checked })}
+          />
+          <Label htmlFor="share-progress" className="text-sm">{labels.showProgress}</Label>
+        </div>
+        <Button variant="ghost" size="sm" className="gap-1 text-red-600" onClick={() => request("DELETE")} disabled={saving}>
+          <Link2Off className="h-4 w-4" />
+          {labels.revoke}
+        </Button>
+      </div>
+      {error && <p className="text-sm text-red-600">{error}</p>}
+    </div>
+  );
+}
@@ -30,6 +30,9 @@ import {
     HelpCircle,
     Settings,
     Shield,
+    Trophy,
+    NotebookPen,
+    LibraryBig,
     X
 } from "lucide-react";
 import { cn } from "@/lib/utils";
@@ -121,6 +124,13 @@ export function Sidebar() {
                                 className="pl-8 bg-emerald-950 border-emerald-800 text-slate-200 h-9 text-sm focus-visible:ring-emerald-600"
                                 value={searchQuery}
                                 onChange={(e) => setSearchQuery(e.target.value)}
+                                onKeyDown={(e) => {
+                                    // Enterでステップや問題も含めて全文検索する
+                                    if (e.key === "Enter" && searchQuery.trim()) {
+                                        router.push(`/search?q=${encodeURIComponent(searchQuery.trim())}`);
+                                    }
+                                }}
+                                title={t("sidebar.searchAll")}
                             />
                         </div>
                     </div>
@@ -150,7 +160,10 @@ export function Sidebar() {
                                     {!isCollapsed && (
                                         <div className="flex flex-col min-w-0">
                                             <span className="truncate font-medium">{project.goal}</span>
-                                            <span className="truncate text-xs text-emerald-400/70">{project.stack}</span>
+                                            <span className="truncate text-xs text-emerald-400/70">
+                                                {project.stack}
+                                                {project.steps_total > 0 && ` · ${project.completion_percentage}%`}
+                                            </span>
                                         </div>
                                     )}
                                 </Link>
@@ -224,6 +237,27 @@ export function Sidebar() {
                                 <Sparkles className="mr-2 h-4 w-4" />
                                 <span>{t("menu.upgradePlan")}</span>
                             </DropdownMenuItem>
+                            <DropdownMenuItem
+                                className="cursor-pointer focus:bg-emerald-800 focus:text-white"
+                                onClick={() => router.push("/leaderboard")}
+                            >
+                                <Trophy className="mr-2 h-4 w-4" />
+                                <span>{t("menu.leaderboard")}</span>
+                            </DropdownMenuItem>
+                            <DropdownMenuItem
+                                className="cursor-pointer focus:bg-emerald-800 focus:text-white"
+                                onClick={() => router.push("/notes")}
+                            >
+                                <NotebookPen className="mr-2 h-4 w-4" />
+                                <span>{t("menu.notes")}</span>
+                            </DropdownMenuItem>
+                            <DropdownMenuItem
+                                className="cursor-pointer focus:bg-emerald-800 focus:text-white"
+                                onClick={() => router.push("/templates")}
+                            >
+                                <LibraryBig className="mr-2 h-4 w-4" />
+                                <span>{t("menu.templates")}</span>
+                            </DropdownMenuItem>
                             <DropdownMenuItem
                                 className="cursor-pointer focus:bg-emerald-800 focus:text-white"
                                 onClick={() => router.push("/settings")}
@@ -0,0 +1,177 @@
+"use client";
+
+import { useEffect, useState } from "react";
+import { Button } from "@/components/ui/button";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Textarea } from "@/components/ui/textarea";
+import { NotebookPen, Pencil, Trash2 } from "lucide-react";
+import { API_BASE_URL } from "@/config/api";
+
+interface Note {
+  id: number;
+  body: string;
+  created_at: string;
+  updated_at: string;
+}
+
+interface StepNotesProps {
+  projectId: number;
+  stepNumber: number;
+  token: string;
+  labels: {
+    title: string;
+    description: string;
+    placeholder: string;
+    add: string;
+    save: string;
+    cancel: string;
+    edit: string;
+    delete: string;
+    empty: string;
+    error: string;
+  };
+}
+
+// ステップごとの個人メモ（本文はMarkdown）
+export function StepNotes({ projectId, stepNumber, token, labels }: StepNotesProps) {
+  const [notes, setNotes] = useState<Note[]>([]);
+  const [draft, setDraft] = useState("");
+  const [editingId, setEditingId] = useState<number | null>(null);
+  const [editBody, setEditBody] = useState("");
+  const [saving, setSaving] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  const baseUrl = `${API_BASE_URL}/api/projects/${projectId}/steps/${stepNumber}/notes`;
+
+  useEffect(() => {
+    const load = async () => {
+      try {
+        const res = await fetch(baseUrl, {
+          headers: { Authorization: `Bearer ${token}` },
+        });
+        if (res.ok) setNotes(await res.json());
+      } catch (err) {
+        console.error("Failed to load notes:", err);
+      }
+    };
+    load();
+  }, [baseUrl, token]);
+
+  // 保存に成功したメモを返す（失敗時は null）
+  const request = async (url: string, method: string, body?: string): Promise<Note | null> => {
+    setError(null);
+    setSaving(true);
+    try {
+      const res = await fetch(url, {
+        method,
+        headers: { "Content-Type": "application/json", Authorization: `Bearer ${token}` },
+        body: body !== undefined ? JSON.stringify({ body }) : undefined,
+      });
+      const data = await res.json().catch(() => ({}));
+      if (!res.ok) {
+        setError(data.error || labels.error);
+        return null;
+      }
+      return data;
+    } catch (err) {
+      console.error("Failed to save note:", err);
+      setError(labels.error);
+      return null;
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  const handleAdd = async () => {
+    const note = await request(baseUrl, "POST", draft);
+    if (note) {
+      setNotes((prev) => [note, ...prev]);
+      setDraft("");
+    }
+  };
+
+  const handleSave = async (id: number) => {
+    const note = await request(`${baseUrl}/${id}`, "PUT", editBody);
+    if (note) {
+      setNotes((prev) => prev.map((n) => (n.id === id ? note : n)));
+      setEditingId(null);
+    }
+  };
+
+  const handleDelete = async (id: number) => {
+    if (await request(`${baseUrl}/${id}`, "DELETE")) {
+      setNotes((prev) => prev.filter((n) => n.id !== id));
+    }
+  };
+
+  return (
+    <Card>
+      <CardHeader>
+        <CardTitle className="text-lg flex items-center gap-2">
+          <NotebookPen className="h-5 w-5" />
+          {labels.title}
+        </CardTitle>
+        <CardDescription>{labels.description}</CardDescription>
+      </CardHeader>
+      <CardContent className="space-y-4">
+        <div className="space-y-2">
+          <Textarea value={draft} onChange={(e) => setDraft(e.target.value)} placeholder={labels.placeholder} rows={3} />
+          <div className="flex justify-end">
+            <Button size="sm" onClick={handleAdd} disabled={saving || !draft.trim()}>
+              {labels.add}
+            </Button>
+          </div>
+        </div>
+
+        {error && <p className="text-sm text-red-600">{error}</p>}
+
+        {notes.length === 0 ? (
+          <p className="text-sm text-slate-500">{labels.empty}</p>
+        ) : (
+          <ul className="space-y-3">
+            {notes.map((note) => (
+              <li key={note.id} className="rounded-lg border p-3 space-y-2">
+                {editingId === note.id ? (
+                  <>
+                    <Textarea value={editBody} onChange={(e) => setEditBody(e.target.value)} rows={4} />
+                    <div className="flex justify-end gap-2">
+                      <Button variant="ghost" size="sm" onClick={() => setEditingId(null)}>
+                        {labels.cancel}
+                      </Button>
+                      <Button size="sm" onClick={() => handleSave(note.id)} disabled={saving || !editBody.trim()}>
+                        {labels.save}
+                      </Button>
+                    </div>
+                  </>
+                ) : (
+                  <>
+                    <p className="text-sm text-slate-800 whitespace-pre-wrap">{note.body}</p>
+                    <div className="flex items-center justify-between text-xs text-slate-400">
+                      <span>{new Date(note.updated_at).toLocaleString()}</span>
+                      <div className="flex gap-1">
+                        <Button
+                          variant="ghost"
+                          size="sm"
+                          aria-label={labels.edit}
+                          onClick={() => {
+                            setEditingId(note.id);
+                            setEditBody(note.body);
+                          }}
+                        >
+                          <Pencil className="h-4 w-4" />
+                        </Button>
+                        <Button variant="ghost" size="sm" aria-label={labels.delete} onClick={() => handleDelete(note.id)}>
+                          <Trash2 className="h-4 w-4" />
+                        </Button>
+                      </div>
+                    </div>
+                  </>
+                )}
+              </li>
+            ))}
+          </ul>
+        )}
+      </CardContent>
+    </Card>
+  );
+}
@@ -0,0 +1,252 @@
+"use client";
+
+import { useEffect, useRef, useState } from "react";
+import { Button } from "@/components/ui/button";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Textarea } from "@/components/ui/textarea";
+import { cn } from "@/lib/utils";
+import { MessageCircle, Send, Plus } from "lucide-react";
+import { API_BASE_URL } from "@/config/api";
+
+interface ChatMessage {
+  id?: number;
+  role: "user" | "assistant";
+  content: string;
+}
+
+interface ChatQuota {
+  limit: number;
+  used: number;
+  resets_at: string;
+}
+
+interface StepTutorChatProps {
+  projectId: number;
+  stepNumber: number;
+  token: string;
+  labels: {
+    title: string;
+    description: string;
+    placeholder: string;
+    send: string;
+    newConversation: string;
+    quota: string; // {used} / {limit} を含む
+    quotaExceeded: string;
+    error: string;
+  };
+}
+
+// ステップについてAIチューターに質問するチャット（回答はSSEでストリーミング）
+export function StepTutorChat({ projectId, stepNumber, token, labels }: StepTutorChatProps) {
+  const [conversationId, setConversationId] = useState<number | null>(null);
+  const [messages, setMessages] = useState<ChatMessage[]>([]);
+  const [input, setInput] = useState("");
+  const [streaming, setStreaming] = useState(false);
+  const [quota, setQuota] = useState<ChatQuota | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const bottomRef = useRef<HTMLDivElement>(null);
+
+  const baseUrl = `${API_BASE_URL}/api/projects/${projectId}/steps/${stepNumber}`;
+
+  // 最新の会話を読み込む
+  useEffect(() => {
+    const load = async () => {
+      try {
+        const res = await fetch(`${baseUrl}/conversations`, {
+          headers: { Authorization: `Bearer ${token}` },
+        });
+        if (!res.ok) return;
+        const data = await res.json();
+        setQuota(data.quota);
+
+        const latest = data.conversations?.[0];
+        if (!latest) {
+          setConversationId(null);
+          setMessages([]);
+          return;
+        }
+        const convRes = await fetch(`${API_BASE_URL}/api/conversations/${latest.id}`, {
+          headers: { Authorization: `Bearer ${token}` },
+        });
+        if (!convRes.ok) return;
+        const conversation = await convRes.json();
+        setConversationId(conversation.id);
+        setMessages(conversation.messages ?? []);
+      } catch (err) {
+        console.error("Failed to load conversations:", err);
+      }
+    };
+    load();
+  }, [baseUrl, token]);
+
+  useEffect(() => {
+    bottomRef.current?.scrollIntoView({ behavior: "smooth" });
+  }, [messages]);
+
+  const quotaExceeded = quota !== null && quota.used >= quota.limit;
+
+  const handleSend = async () => {
+    const message = input.trim();
+    if (!message || streaming || quotaExceeded) return;
+
+    setError(null);
+    setStreaming(true);
+    setInput("");
+    setMessages((prev) => [...prev, { role: "user", content: message }, { role: "assistant", content: "" }]);
+
+    const appendToAnswer = (text: string) => {
+      setMessages((prev) => {
+        const next = [...prev];
+        const last = next[next.length - 1];
+        next[next.length - 1] = { ...last, content: last.content + text };
+        return next;
+      });
+    };
+    const discardTurn = () => setMessages((prev) => prev.slice(0, -2));
+
+    try {
+      const res = await fetch(`${baseUrl}/chat`, {
+        method: "POST",
+        headers: {
+          "Content-Type": "application/json",
+          Authorization: `Bearer ${token}`,
+        },
+        body: JSON.stringify({ conversation_id: conversationId ?? undefined, message }),
+      });
+
+      if (!res.ok || !res.body) {
+        const data = await res.json().catch(() => ({}));
+        if (res.status === 429 && data.quota) {
+          setQuota(data.quota);
+          setError(labels.quotaExceeded);
+        } else {
+          setError(labels.error);
+        }
+        discardTurn();
+        setInput(message);
+        return;
+      }
+
+      // SSE を読み取る（event: xxx / data: {...} を空行で区切る）
+      const reader = res.body.getReader();
+      const decoder = new TextDecoder();
+      let buffer = "";
+      let failed = false;
+
+      while (true) {
+        const { done, value } = await reader.read();
+        if (done) break;
+        buffer += decoder.decode(value, { stream: true });
+
+        let boundary;
+        while ((boundary = buffer.indexOf("\n\n")) !== -1) {
+          const raw = buffer.slice(0, boundary);
+          buffer = buffer.slice(boundary + 2);
+
+          let event = "message";
+          let data = "";
+          for (const line of raw.split("\n")) {
+            if (line.startsWith("event: ")) event = line.slice(7);
+            else if (line.startsWith("data: ")) data += line.slice(6);
+          }
+          const payload = data ? JSON.parse(data) : {};
+
+          if (event === "conversation") {
+            setConversationId(payload.conversation_id);
+          } else if (event === "delta") {
+            appendToAnswer(payload.text);
+          } else if (event === "done") {
+            setQuota(payload.quota);
+          } else if (event === "error") {
+            failed = true;
+          }
+        }
+      }
+
+      if (failed) {
+        setError(labels.error);
+        discardTurn();
+        setInput(message);
+      }
+    } catch (err) {
+      console.error("Failed to chat:", err);
+      setError(labels.error);
+      discardTurn();
+      setInput(message);
+    } finally {
+      setStreaming(false);
+    }
+  };
+
+  const handleNewConversation = () => {
+    setConversationId(null);
+    setMessages([]);
+    setError(null);
+  };
+
+  return (
+    <Card>
+      <CardHeader className="flex flex-row items-start justify-between space-y-0">
+        <div>
+          <CardTitle className="text-lg flex items-center gap-2">
+            <MessageCircle className="h-5 w-5" />
+            {labels.title}
+          </CardTitle>
+          <CardDescription className="mt-1">{labels.description}</CardDescription>
+        </div>
+        <Button variant="ghost" size="sm" onClick={handleNewConversation} disabled={streaming} className="gap-1">
+          <Plus className="h-4 w-4" />
+          {labels.newConversation}
+        </Button>
+      </CardHeader>
+      <CardContent className="space-y-4">
+        {messages.length > 0 && (
+          <div className="max-h-96 overflow-y-auto space-y-3 pr-1">
+            {messages.map((m, index) => (
+              <div key={m.id ?? `pending-${index}`} className={cn("flex", m.role === "user" ? "justify-end" : "justify-start")}>
+                <div
+                  className={cn(
+                    "max-w-[85%] rounded-lg px-4 py-2 text-sm whitespace-pre-wrap leading-relaxed",
+                    m.role === "user" ? "bg-slate-900 text-white" : "bg-slate-100 text-slate-900"
+                  )}
+                >
+                  {m.content || (streaming && index === messages.length - 1 ? "…" : "")}
+                </div>
+              </div>
+            ))}
+            <div ref={bottomRef} />
+          </div>
+        )}
+
+        {error && <p className="text-sm text-red-600">{error}</p>}
+
+        <div className="flex gap-2 items-end">
+          <Textarea
+            value={input}
+            onChange={(e) => setInput(e.target.value)}
+            onKeyDown={(e) => {
+              if (e.key === "Enter" && (e.metaKey || e.ctrlKey)) {
+                e.preventDefault();
+                handleSend();
+              }
+            }}
+            placeholder={labels.placeholder}
+            disabled={streaming || quotaExceeded}
+            rows={2}
+            maxLength={2000}
+          />
+          <Button onClick={handleSend} disabled={streaming || quotaExceeded || input.trim() === ""} className="bg-slate-900 hover:bg-slate-800 gap-1">
+            <Send className="h-4 w-4" />
+            {labels.send}
+          </Button>
+        </div>
+
+        {quota && (
+          <p className="text-xs text-slate-500">
+            {labels.quota.replace("{used}", String(quota.used)).replace("{limit}", String(quota.limit))}
+          </p>
+        )}
+      </CardContent>
+    </Card>
+  );
+}
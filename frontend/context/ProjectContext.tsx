@@ -11,10 +11,66 @@ interface ProjectSummary {
     goal: string;
     stack: string;
     created_at: string;
+    steps_total: number;
+    steps_completed: number;
+    completion_percentage: number;
+    average_percentage: number | null;
+    last_activity_at: string | null;
+    study_seconds: number;
+}
+
+export interface WeakTopic {
+    topic: string;
+    answered: number;
+    wrong: number;
+    correct_rate: number;
+}
+
+// 全プロジェクトを通した学習の進み具合（GET /api/me/progress）
+export interface Progress {
+    projects: ProjectSummary[];
+    steps_total: number;
+    steps_completed: number;
+    attempts_completed: number;
+    study_seconds: number;
+    average_percentage: number | null;
+    trend: {
+        direction: "up" | "down" | "flat";
+        change: number;
+        weeks: { week_start: string; attempts: number; average_percentage: number | null }[];
+    };
+    last_activity_at: string | null;
+    seconds_since_last_activity: number | null;
+    weak_topics: WeakTopic[];
+}
+
+// 経験値とレベル（GET /api/me/xp）
+export interface XP {
+    xp: number;
+    level: number;
+    level_xp: number;
+    next_level_xp: number;
+    today_xp: number;
+}
+
+// 学習者のタイムゾーンでの連続学習日数（GET /api/me/streak）
+export interface Streak {
+    current: number;
+    longest: number;
+    freezes: number;
+    freezes_used: number;
+    last_active_day?: string;
+    today: string;
+    timezone: string;
+    active_today: boolean;
+    at_risk: boolean;
 }
 
 interface ProjectContextType {
     projects: ProjectSummary[];
+    progress: Progress | null;
+    xp: XP | null;
+    streak: Streak | null;
     isLoading: boolean;
     fetchProjects: () => Promise<void>;
     deleteProject: (id: number) => Promise<void>;
@@ -24,6 +80,9 @@ const ProjectContext = createContext<ProjectContextType | undefined>(undefined);
 
 export function ProjectProvider({ children }: { children: React.ReactNode }) {
     const [projects, setProjects] = useState<ProjectSummary[]>([]);
+    const [progress, setProgress] = useState<Progress | null>(null);
+    const [xp, setXP] = useState<XP | null>(null);
+    const [streak, setStreak] = useState<Streak | null>(null);
     const [isLoading, setIsLoading] = useState(false);
     const { token, logout } = useAuth();
     const pathname = usePathname();
@@ -31,14 +90,21 @@ export function ProjectProvider({ children }: { children: React.ReactNode }) {
     const fetchProjects = useCallback(async () => {
         if (!token || ["/login", "/signup"].includes(pathname)) {
             setProjects([]);
+            setProgress(null);
+            setXP(null);
+            setStreak(null);
             return;
         }
 
         setIsLoading(true);
         try {
-            const response = await fetch(`${API_BASE_URL}/api/projects`, {
-                headers: { Authorization: `Bearer ${token}` },
-            });
+            // プロジェクト一覧と進捗を1回のリクエストで取得する（経験値と連続日数は並行して取得）
+            const headers = { Authorization: `Bearer ${token}` };
+            const [response, xpResponse, streakResponse] = await Promise.all([
+                fetch(`${API_BASE_URL}/api/me/progress`, { headers }),
+                fetch(`${API_BASE_URL}/api/me/xp`, { headers }),
+                fetch(`${API_BASE_URL}/api/me/streak`, { headers }),
+            ]);
 
             if (response.status === 401) {
                 logout();
@@ -46,8 +112,15 @@ export function ProjectProvider({ children }: { children: React.ReactNode }) {
             }
 
             if (response.ok) {
-                const data = await response.json();
-                setProjects(data || []);
+                const data: Progress = await response.json();
+                setProgress(data);
+                setProjects(data.projects || []);
+            }
+            if (xpResponse.ok) {
+                setXP(await xpResponse.json());
+            }
+            if (streakResponse.ok) {
+                setStreak(await streakResponse.json());
             }
         } catch (error) {
             console.error("Failed to fetch projects:", error);
@@ -72,6 +145,7 @@ export function ProjectProvider({ children }: { children: React.ReactNode }) {
 
             if (response.ok) {
                 setProjects((prev) => prev.filter((p) => p.id !== id));
+                fetchProjects();
             } else {
                 console.error("Failed to delete project");
             }
@@ -85,7 +159,7 @@ export function ProjectProvider({ children }: { children: React.ReactNode }) {
     }, [fetchProjects]);
 
     return (
-        <ProjectContext.Provider value={{ projects, isLoading, fetchProjects, deleteProject }}>
+        <ProjectContext.Provider value={{ projects, progress, xp, streak, isLoading, fetchProjects, deleteProject }}>
             {children}
         </ProjectContext.Provider>
     );
@@ -0,0 +1,68 @@
+"use client";
+
+import { useEffect } from "react";
+import { API_BASE_URL } from "@/config/api";
+
+// ステップの学習時間を計測する。表示中は定期的にハートビートを送り、離れると終了する
+export function useStudySession(projectId: number | null, stepNumber: number, token: string | null) {
+    useEffect(() => {
+        if (!projectId || !token || Number.isNaN(stepNumber)) return;
+
+        const headers = { Authorization: `Bearer ${token}` };
+        let sessionId: number | null = null;
+        let timer: ReturnType<typeof setInterval> | null = null;
+        let cancelled = false;
+
+        const stopSession = (id: number) => {
+            // ページを閉じる途中でも送信されるよう keepalive を使う
+            fetch(`${API_BASE_URL}/api/sessions/${id}/stop`, { method: "POST", headers, keepalive: true }).catch(() => {});
+        };
+
+        const heartbeat = async () => {
+            // タブが非表示の間は送らない（離席として計測されない）
+            if (!sessionId || document.visibilityState !== "visible") return;
+            const res = await fetch(`${API_BASE_URL}/api/sessions/${sessionId}/heartbeat`, { method: "POST", headers });
+            // 別のステップで学習を始めるとセッションは終了するので、始め直す
+            if (res.status === 409) {
+                if (timer) clearInterval(timer);
+                sessionId = null;
+                start();
+            }
+        };
+
+        const start = async () => {
+            try {
+                const res = await fetch(`${API_BASE_URL}/api/projects/${projectId}/steps/${stepNumber}/sessions`, {
+                    method: "POST",
+                    headers,
+                });
+                if (!res.ok) return;
+                const data = await res.json();
+                if (cancelled) {
+                    stopSession(data.session.id);
+                    return;
+                }
+                sessionId = data.session.id;
+                timer = setInterval(heartbeat, data.heartbeat_interval_seconds * 1000);
+            } catch (err) {
+                console.error("Failed to start study session:", err);
+            }
+        };
+
+        const stop = () => {
+            if (sessionId) {
+                stopSession(sessionId);
+                sessionId = null;
+            }
+        };
+
+        start();
+        window.addEventListener("pagehide", stop);
+        return () => {
+            cancelled = true;
+            if (timer) clearInterval(timer);
+            window.removeEventListener("pagehide", stop);
+            stop();
+        };
+    }, [projectId, stepNumber, token]);
+}
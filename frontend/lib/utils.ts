@@ -4,3 +4,12 @@ import { twMerge } from "tailwind-merge"
 export function cn(...inputs: ClassValue[]) {
   return twMerge(clsx(inputs))
 }
+
+// 秒数を「{h}時間{m}分」などの表記にする（labels の {h} と {m} を置き換える）
+export function formatDuration(seconds: number, labels: { hoursMinutes: string; minutes: string }) {
+  const minutes = Math.floor(seconds / 60)
+  if (minutes < 60) {
+    return labels.minutes.replace("{m}", String(minutes))
+  }
+  return labels.hoursMinutes.replace("{h}", String(Math.floor(minutes / 60))).replace("{m}", String(minutes % 60))
+}
@@ -1,8 +1,36 @@
+export type QuizType =
+  | "multiple_choice"
+  | "multiple_select"
+  | "true_false"
+  | "ordering"
+  | "fill_blank"
+  | "code_output"
+  | "free_response";
+
 export interface Quiz {
+  quiz_id?: number;
+  type?: QuizType; // 未指定の場合は multiple_choice
   question: string;
+  code?: string; // code_output の場合のコード
   options: string[];
-  answer_index: number;
-  explanation: string;
+  answer_index?: number; // 回答後にサーバーから返される
+  answer_indexes?: number[]; // multiple_select の正解 / ordering の正しい順序（回答後）
+  answer_text?: string; // fill_blank / code_output の正解、free_response の模範解答（回答後）
+  is_correct?: boolean; // 回答後にサーバーから返される（free_response は採点後）
+  explanation?: string; // 回答後にサーバーから返される
+  grading_status?: "pending" | "graded" | "failed" | "overridden"; // free_response の採点状況
+  points?: number; // free_response の得点（採点後）
+  max_points?: number;
+  feedback?: string; // free_response へのフィードバック（採点後）
+  mistake_explanation?: string; // 不正解の回答に合わせた解説（リクエスト時に取得）
+  mistake_concept?: string; // 復習すべき概念
+}
+
+// 回答内容（インデックスは表示順）
+export interface QuizResponse {
+  choice?: number;
+  choices?: number[];
+  text?: string;
 }
 
 export interface Step {
@@ -16,4 +44,4 @@ export interface RoadmapResponse {
   id: number;
   complexity: string;
   roadmap: Step[];
-}
\ No newline at end of file
+}